@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"fmt"
 	"github.com/broadinstitute/revere/internal/configuration"
 	"github.com/broadinstitute/revere/internal/shared"
 	"github.com/broadinstitute/revere/internal/statuspage"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/go-resty/resty/v2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"os"
 )
 
 var prepareCmd = &cobra.Command{
@@ -17,14 +20,34 @@ channels for Revere to subsequently run.
 
 Contents:
 	- Configure Statuspage.io to display Terra components as described in the
-configuration file`,
+configuration file
+
+Components and groups that aren't configured are only deleted as the
+configuration's Statuspage.DeletionPolicy allows; under the default "flag"
+policy, they are only deleted with --allow-delete.
+
+To see what would change on Statuspage.io without changing anything:
+	$ revere prepare --dry-run
+
+To fail CI when Statuspage.io has drifted from the configuration file:
+	$ revere prepare --dry-run --exit-code --output json`,
 	Run: Prepare,
 }
 
+var (
+	prepareDryRun   bool
+	prepareOutput   string
+	prepareExitCode bool
+)
+
 func Prepare(*cobra.Command, []string) {
 	config, err := configuration.AssembleConfig(viper.GetViper())
 	cobra.CheckErr(err)
 	client := statuspageapi.Client(config)
+	if prepareDryRun {
+		dryRun(config, client)
+		return
+	}
 	shared.LogLn(config, "reconciling components...")
 	err = statuspage.ReconcileComponents(config, client)
 	cobra.CheckErr(err)
@@ -33,6 +56,36 @@ func Prepare(*cobra.Command, []string) {
 	cobra.CheckErr(err)
 }
 
+// dryRun prints the changes Prepare would make, exiting with code 2 if there are any and --exit-code was given
+func dryRun(config *configuration.Config, client *resty.Client) {
+	shared.LogLn(config, "planning changes...")
+	plan, err := statuspage.DryRun(config, client)
+	cobra.CheckErr(err)
+	switch prepareOutput {
+	case "text":
+		fmt.Print(plan.ToText())
+	case "json":
+		rendered, err := plan.ToJSON()
+		cobra.CheckErr(err)
+		fmt.Print(rendered)
+	default:
+		cobra.CheckErr(fmt.Errorf("unknown output format %s, expected text or json", prepareOutput))
+	}
+	if prepareExitCode && plan.HasDrift() {
+		os.Exit(2)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(prepareCmd)
+	prepareCmd.Flags().BoolVar(&prepareDryRun, "dry-run", false,
+		"print what would change on Statuspage.io without changing anything")
+	prepareCmd.Flags().StringVar(&prepareOutput, "output", "text",
+		"format of --dry-run output, either text or json")
+	prepareCmd.Flags().BoolVar(&prepareExitCode, "exit-code", false,
+		"with --dry-run, exit with code 2 if anything would change")
+	prepareCmd.Flags().Bool("allow-delete", false,
+		"delete unconfigured components and groups from Statuspage.io under the \"flag\" deletion policy")
+	err := viper.BindPFlag("Statuspage.AllowDelete", prepareCmd.Flags().Lookup("allow-delete"))
+	cobra.CheckErr(err)
 }
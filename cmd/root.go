@@ -17,8 +17,14 @@ channels to notify the public about Terra's status and uptime.
 
 Current input event sources:
 	- Google Cloud Monitoring via Google Cloud Pub/Sub
-Current output communication channels:
-	- Atlassian Statuspage.io
+	- Google Cloud Monitoring via webhook
+	- Prometheus Alertmanager via webhook
+Current output communication channels, each told about every status
+change independently of the others:
+	- Atlassian Statuspage.io component statuses, incidents, and
+	  scheduled maintenances
+	- Slack via incoming webhooks, if enabled
+	- Email via SMTP, if enabled
 
 Requires a configuration file via --configuration, ./revere.yaml,
 or /etc/revere/revere.yaml.
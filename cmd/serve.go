@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"github.com/broadinstitute/revere/internal/api"
 	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/deadletter"
+	"github.com/broadinstitute/revere/internal/email"
+	"github.com/broadinstitute/revere/internal/outputs"
 	"github.com/broadinstitute/revere/internal/pubsub"
 	"github.com/broadinstitute/revere/internal/pubsub/pubsubapi"
 	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/slack"
 	"github.com/broadinstitute/revere/internal/state"
 	"github.com/broadinstitute/revere/internal/statuspage"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
@@ -29,10 +33,40 @@ var serveCmd = &cobra.Command{
 communication channels as described in the configuration file.
 
 Input event sources:
-	- Google Cloud Monitoring via Google Cloud Pub/Sub`,
+	- Google Cloud Monitoring via Google Cloud Pub/Sub
+	- Google Cloud Monitoring via webhook (POST /api/v1/webhooks/cloudmonitoring,
+	  if Api.CloudMonitoringWebhookToken is set)
+	- Prometheus Alertmanager via webhook (POST /api/v1/webhooks/alertmanager,
+	  if Api.AlertmanagerWebhookToken is set)
+
+Output communication channels, each notified of status changes in the
+background independently of the others:
+	- Atlassian Statuspage.io component statuses
+	- Atlassian Statuspage.io incidents (StatuspageIncidents.Enabled)
+	- Slack via incoming webhooks (Slack.Enabled)
+	- Email via SMTP (Email.Enabled)`,
 	Run: Serve,
 }
 
+// routine is something Serve runs in parallel until shutdown
+type routine struct {
+	runForever   func()
+	uponShutdown func() error
+}
+
+// outputShutdownTimeout is how long shutdown waits for outputs to finish sending what they have queued
+const outputShutdownTimeout = 10 * time.Second
+
+// awaitShutdown waits for a routine to close done, up to the timeout, so a stuck routine can't keep Serve from exiting
+func awaitShutdown(name string, done <-chan struct{}, timeout time.Duration) error {
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("%s didn't finish shutting down within %v", name, timeout)
+	}
+}
+
 func Serve(*cobra.Command, []string) {
 	config, err := configuration.AssembleConfig(viper.GetViper())
 	cobra.CheckErr(err)
@@ -41,39 +75,93 @@ func Serve(*cobra.Command, []string) {
 	statuspageClient := statuspageapi.Client(config)
 	statuspageComponents, err := statuspageapi.GetComponents(statuspageClient, config.Statuspage.PageID)
 	cobra.CheckErr(err)
-	componentNamesToIDs := make(map[string]string)
-	for _, component := range *statuspageComponents {
-		componentNamesToIDs[component.Name] = component.ID
-	}
 
 	shared.LogLn(config, "preparing pubsub...")
 	pubsubClient, err := pubsubapi.Client(config)
 	cobra.CheckErr(err)
 	pubsubCtx, cancelPubsub := context.WithCancel(context.Background())
 
+	shared.LogLn(config, "deriving state...")
+	stateStore, err := state.NewStore(config)
+	cobra.CheckErr(err)
+	appState := state.NewState(config, stateStore)
+	err = appState.Seed(*statuspageComponents)
+	cobra.CheckErr(err)
+
+	// StatusWriter patches statuses on Statuspage in the background, whenever one changes and periodically;
+	// changes made before it runs are written once it does
+	statusWriter := statuspage.NewStatusWriter(config, appState, statuspageClient)
+	statusWriterCtx, cancelStatusWriter := context.WithCancel(context.Background())
+	// IncidentWriter similarly opens, updates, and resolves Statuspage incidents in the background
+	incidentWriter, err := statuspage.NewIncidentWriter(config, appState, statuspageClient)
+	cobra.CheckErr(err)
+
+	// FanOut tells each output about status changes, each independently of the others; like the StatusWriter,
+	// it queues changes made before it runs
+	outputList := []outputs.Output{statusWriter}
+	if config.StatuspageIncidents.Enabled {
+		outputList = append(outputList, incidentWriter)
+	}
+	if config.Slack.Enabled {
+		outputList = append(outputList, slack.NewOutput(config))
+	}
+	// The email output batches changes, sending them from its own routine
+	var emailOutput *email.Output
+	if config.Email.Enabled {
+		emailOutput, err = email.NewOutput(config)
+		cobra.CheckErr(err)
+		outputList = append(outputList, emailOutput)
+	}
+	fanOut := outputs.NewFanOut(config, outputList...)
+	appState.OnStatusChange(fanOut.Handle)
+	fanOutCtx, cancelFanOut := context.WithCancel(context.Background())
+
+	err = statuspage.PatchDriftedStatuses(config, appState, statuspageClient, *statuspageComponents)
+	cobra.CheckErr(err)
+	if config.StatuspageIncidents.Enabled {
+		err = statuspage.AdoptUnresolvedIncidents(config, appState, statuspageClient)
+		cobra.CheckErr(err)
+	}
+	err = statuspage.AdoptMaintenanceWindows(config, appState, statuspageClient)
+	cobra.CheckErr(err)
+	err = statuspage.ApplyMaintenanceWindows(config, appState)
+	cobra.CheckErr(err)
+
+	// StatusUpdater returns a function to update the status for one component;
+	// each input source will call that function as messages are handled
+	statusUpdater := statuspage.StatusUpdater(config, appState)
+	// Retrying wraps that function so failures are retried and, if they persist, dead-lettered; webhooks instead
+	// dead-letter failures right away, since their senders are waiting on the response and retry on their own
+	deadLetterSink, err := deadletter.NewSink(config, pubsubClient)
+	cobra.CheckErr(err)
+	deadLetters := deadletter.NewQueue(deadLetterSink)
+	alertHandler := deadletter.Retrying(pubsubCtx, config, deadLetters, statusUpdater)
+	webhookHandler := deadletter.DeadLettering(config, deadLetters, statusUpdater)
+	// OverrideUpdater similarly returns a function for the admin API to set or clear one component's override
+	overrideUpdater := statuspage.OverrideUpdater(config, appState)
+	// MaintenanceUpdater likewise returns a function for the admin API to schedule or cancel maintenance
+	maintenanceUpdater := statuspage.MaintenanceUpdater(config, appState, statuspageClient)
+
 	shared.LogLn(config, "preparing api...")
 	apiServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", config.Api.Port),
-		Handler: api.NewRouter(config),
+		Handler: api.NewRouter(config, appState, webhookHandler, overrideUpdater, maintenanceUpdater, deadLetters),
 	}
 
-	shared.LogLn(config, "deriving state...")
-	appState := &state.State{}
-	appState.Seed(componentNamesToIDs)
+	// Routines may report unrecoverable errors here, so that Serve shuts down gracefully before exiting
+	fatalErrors := make(chan error, 1)
 
 	// Routines to run in parallel
-	routines := []struct {
-		runForever   func()
-		uponShutdown func() error
-	}{
+	routines := []routine{
 		{
 			runForever: func() {
 				shared.LogLn(config, "listening to pubsub...")
-				err := pubsub.ReceiveMessages(config, pubsubClient, pubsubCtx,
-					// StatusUpdater returns a function to update the status for one component;
-					// ReceiveMessages will call that function as messages are handled
-					statuspage.StatusUpdater(config, appState, statuspageClient))
-				cobra.CheckErr(err)
+				if err := pubsub.ReceiveMessages(config, pubsubClient, pubsubCtx, alertHandler); err != nil {
+					select {
+					case fatalErrors <- err:
+					default:
+					}
+				}
 			},
 			uponShutdown: func() error {
 				cancelPubsub()
@@ -84,7 +172,10 @@ func Serve(*cobra.Command, []string) {
 			runForever: func() {
 				shared.LogLn(config, fmt.Sprintf("serving api on port %d...", config.Api.Port))
 				if err := apiServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-					cobra.CheckErr(err)
+					select {
+					case fatalErrors <- err:
+					default:
+					}
 				}
 			},
 			uponShutdown: func() error {
@@ -96,16 +187,155 @@ func Serve(*cobra.Command, []string) {
 		},
 	}
 
+	routines = append(routines, routine{
+		runForever: func() {
+			statusWriter.Run(statusWriterCtx)
+		},
+		uponShutdown: func() error {
+			cancelStatusWriter()
+			return nil
+		},
+	})
+	if config.StatuspageIncidents.Enabled {
+		incidentWriterCtx, cancelIncidentWriter := context.WithCancel(context.Background())
+		routines = append(routines, routine{
+			runForever: func() {
+				incidentWriter.Run(incidentWriterCtx)
+			},
+			uponShutdown: func() error {
+				cancelIncidentWriter()
+				return nil
+			},
+		})
+	}
+	// Upon shutdown, outputs are still told about transitions already queued for them, and the email output then
+	// sends whatever it has batched; shutdown waits for both, in that order
+	fanOutDone := make(chan struct{})
+	routines = append(routines, routine{
+		runForever: func() {
+			fanOut.Run(fanOutCtx)
+			close(fanOutDone)
+		},
+		uponShutdown: func() error {
+			cancelFanOut()
+			return awaitShutdown("fan-out", fanOutDone, outputShutdownTimeout)
+		},
+	})
+
+	if emailOutput != nil {
+		emailCtx, cancelEmail := context.WithCancel(context.Background())
+		emailDone := make(chan struct{})
+		routines = append(routines, routine{
+			runForever: func() {
+				emailOutput.Run(emailCtx)
+				close(emailDone)
+			},
+			uponShutdown: func() error {
+				// The fan-out's own shutdown reports if it timed out, so send what's batched either way
+				_ = awaitShutdown("fan-out", fanOutDone, outputShutdownTimeout)
+				cancelEmail()
+				return awaitShutdown("email output", emailDone, outputShutdownTimeout)
+			},
+		})
+	}
+
+	// Background routines log failures and retry rather than exiting, so that one failure doesn't take down an
+	// otherwise healthy server; under the "wait" policy, inherited statuses are only held for a limited time
+	if config.InheritedStatus.Policy == "wait" {
+		inheritedStatusCtx, cancelInheritedStatus := context.WithCancel(context.Background())
+		routines = append(routines, routine{
+			runForever: func() {
+				select {
+				case <-time.After(time.Duration(config.InheritedStatus.WaitMinutes) * time.Minute):
+				case <-inheritedStatusCtx.Done():
+					return
+				}
+				ticker := time.NewTicker(time.Minute)
+				defer ticker.Stop()
+				for {
+					shared.LogLn(config, "clearing inherited statuses...")
+					err := statuspage.ClearInheritedIncidents(config, appState)
+					if err == nil {
+						return
+					}
+					shared.LogLn(config, fmt.Sprintf("failed to clear inherited statuses, will retry: %v", err))
+					select {
+					case <-ticker.C:
+					case <-inheritedStatusCtx.Done():
+						return
+					}
+				}
+			},
+			uponShutdown: func() error {
+				cancelInheritedStatus()
+				return nil
+			},
+		})
+	}
+
+	// Maintenance windows start and end on their own schedule
+	maintenanceCtx, cancelMaintenance := context.WithCancel(context.Background())
+	routines = append(routines, routine{
+		runForever: func() {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := statuspage.ApplyMaintenanceWindows(config, appState); err != nil {
+						shared.LogLn(config, fmt.Sprintf("failed to apply maintenance windows, will retry: %v", err))
+					}
+				case <-maintenanceCtx.Done():
+					return
+				}
+			}
+		},
+		uponShutdown: func() error {
+			cancelMaintenance()
+			return nil
+		},
+	})
+
+	// Overrides can only be set via the admin API, so only expire them if it's enabled
+	if config.Api.AdminToken != "" {
+		overrideExpiryCtx, cancelOverrideExpiry := context.WithCancel(context.Background())
+		routines = append(routines, routine{
+			runForever: func() {
+				ticker := time.NewTicker(time.Minute)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ticker.C:
+						if err := statuspage.ExpireOverrides(config, appState); err != nil {
+							shared.LogLn(config, fmt.Sprintf("failed to expire overrides, will retry: %v", err))
+						}
+					case <-overrideExpiryCtx.Done():
+						return
+					}
+				}
+			},
+			uponShutdown: func() error {
+				cancelOverrideExpiry()
+				return nil
+			},
+		})
+	}
+
 	// Run continuous routines forever
 	for _, routine := range routines {
 		go routine.runForever()
 	}
 
-	// Block waiting for SIGINT/SIGTERM
+	// Block waiting for SIGINT/SIGTERM or an unrecoverable error
 	// We can't capture SIGKILL so no need to include
 	shutdownChannel := make(chan os.Signal, 1)
 	signal.Notify(shutdownChannel, syscall.SIGINT, syscall.SIGTERM)
-	<-shutdownChannel
+	var fatalErr error
+	select {
+	case <-shutdownChannel:
+	case fatalErr = <-fatalErrors:
+		shared.LogLn(config, fmt.Sprintf("unrecoverable error: %v", fatalErr))
+	}
 
 	// Run shutdown routines "forever", use errgroup to synchronize
 	// Errgroup collects errors instead of exiting immediately
@@ -115,6 +345,7 @@ func Serve(*cobra.Command, []string) {
 		shutdownErrorGroup.Go(routine.uponShutdown)
 	}
 	cobra.CheckErr(shutdownErrorGroup.Wait())
+	cobra.CheckErr(fatalErr)
 }
 
 func init() {
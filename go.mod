@@ -9,6 +9,7 @@ go 1.16
 // Go-Cmp helps diff test results
 // Httpmock works with Resty to mock APIs
 // Mapstructure translates structures based on field names, helpful for API usage
+// Prometheus' client exposes metrics about Revere itself
 // Pubsub connects with Google Pub/Sub for input events
 // Resty simplifies REST API usage, remembering headers and unmarshalling to Go structs
 // Validator recursively checks structs based on field tags
@@ -24,6 +25,7 @@ require (
 	github.com/jarcoal/httpmock v1.0.8
 	github.com/leodido/go-urn v1.2.1 // indirect
 	github.com/mitchellh/mapstructure v1.4.1
+	github.com/prometheus/client_golang v1.11.0
 	github.com/spf13/cobra v1.1.3
 	github.com/spf13/viper v1.8.0
 	golang.org/x/net v0.0.0-20210813160813-60bc85c4be6d // indirect
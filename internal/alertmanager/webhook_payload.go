@@ -0,0 +1,100 @@
+package alertmanager
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"strings"
+	"time"
+)
+
+// WebhookPayload handles payloads from Prometheus Alertmanager's webhook receiver (version 4)
+// https://prometheus.io/docs/alerting/latest/configuration/#webhook_config
+type WebhookPayload struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+// Alert is a single alert within a WebhookPayload; Alertmanager groups many alerts into each payload
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// HasEnded is if Alertmanager says the alert has resolved
+func (a *Alert) HasEnded() bool {
+	return a.Status == "resolved"
+}
+
+// ToAlertEvent translates the alert to the form the rest of Revere handles, parsing Revere's labels.
+// The source is recorded in the event, so it's known how the alert arrived.
+//
+// Prometheus label names can't contain hyphens, so underscores in label names are read as hyphens
+// (`revere_service_name` is read as `revere-service-name`, and so on); that's also how labels are named as
+// attributes for service mappings to match on. The alert's fingerprint is used as the incident ID, since
+// Alertmanager keeps it stable between the firing and resolved notifications.
+func (a *Alert) ToAlertEvent(source string) (*events.AlertEvent, error) {
+	labels := make(map[string]string, len(a.Labels))
+	for key, value := range a.Labels {
+		labels[strings.ReplaceAll(key, "_", "-")] = value
+	}
+	serviceName, present := labels["revere-service-name"]
+	if !present {
+		return nil, fmt.Errorf("alert labels lacked the service name in %+v", a.Labels)
+	}
+	serviceEnvironment, present := labels["revere-service-environment"]
+	if !present {
+		return nil, fmt.Errorf("alert labels lacked the service environment in %+v", a.Labels)
+	}
+	alertTypeString, present := labels["revere-alert-type"]
+	if !present {
+		return nil, fmt.Errorf("alert labels lacked the alert type in %+v", a.Labels)
+	}
+	alertType, err := statuspagetypes.StatusFromKebabCase(alertTypeString)
+	if err != nil {
+		return nil, fmt.Errorf("alert label's alert type incorrect format: %w", err)
+	}
+
+	event := &events.AlertEvent{
+		Source:             source,
+		IncidentID:         a.Fingerprint,
+		Name:               a.Labels["alertname"],
+		ServiceName:        serviceName,
+		ServiceEnvironment: serviceEnvironment,
+		Status:             alertType,
+		Ended:              a.HasEnded(),
+		Summary:            a.Annotations["summary"],
+		Documentation:      a.Annotations["description"],
+		Attributes:         make(map[string]string, len(labels)+1),
+	}
+	if !a.StartsAt.IsZero() {
+		event.StartedAt = a.StartsAt.UTC()
+	}
+	// Alertmanager sets endsAt into the future for firing alerts, so it can only be trusted once resolved
+	if a.HasEnded() && !a.EndsAt.IsZero() {
+		event.EndedAt = a.EndsAt.UTC()
+	}
+	if a.GeneratorURL != "" {
+		event.Links = append(event.Links, events.Link{Name: "generator", URL: a.GeneratorURL})
+	}
+	if event.Name != "" {
+		event.Attributes["policy.name"] = event.Name
+	}
+	for key, value := range labels {
+		event.Attributes["policy.labels."+key] = value
+	}
+	return event, nil
+}
@@ -0,0 +1,120 @@
+package alertmanager
+
+import (
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestAlert_ToAlertEvent(t *testing.T) {
+	startsAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2021, 8, 1, 13, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		alert   Alert
+		want    *events.AlertEvent
+		wantErr bool
+	}{
+		{
+			name: "Translates firing alert",
+			alert: Alert{
+				Status: "firing",
+				Labels: map[string]string{
+					"alertname":                  "HighErrorRate",
+					"revere_service_name":        "rawls",
+					"revere_service_environment": "prod",
+					"revere_alert_type":          "partial-outage",
+				},
+				Annotations:  map[string]string{"summary": "Rawls is erroring", "description": "Check the *logs*"},
+				StartsAt:     startsAt,
+				EndsAt:       endsAt,
+				GeneratorURL: "https://prometheus/graph",
+				Fingerprint:  "abc123",
+			},
+			want: &events.AlertEvent{
+				Source:             "alertmanager",
+				IncidentID:         "abc123",
+				Name:               "HighErrorRate",
+				ServiceName:        "rawls",
+				ServiceEnvironment: "prod",
+				Status:             statuspagetypes.PartialOutage,
+				StartedAt:          startsAt,
+				Summary:            "Rawls is erroring",
+				Documentation:      "Check the *logs*",
+				Links:              []events.Link{{Name: "generator", URL: "https://prometheus/graph"}},
+				Attributes: map[string]string{
+					"policy.name":                              "HighErrorRate",
+					"policy.labels.alertname":                  "HighErrorRate",
+					"policy.labels.revere-service-name":        "rawls",
+					"policy.labels.revere-service-environment": "prod",
+					"policy.labels.revere-alert-type":          "partial-outage",
+				},
+			},
+		},
+		{
+			name: "Translates resolved alert",
+			alert: Alert{
+				Status: "resolved",
+				Labels: map[string]string{
+					"revere_service_name":        "rawls",
+					"revere_service_environment": "prod",
+					"revere_alert_type":          "major-outage",
+				},
+				StartsAt:    startsAt,
+				EndsAt:      endsAt,
+				Fingerprint: "abc123",
+			},
+			want: &events.AlertEvent{
+				Source:             "alertmanager",
+				IncidentID:         "abc123",
+				ServiceName:        "rawls",
+				ServiceEnvironment: "prod",
+				Status:             statuspagetypes.MajorOutage,
+				Ended:              true,
+				StartedAt:          startsAt,
+				EndedAt:            endsAt,
+				Attributes: map[string]string{
+					"policy.labels.revere-service-name":        "rawls",
+					"policy.labels.revere-service-environment": "prod",
+					"policy.labels.revere-alert-type":          "major-outage",
+				},
+			},
+		},
+		{
+			name: "Errors without labels",
+			alert: Alert{
+				Status:      "firing",
+				Labels:      map[string]string{"alertname": "Unlabeled"},
+				Fingerprint: "abc123",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Errors with unknown alert type",
+			alert: Alert{
+				Status: "firing",
+				Labels: map[string]string{
+					"revere_service_name":        "rawls",
+					"revere_service_environment": "prod",
+					"revere_alert_type":          "on-fire",
+				},
+				Fingerprint: "abc123",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.alert.ToAlertEvent("alertmanager")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToAlertEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ToAlertEvent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
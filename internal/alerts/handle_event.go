@@ -0,0 +1,47 @@
+package alerts
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/metrics"
+	"github.com/broadinstitute/revere/internal/pubsub/pubsubtypes"
+	"github.com/broadinstitute/revere/internal/shared"
+)
+
+// HandleAlertEvent executes the callback for each component affected by the event according to the config's
+// ServiceToComponentMapping, translating the event's status per component if the mapping says to. When several
+// mappings affect a component, the first one wins. Events affecting no components are logged and ignored; only
+// errors from the callback are returned.
+func HandleAlertEvent(config *configuration.Config, event *events.AlertEvent, callback pubsubtypes.PerComponentHandler) error {
+	shared.LogLn(config, fmt.Sprintf("%s alert %s (closed: %v) -- service %s in %s (%s)",
+		event.Source, event.Name, event.Ended, event.ServiceName, event.ServiceEnvironment, event.Status.ToString()))
+
+	// execute callback for each affected component, once even if multiple mappings match it
+	affectedComponents := make(map[string]struct{})
+	for _, serviceMapping := range config.ServiceToComponentMapping {
+		if matchesMapping(serviceMapping, event) {
+			for _, componentName := range serviceMapping.AffectsComponentsNamed {
+				if _, affected := affectedComponents[componentName]; affected {
+					continue
+				}
+				affectedComponents[componentName] = struct{}{}
+				componentEvent := *event
+				componentEvent.Status = translateStatus(serviceMapping, componentName, event.Status)
+				shared.LogLn(config,
+					fmt.Sprintf("%s alert %s affects %s (%s), executing callback...", event.Source, event.IncidentID,
+						componentName, componentEvent.Status.ToString()))
+				if err := callback(componentName, &componentEvent); err != nil {
+					shared.LogLn(config,
+						"failed to execute callback", fmt.Sprintf("%+v", err))
+					return err
+				}
+			}
+		}
+	}
+	if len(affectedComponents) == 0 {
+		shared.LogLn(config, fmt.Sprintf("%s alert %s affected no components, ignoring", event.Source, event.Name))
+		metrics.AlertsIgnored.WithLabelValues(event.Source, "no_mapping").Inc()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package alerts
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestHandleAlertEvent(t *testing.T) {
+	config := &configuration.Config{
+		ServiceToComponentMapping: []configuration.ServiceToComponentMapping{
+			{ServiceName: "leonardo", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"notebooks"}},
+			{ServiceName: "sam", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"notebooks", "ui"}},
+			{ServiceName: "*", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"notebooks"},
+				MatchIncident: []configuration.IncidentMatcher{{Attribute: "policy.labels.team", Pattern: "dsp-*"}}},
+		},
+	}
+	tests := []struct {
+		name           string
+		event          events.AlertEvent
+		wantComponents []string
+	}{
+		{
+			name:           "Calls back for each affected component",
+			event:          events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "prod"},
+			wantComponents: []string{"notebooks", "ui"},
+		},
+		{
+			name: "Matches attributes",
+			event: events.AlertEvent{ServiceName: "rawls", ServiceEnvironment: "prod",
+				Attributes: map[string]string{"policy.labels.team": "dsp-workspaces"}},
+			wantComponents: []string{"notebooks"},
+		},
+		{
+			name:  "Ignores unmapped services",
+			event: events.AlertEvent{ServiceName: "rawls", ServiceEnvironment: "prod"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotComponents []string
+			err := HandleAlertEvent(config, &tt.event, func(componentName string, event *events.AlertEvent) error {
+				gotComponents = append(gotComponents, componentName)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("HandleAlertEvent() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantComponents, gotComponents); diff != "" {
+				t.Errorf("HandleAlertEvent() components mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleAlertEvent_translatesStatuses(t *testing.T) {
+	config := &configuration.Config{
+		ServiceToComponentMapping: []configuration.ServiceToComponentMapping{
+			{ServiceName: "rawls", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"workspaces", "notebooks"},
+				StatusTranslations: []configuration.StatusTranslation{{ComponentName: "notebooks", AtMost: "degraded-performance"}}},
+		},
+	}
+	event := &events.AlertEvent{
+		IncidentID:         "abc",
+		ServiceName:        "rawls",
+		ServiceEnvironment: "prod",
+		Status:             statuspagetypes.MajorOutage,
+	}
+	gotStatuses := make(map[string]statuspagetypes.Status)
+	err := HandleAlertEvent(config, event, func(componentName string, event *events.AlertEvent) error {
+		gotStatuses[componentName] = event.Status
+		return nil
+	})
+	if err != nil {
+		t.Errorf("HandleAlertEvent() error = %v", err)
+	}
+	want := map[string]statuspagetypes.Status{
+		"workspaces": statuspagetypes.MajorOutage,
+		"notebooks":  statuspagetypes.DegradedPerformance,
+	}
+	if diff := cmp.Diff(want, gotStatuses); diff != "" {
+		t.Errorf("HandleAlertEvent() statuses mismatch (-want +got):\n%s", diff)
+	}
+	if event.Status != statuspagetypes.MajorOutage {
+		t.Errorf("HandleAlertEvent() changed the original event's status to %s", event.Status.ToString())
+	}
+}
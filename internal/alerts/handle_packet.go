@@ -0,0 +1,39 @@
+package alerts
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/cloudmonitoring"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/metrics"
+	"github.com/broadinstitute/revere/internal/pubsub/pubsubtypes"
+	"github.com/broadinstitute/revere/internal/shared"
+)
+
+// EventFromMonitoringPacket translates a Cloud Monitoring packet to an AlertEvent, parsing Revere's labels and
+// recording the source so callers can distinguish how the packet arrived. Packets that can't be understood are
+// logged and ignored, returning nil.
+func EventFromMonitoringPacket(config *configuration.Config, source string, packet *cloudmonitoring.MonitoringPacket) *events.AlertEvent {
+	if packet == nil || packet.Incident == nil {
+		shared.LogLn(config, fmt.Sprintf("%s packet lacked an incident, ignoring", source))
+		metrics.AlertsIgnored.WithLabelValues(source, "no_incident").Inc()
+		return nil
+	}
+	event, err := packet.ToAlertEvent(source)
+	if err != nil {
+		shared.LogLn(config, fmt.Sprintf("failed to parse labels from %s packet %s, ignoring: %v", source, packet.Incident.PolicyName, err))
+		metrics.AlertsIgnored.WithLabelValues(source, "label_failure").Inc()
+		return nil
+	}
+	return event
+}
+
+// HandleMonitoringPacket translates a Cloud Monitoring packet with EventFromMonitoringPacket and handles the
+// resulting event with HandleAlertEvent. Only errors from the callback are returned.
+func HandleMonitoringPacket(config *configuration.Config, source string, packet *cloudmonitoring.MonitoringPacket, callback pubsubtypes.PerComponentHandler) error {
+	event := EventFromMonitoringPacket(config, source, packet)
+	if event == nil {
+		return nil
+	}
+	return HandleAlertEvent(config, event, callback)
+}
@@ -0,0 +1,108 @@
+package alerts
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/cloudmonitoring"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestHandleMonitoringPacket(t *testing.T) {
+	config := &configuration.Config{
+		ServiceToComponentMapping: []configuration.ServiceToComponentMapping{
+			{ServiceName: "leonardo", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"notebooks"}},
+			{ServiceName: "sam", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"notebooks", "ui"}},
+			{ServiceName: "sam", ServiceEnvironment: "dev", AffectsComponentsNamed: []string{"preview"}},
+			{ServiceName: "*", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"notebooks"}},
+		},
+	}
+	labelsFor := func(serviceName, serviceEnvironment string) map[string]string {
+		return map[string]string{
+			"revere-service-name":        serviceName,
+			"revere-service-environment": serviceEnvironment,
+			"revere-alert-type":          "partial-outage",
+		}
+	}
+	tests := []struct {
+		name           string
+		packet         *cloudmonitoring.MonitoringPacket
+		callbackErr    error
+		wantComponents []string
+		wantErr        bool
+	}{
+		{
+			name: "Calls back for each affected component",
+			packet: &cloudmonitoring.MonitoringPacket{Incident: &cloudmonitoring.MonitoringIncident{
+				IncidentID: "abc", PolicyUserLabels: labelsFor("sam", "prod"),
+			}},
+			wantComponents: []string{"notebooks", "ui"},
+		},
+		{
+			name: "Matches patterns",
+			packet: &cloudmonitoring.MonitoringPacket{Incident: &cloudmonitoring.MonitoringIncident{
+				IncidentID: "abc", PolicyUserLabels: labelsFor("rawls", "prod"),
+			}},
+			wantComponents: []string{"notebooks"},
+		},
+		{
+			name: "Matches on environment",
+			packet: &cloudmonitoring.MonitoringPacket{Incident: &cloudmonitoring.MonitoringIncident{
+				IncidentID: "abc", PolicyUserLabels: labelsFor("sam", "dev"),
+			}},
+			wantComponents: []string{"preview"},
+		},
+		{
+			name: "Ignores unmapped services",
+			packet: &cloudmonitoring.MonitoringPacket{Incident: &cloudmonitoring.MonitoringIncident{
+				IncidentID: "abc", PolicyUserLabels: labelsFor("rawls", "dev"),
+			}},
+		},
+		{
+			name: "Ignores bad labels",
+			packet: &cloudmonitoring.MonitoringPacket{Incident: &cloudmonitoring.MonitoringIncident{
+				IncidentID: "abc",
+			}},
+		},
+		{
+			name:   "Ignores missing incident",
+			packet: &cloudmonitoring.MonitoringPacket{},
+		},
+		{
+			name: "Stops on callback error",
+			packet: &cloudmonitoring.MonitoringPacket{Incident: &cloudmonitoring.MonitoringIncident{
+				IncidentID: "abc", PolicyUserLabels: labelsFor("sam", "prod"),
+			}},
+			callbackErr:    fmt.Errorf("some error"),
+			wantComponents: []string{"notebooks"},
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotComponents []string
+			err := HandleMonitoringPacket(config, "test", tt.packet,
+				func(componentName string, event *events.AlertEvent) error {
+					if event.Status != statuspagetypes.PartialOutage {
+						t.Errorf("callback got status %s, want %s", event.Status.ToString(), statuspagetypes.PartialOutage.ToString())
+					}
+					if event.Source != "test" {
+						t.Errorf("callback got source %s, want test", event.Source)
+					}
+					if event.IncidentID != "abc" {
+						t.Errorf("callback got incident ID %s, want abc", event.IncidentID)
+					}
+					gotComponents = append(gotComponents, componentName)
+					return tt.callbackErr
+				})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HandleMonitoringPacket() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantComponents, gotComponents); diff != "" {
+				t.Errorf("HandleMonitoringPacket() components mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package alerts
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+)
+
+// matchesMapping returns if the alert is for the mapping's service and environment and its attributes meet the
+// mapping's other conditions. Patterns were already compiled when the configuration was validated.
+func matchesMapping(serviceMapping configuration.ServiceToComponentMapping, event *events.AlertEvent) bool {
+	if !serviceMapping.MatchesPattern(serviceMapping.ServiceName, event.ServiceName) ||
+		!serviceMapping.MatchesPattern(serviceMapping.ServiceEnvironment, event.ServiceEnvironment) {
+		return false
+	}
+	for _, matcher := range serviceMapping.MatchIncident {
+		value, present := event.Attribute(matcher.Attribute)
+		if !present || !serviceMapping.MatchesPattern(matcher.Pattern, value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package alerts
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"testing"
+)
+
+func Test_matchesMapping(t *testing.T) {
+	attributes := map[string]string{
+		"resource.type":              "k8s_container",
+		"resource.labels.project_id": "broad-dsde-dev",
+		"metric.type":                "custom.googleapis.com/sam/latency",
+	}
+	tests := []struct {
+		name           string
+		serviceMapping configuration.ServiceToComponentMapping
+		event          events.AlertEvent
+		want           bool
+	}{
+		{
+			name:           "exact names",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "dev"},
+			event:          events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "dev"},
+			want:           true,
+		},
+		{
+			name:           "exact names mismatch",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "dev"},
+			event:          events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "prod"},
+			want:           false,
+		},
+		{
+			name:           "globs",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "sa?", ServiceEnvironment: "*"},
+			event:          events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "staging"},
+			want:           true,
+		},
+		{
+			name:           "regular expressions",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "^sam$", ServiceEnvironment: "^(dev|staging)$", Regex: true},
+			event:          events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "staging"},
+			want:           true,
+		},
+		{
+			name:           "unanchored regular expressions",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "dev|staging", Regex: true},
+			event:          events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "staging"},
+			want:           true,
+		},
+		{
+			name:           "regular expressions match whole values",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "dev", Regex: true},
+			event:          events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "not-dev"},
+			want:           false,
+		},
+		{
+			name:           "regular expressions mismatch",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "^sam$", ServiceEnvironment: "^(dev|staging)$", Regex: true},
+			event:          events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "prod"},
+			want:           false,
+		},
+		{
+			name: "incident attributes",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "*",
+				MatchIncident: []configuration.IncidentMatcher{
+					{Attribute: "resource.labels.project_id", Pattern: "broad-dsde-*"},
+					{Attribute: "metric.type", Pattern: "custom.googleapis.com/sam/*"},
+				}},
+			event: events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "dev"},
+			want:  true,
+		},
+		{
+			name: "incident attributes mismatch",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "*",
+				MatchIncident: []configuration.IncidentMatcher{{Attribute: "resource.type", Pattern: "gce_instance"}}},
+			event: events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "dev"},
+			want:  false,
+		},
+		{
+			name: "missing incident attributes",
+			serviceMapping: configuration.ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "*",
+				MatchIncident: []configuration.IncidentMatcher{{Attribute: "condition.name", Pattern: "*"}}},
+			event: events.AlertEvent{ServiceName: "sam", ServiceEnvironment: "dev"},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := tt.event
+			event.Attributes = attributes
+			if err := tt.serviceMapping.CompilePatterns(); err != nil {
+				t.Errorf("CompilePatterns() error = %v", err)
+				return
+			}
+			if got := matchesMapping(tt.serviceMapping, &event); got != tt.want {
+				t.Errorf("matchesMapping() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package alerts
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+)
+
+// translateStatus applies the mapping's status translation for the component, if it has one, to the status
+// given by an alert. Translations were already validated with the rest of the configuration.
+func translateStatus(serviceMapping configuration.ServiceToComponentMapping, componentName string,
+	status statuspagetypes.Status) statuspagetypes.Status {
+	for _, translation := range serviceMapping.StatusTranslations {
+		if translation.ComponentName != componentName {
+			continue
+		}
+		if to, found := translation.Translate[status.ToKebabCase()]; found {
+			status, _ = statuspagetypes.StatusFromKebabCase(to)
+		}
+		if translation.AtMost != "" {
+			if atMost, _ := statuspagetypes.StatusFromKebabCase(translation.AtMost); status.WorstWith(atMost) == status {
+				status = atMost
+			}
+		}
+	}
+	return status
+}
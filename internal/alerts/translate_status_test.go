@@ -0,0 +1,63 @@
+package alerts
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"testing"
+)
+
+func Test_translateStatus(t *testing.T) {
+	serviceMapping := configuration.ServiceToComponentMapping{
+		ServiceName:            "rawls",
+		ServiceEnvironment:     "prod",
+		AffectsComponentsNamed: []string{"workspaces", "notebooks", "ui"},
+		StatusTranslations: []configuration.StatusTranslation{
+			{ComponentName: "notebooks", Translate: map[string]string{"major-outage": "partial-outage"}},
+			{ComponentName: "ui", Translate: map[string]string{"degraded-performance": "partial-outage"}, AtMost: "partial-outage"},
+		},
+	}
+	tests := []struct {
+		name          string
+		componentName string
+		status        statuspagetypes.Status
+		want          statuspagetypes.Status
+	}{
+		{
+			name:          "untranslated component",
+			componentName: "workspaces",
+			status:        statuspagetypes.MajorOutage,
+			want:          statuspagetypes.MajorOutage,
+		},
+		{
+			name:          "translated status",
+			componentName: "notebooks",
+			status:        statuspagetypes.MajorOutage,
+			want:          statuspagetypes.PartialOutage,
+		},
+		{
+			name:          "untranslated status",
+			componentName: "notebooks",
+			status:        statuspagetypes.DegradedPerformance,
+			want:          statuspagetypes.DegradedPerformance,
+		},
+		{
+			name:          "capped status",
+			componentName: "ui",
+			status:        statuspagetypes.MajorOutage,
+			want:          statuspagetypes.PartialOutage,
+		},
+		{
+			name:          "translated then capped status",
+			componentName: "ui",
+			status:        statuspagetypes.DegradedPerformance,
+			want:          statuspagetypes.PartialOutage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translateStatus(serviceMapping, tt.componentName, tt.status); got != tt.want {
+				t.Errorf("translateStatus() = %s, want %s", got.ToString(), tt.want.ToString())
+			}
+		})
+	}
+}
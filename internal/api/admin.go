@@ -0,0 +1,187 @@
+package api
+
+import (
+	"crypto/subtle"
+	"github.com/broadinstitute/revere/internal/deadletter"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// overrideRequest is the body accepted when setting an override
+type overrideRequest struct {
+	// Status in kebab-case, like "major-outage"
+	Status *statuspagetypes.Status `json:"status" binding:"required"`
+	Reason string                  `json:"reason"`
+	// How long the override should apply; if zero it applies until cleared
+	DurationMinutes int `json:"durationMinutes" binding:"min=0"`
+}
+
+// requireAdminToken rejects requests that don't bear the token in their Authorization header
+func requireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or incorrect admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// getOverrides lists every component that has an override
+func getOverrides(appState *state.State) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overrides := make(map[string]*state.Override)
+		for _, componentName := range appState.ComponentNames() {
+			err := appState.UseComponent(componentName, func(componentState *state.ComponentState) error {
+				if override := componentState.GetOverride(); override != nil {
+					overrides[componentName] = override
+				}
+				return nil
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"overrides": overrides})
+	}
+}
+
+// putOverride sets the override of the component named in the path
+func putOverride(appState *state.State, overrideHandler state.OverrideHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		componentName := c.Param("component")
+		if !appState.HasComponent(componentName) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no component named " + componentName})
+			return
+		}
+		var request overrideRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		override := state.Override{Status: *request.Status, Reason: request.Reason}
+		if request.DurationMinutes > 0 {
+			expiresAt := time.Now().Add(time.Duration(request.DurationMinutes) * time.Minute)
+			override.ExpiresAt = &expiresAt
+		}
+		if err := overrideHandler(componentName, &override); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"override": override})
+	}
+}
+
+// deleteOverride clears the override of the component named in the path
+func deleteOverride(appState *state.State, overrideHandler state.OverrideHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		componentName := c.Param("component")
+		if !appState.HasComponent(componentName) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no component named " + componentName})
+			return
+		}
+		if err := overrideHandler(componentName, nil); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// maintenanceRequest is the body accepted when scheduling a maintenance window
+type maintenanceRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Components  []string `json:"components" binding:"required,min=1"`
+	// When the window starts; if nil it starts immediately
+	StartsAt *time.Time `json:"startsAt"`
+	// When the window ends, or alternatively how long it lasts; one is required
+	EndsAt          *time.Time `json:"endsAt"`
+	DurationMinutes int        `json:"durationMinutes" binding:"min=0"`
+}
+
+// getMaintenance lists every scheduled maintenance window
+func getMaintenance(appState *state.State) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"maintenance": appState.GetMaintenanceWindows()})
+	}
+}
+
+// postMaintenance schedules a new maintenance window
+func postMaintenance(appState *state.State, maintenanceHandler state.MaintenanceHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request maintenanceRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, componentName := range request.Components {
+			if !appState.HasComponent(componentName) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "no component named " + componentName})
+				return
+			}
+		}
+		if _, scheduled := appState.GetMaintenanceWindow(request.Name); scheduled {
+			c.JSON(http.StatusConflict, gin.H{"error": "maintenance named " + request.Name + " is already scheduled"})
+			return
+		}
+		window := state.MaintenanceWindow{
+			Name:           request.Name,
+			Description:    request.Description,
+			ComponentNames: request.Components,
+			StartsAt:       time.Now().UTC(),
+		}
+		if request.StartsAt != nil {
+			window.StartsAt = *request.StartsAt
+		}
+		if request.EndsAt != nil {
+			window.EndsAt = *request.EndsAt
+		} else if request.DurationMinutes > 0 {
+			window.EndsAt = window.StartsAt.Add(time.Duration(request.DurationMinutes) * time.Minute)
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "one of endsAt or durationMinutes is required"})
+			return
+		}
+		if !window.EndsAt.After(window.StartsAt) || !window.EndsAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "maintenance must end after it starts and in the future"})
+			return
+		}
+		if err := maintenanceHandler(window.Name, &window); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if scheduled, found := appState.GetMaintenanceWindow(window.Name); found {
+			window = scheduled
+		}
+		c.JSON(http.StatusCreated, gin.H{"maintenance": window})
+	}
+}
+
+// deleteMaintenance cancels the maintenance window named in the path
+func deleteMaintenance(appState *state.State, maintenanceHandler state.MaintenanceHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if _, scheduled := appState.GetMaintenanceWindow(name); !scheduled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no maintenance named " + name})
+			return
+		}
+		if err := maintenanceHandler(name, nil); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// getDeadLetters lists the most recent alerts that couldn't be handled even after retrying
+func getDeadLetters(deadLetters *deadletter.Queue) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"deadLetters": deadLetters.List()})
+	}
+}
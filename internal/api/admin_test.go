@@ -0,0 +1,289 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/deadletter"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_adminOverrides(t *testing.T) {
+	config := testConfig
+	config.Api.AdminToken = "secret"
+	type call struct {
+		ComponentName string
+		Override      *state.Override
+	}
+	tests := []struct {
+		name       string
+		reqMethod  string
+		reqUrl     string
+		reqBody    string
+		token      string
+		handlerErr error
+		wantCode   int
+		wantCalls  []call
+	}{
+		{
+			name:      "Rejects requests without the token",
+			reqMethod: "PUT",
+			reqUrl:    "/api/v1/admin/overrides/notebooks",
+			reqBody:   `{"status": "major-outage"}`,
+			wantCode:  401,
+		},
+		{
+			name:      "Rejects requests with the wrong token",
+			reqMethod: "PUT",
+			reqUrl:    "/api/v1/admin/overrides/notebooks",
+			reqBody:   `{"status": "major-outage"}`,
+			token:     "not secret",
+			wantCode:  401,
+		},
+		{
+			name:      "Sets an override",
+			reqMethod: "PUT",
+			reqUrl:    "/api/v1/admin/overrides/notebooks",
+			reqBody:   `{"status": "major-outage", "reason": "on fire"}`,
+			token:     "secret",
+			wantCode:  200,
+			wantCalls: []call{{ComponentName: "notebooks", Override: &state.Override{Status: statuspagetypes.MajorOutage, Reason: "on fire"}}},
+		},
+		{
+			name:      "Requires a status",
+			reqMethod: "PUT",
+			reqUrl:    "/api/v1/admin/overrides/notebooks",
+			reqBody:   `{"reason": "on fire"}`,
+			token:     "secret",
+			wantCode:  400,
+		},
+		{
+			name:      "Rejects unknown statuses",
+			reqMethod: "PUT",
+			reqUrl:    "/api/v1/admin/overrides/notebooks",
+			reqBody:   `{"status": "on-fire"}`,
+			token:     "secret",
+			wantCode:  400,
+		},
+		{
+			name:      "Rejects unknown components",
+			reqMethod: "PUT",
+			reqUrl:    "/api/v1/admin/overrides/foobar",
+			reqBody:   `{"status": "major-outage"}`,
+			token:     "secret",
+			wantCode:  404,
+		},
+		{
+			name:      "Clears an override",
+			reqMethod: "DELETE",
+			reqUrl:    "/api/v1/admin/overrides/notebooks",
+			token:     "secret",
+			wantCode:  200,
+			wantCalls: []call{{ComponentName: "notebooks"}},
+		},
+		{
+			name:       "Errors if handler fails",
+			reqMethod:  "DELETE",
+			reqUrl:     "/api/v1/admin/overrides/notebooks",
+			token:      "secret",
+			handlerErr: fmt.Errorf("some error"),
+			wantCode:   500,
+			wantCalls:  []call{{ComponentName: "notebooks"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appState := &state.State{}
+			if err := appState.Seed([]statuspagetypes.Component{{Name: "notebooks", Status: "operational"}}); err != nil {
+				t.Errorf("unexpected Seed error %v", err)
+				return
+			}
+			var gotCalls []call
+			router := NewRouter(&config, appState, noopCallback, func(componentName string, override *state.Override) error {
+				gotCalls = append(gotCalls, call{ComponentName: componentName, Override: override})
+				return tt.handlerErr
+			}, nil, nil)
+			got := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.reqMethod, tt.reqUrl, strings.NewReader(tt.reqBody))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			router.ServeHTTP(got, req)
+			if got.Code != tt.wantCode {
+				t.Errorf("code %d, want %d: %s", got.Code, tt.wantCode, got.Body.String())
+			}
+			if diff := cmp.Diff(tt.wantCalls, gotCalls); diff != "" {
+				t.Errorf("override handler calls mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_getOverrides(t *testing.T) {
+	config := testConfig
+	config.Api.AdminToken = "secret"
+	appState := &state.State{}
+	if err := appState.Seed([]statuspagetypes.Component{
+		{Name: "notebooks", Status: "operational"},
+		{Name: "ui", Status: "operational"},
+	}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	_ = appState.UseComponent("ui", func(c *state.ComponentState) error {
+		c.SetOverride(state.Override{Status: statuspagetypes.Operational, Reason: "false alarm"})
+		return nil
+	})
+	router := NewRouter(&config, appState, noopCallback, nil, nil, nil)
+	got := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/overrides", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	router.ServeHTTP(got, req)
+	if got.Code != 200 {
+		t.Errorf("code %d, want 200", got.Code)
+	}
+	var body map[string]map[string]map[string]interface{}
+	if err := json.Unmarshal(got.Body.Bytes(), &body); err != nil {
+		t.Errorf("failed to parse response: %v", err)
+	}
+	want := map[string]map[string]map[string]interface{}{
+		"overrides": {"ui": {"status": "operational", "reason": "false alarm"}},
+	}
+	if diff := cmp.Diff(want, body); diff != "" {
+		t.Errorf("getOverrides() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_adminDisabledWithoutToken(t *testing.T) {
+	router := NewRouter(&testConfig, &state.State{}, noopCallback, nil, nil, nil)
+	got := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/overrides", nil)
+	router.ServeHTTP(got, req)
+	if got.Code != 404 {
+		t.Errorf("code %d, want 404", got.Code)
+	}
+}
+
+func Test_adminMaintenance(t *testing.T) {
+	config := testConfig
+	config.Api.AdminToken = "secret"
+	type call struct {
+		Name       string
+		Components []string
+		Cancelled  bool
+	}
+	tests := []struct {
+		name      string
+		reqMethod string
+		reqUrl    string
+		reqBody   string
+		wantCode  int
+		wantCalls []call
+	}{
+		{
+			name:      "Schedules maintenance",
+			reqMethod: "POST",
+			reqUrl:    "/api/v1/admin/maintenance",
+			reqBody:   `{"name": "upgrade", "components": ["notebooks"], "durationMinutes": 30}`,
+			wantCode:  201,
+			wantCalls: []call{{Name: "upgrade", Components: []string{"notebooks"}}},
+		},
+		{
+			name:      "Requires an end",
+			reqMethod: "POST",
+			reqUrl:    "/api/v1/admin/maintenance",
+			reqBody:   `{"name": "upgrade", "components": ["notebooks"]}`,
+			wantCode:  400,
+		},
+		{
+			name:      "Rejects ends in the past",
+			reqMethod: "POST",
+			reqUrl:    "/api/v1/admin/maintenance",
+			reqBody:   `{"name": "upgrade", "components": ["notebooks"], "startsAt": "2020-01-01T00:00:00Z", "endsAt": "2020-01-01T01:00:00Z"}`,
+			wantCode:  400,
+		},
+		{
+			name:      "Rejects unknown components",
+			reqMethod: "POST",
+			reqUrl:    "/api/v1/admin/maintenance",
+			reqBody:   `{"name": "upgrade", "components": ["foobar"], "durationMinutes": 30}`,
+			wantCode:  400,
+		},
+		{
+			name:      "Rejects names already scheduled",
+			reqMethod: "POST",
+			reqUrl:    "/api/v1/admin/maintenance",
+			reqBody:   `{"name": "existing", "components": ["notebooks"], "durationMinutes": 30}`,
+			wantCode:  409,
+		},
+		{
+			name:      "Cancels maintenance",
+			reqMethod: "DELETE",
+			reqUrl:    "/api/v1/admin/maintenance/existing",
+			wantCode:  200,
+			wantCalls: []call{{Name: "existing", Cancelled: true}},
+		},
+		{
+			name:      "Rejects cancelling unknown maintenance",
+			reqMethod: "DELETE",
+			reqUrl:    "/api/v1/admin/maintenance/foobar",
+			wantCode:  404,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appState := &state.State{}
+			if err := appState.Seed([]statuspagetypes.Component{{Name: "notebooks", Status: "operational"}}); err != nil {
+				t.Errorf("unexpected Seed error %v", err)
+				return
+			}
+			appState.PutMaintenanceWindow(state.MaintenanceWindow{Name: "existing", ComponentNames: []string{"notebooks"}})
+			var gotCalls []call
+			router := NewRouter(&config, appState, noopCallback, nil, func(name string, window *state.MaintenanceWindow) error {
+				if window == nil {
+					gotCalls = append(gotCalls, call{Name: name, Cancelled: true})
+				} else {
+					gotCalls = append(gotCalls, call{Name: name, Components: window.ComponentNames})
+				}
+				return nil
+			}, nil)
+			got := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.reqMethod, tt.reqUrl, strings.NewReader(tt.reqBody))
+			req.Header.Set("Authorization", "Bearer secret")
+			router.ServeHTTP(got, req)
+			if got.Code != tt.wantCode {
+				t.Errorf("code %d, want %d: %s", got.Code, tt.wantCode, got.Body.String())
+			}
+			if diff := cmp.Diff(tt.wantCalls, gotCalls); diff != "" {
+				t.Errorf("maintenance handler calls mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_getDeadLetters(t *testing.T) {
+	config := testConfig
+	config.Api.AdminToken = "secret"
+	deadLetters := deadletter.NewQueue(deadletter.NoneSink{})
+	_ = deadLetters.Add(deadletter.Letter{ComponentName: "notebooks", Attempts: 5, Error: "some error"})
+	router := NewRouter(&config, &state.State{}, noopCallback, nil, nil, deadLetters)
+	got := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/dead-letters", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	router.ServeHTTP(got, req)
+	if got.Code != 200 {
+		t.Errorf("code %d, want 200", got.Code)
+	}
+	var body map[string][]deadletter.Letter
+	if err := json.Unmarshal(got.Body.Bytes(), &body); err != nil {
+		t.Errorf("failed to parse response: %v", err)
+	}
+	if len(body["deadLetters"]) != 1 || body["deadLetters"][0].ComponentName != "notebooks" {
+		t.Errorf("getDeadLetters() = %+v", body)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// componentResponse is what Revere currently believes about a single component
+type componentResponse struct {
+	Name string `json:"name"`
+	// Statuspage ID of the component
+	ID            string                 `json:"id"`
+	DesiredStatus statuspagetypes.Status `json:"desiredStatus"`
+	OpenIncidents []state.OpenIncident   `json:"openIncidents"`
+	Override      *state.Override        `json:"override,omitempty"`
+}
+
+// describeComponent reads the state of the named component
+func describeComponent(appState *state.State, componentName string) (*componentResponse, error) {
+	var response *componentResponse
+	err := appState.UseComponent(componentName, func(c *state.ComponentState) error {
+		response = &componentResponse{
+			Name:          componentName,
+			ID:            c.GetID(),
+			DesiredStatus: c.GetDesiredStatus(),
+			OpenIncidents: c.GetOpenIncidents(),
+			Override:      c.GetOverride(),
+		}
+		return nil
+	})
+	return response, err
+}
+
+// getComponents lists the state of every component, sorted by name
+func getComponents(appState *state.State) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		components := make([]*componentResponse, 0)
+		for _, componentName := range appState.ComponentNames() {
+			component, err := describeComponent(appState, componentName)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			components = append(components, component)
+		}
+		c.JSON(http.StatusOK, gin.H{"components": components})
+	}
+}
+
+// getComponent returns the state of the component named in the path
+func getComponent(appState *state.State) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		componentName := c.Param("component")
+		if !appState.HasComponent(componentName) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no component named " + componentName})
+			return
+		}
+		component, err := describeComponent(appState, componentName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, component)
+	}
+}
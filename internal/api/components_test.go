@@ -0,0 +1,70 @@
+package api
+
+import (
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_getComponents(t *testing.T) {
+	openedAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	appState := &state.State{}
+	if err := appState.Seed([]statuspagetypes.Component{
+		{Name: "ui", ID: "ui-id", Status: "operational"},
+		{Name: "notebooks", ID: "notebooks-id", Status: "operational"},
+	}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	_ = appState.UseComponent("notebooks", func(c *state.ComponentState) error {
+		c.LogIncident("abc", statuspagetypes.PartialOutage)
+		c.DescribeIncident("abc", "pubsub", openedAt)
+		return nil
+	})
+	tests := []struct {
+		name     string
+		reqUrl   string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Lists every component",
+			reqUrl:   "/api/v1/components",
+			wantCode: 200,
+			wantBody: `{"components":[` +
+				`{"name":"notebooks","id":"notebooks-id","desiredStatus":"partial-outage","openIncidents":[` +
+				`{"id":"abc","status":"partial-outage","source":"pubsub","openedAt":"2021-06-01T12:00:00Z"}]},` +
+				`{"name":"ui","id":"ui-id","desiredStatus":"operational","openIncidents":[]}]}`,
+		},
+		{
+			name:     "Describes one component",
+			reqUrl:   "/api/v1/components/ui",
+			wantCode: 200,
+			wantBody: `{"name":"ui","id":"ui-id","desiredStatus":"operational","openIncidents":[]}`,
+		},
+		{
+			name:     "Errors on unknown component",
+			reqUrl:   "/api/v1/components/foobar",
+			wantCode: 404,
+			wantBody: `{"error":"no component named foobar"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter(&testConfig, appState, noopCallback, nil, nil, nil)
+			got := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.reqUrl, nil)
+			router.ServeHTTP(got, req)
+			if got.Code != tt.wantCode {
+				t.Errorf("code %d, want %d", got.Code, tt.wantCode)
+			}
+			if diff := cmp.Diff(tt.wantBody, got.Body.String()); diff != "" {
+				t.Errorf("GET %s mismatch (-want +got):\n%s", tt.reqUrl, diff)
+			}
+		})
+	}
+}
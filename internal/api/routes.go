@@ -2,8 +2,12 @@ package api
 
 import (
 	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/deadletter"
+	"github.com/broadinstitute/revere/internal/pubsub/pubsubtypes"
+	"github.com/broadinstitute/revere/internal/state"
 	"github.com/broadinstitute/revere/internal/version"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
 )
 
@@ -15,7 +19,15 @@ func getStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
-func NewRouter(config *configuration.Config) *gin.Engine {
+// NewRouter builds Revere's API, exposing the appState read-only. The callback is invoked for each component
+// affected by incoming webhooks; it shouldn't retry failures itself, since the webhook's sender is waiting on
+// the response (see deadletter.DeadLettering). Each webhook is only served if a token is configured to
+// authenticate it, since it can change public statuses. The overrideHandler and maintenanceHandler are invoked
+// when overrides are set or cleared and maintenance is scheduled or cancelled through the admin endpoints, which
+// are only served if an admin token is configured.
+// The admin endpoints also list the alerts in deadLetters, which may be nil.
+func NewRouter(config *configuration.Config, appState *state.State, callback pubsubtypes.PerComponentHandler,
+	overrideHandler state.OverrideHandler, maintenanceHandler state.MaintenanceHandler, deadLetters *deadletter.Queue) *gin.Engine {
 	if config.Api.Debug {
 		gin.SetMode(gin.DebugMode)
 	} else {
@@ -36,5 +48,33 @@ func NewRouter(config *configuration.Config) *gin.Engine {
 		g.GET("/status", getStatus)
 	}
 
+	// Routes available only on /, following Prometheus convention
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Routes available only on /api/v1/
+	api.GET("/components", getComponents(appState))
+	api.GET("/components/:component", getComponent(appState))
+
+	webhooks := api.Group("/webhooks")
+	if config.Api.CloudMonitoringWebhookToken != "" {
+		webhooks.POST("/cloudmonitoring", requireWebhookToken(config.Api.CloudMonitoringWebhookToken),
+			postCloudMonitoringWebhook(config, callback))
+	}
+	if config.Api.AlertmanagerWebhookToken != "" {
+		webhooks.POST("/alertmanager", requireWebhookToken(config.Api.AlertmanagerWebhookToken),
+			postAlertmanagerWebhook(config, callback))
+	}
+
+	if config.Api.AdminToken != "" {
+		admin := api.Group("/admin", requireAdminToken(config.Api.AdminToken))
+		admin.GET("/overrides", getOverrides(appState))
+		admin.PUT("/overrides/:component", putOverride(appState, overrideHandler))
+		admin.DELETE("/overrides/:component", deleteOverride(appState, overrideHandler))
+		admin.GET("/maintenance", getMaintenance(appState))
+		admin.POST("/maintenance", postMaintenance(appState, maintenanceHandler))
+		admin.DELETE("/maintenance/:name", deleteMaintenance(appState, maintenanceHandler))
+		admin.GET("/dead-letters", getDeadLetters(deadLetters))
+	}
+
 	return router
 }
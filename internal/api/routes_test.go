@@ -3,24 +3,35 @@ package api
 import (
 	"encoding/json"
 	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/state"
 	"github.com/broadinstitute/revere/internal/version"
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-cmp/cmp"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
 // Squelch Gin's normal logging output in favor of test logs
 var testConfig = configuration.Config{
 	Api: struct {
-		Port   int
-		Debug  bool
-		Silent bool
+		Port                        int
+		Debug                       bool
+		Silent                      bool
+		AdminToken                  string
+		CloudMonitoringWebhookToken string
+		AlertmanagerWebhookToken    string
 	}{Debug: false, Silent: true},
 }
 
+// Callback for routes that don't need to record what they're given
+func noopCallback(string, *events.AlertEvent) error {
+	return nil
+}
+
 // Alias the abstract fields needed to test a route
 type routeTest = struct {
 	name      string
@@ -38,7 +49,7 @@ func runRouteTest(t *testing.T, rt routeTest) {
 		t.Errorf("wantJson %v could not be rendered: %v", rt.wantJson, err)
 		return
 	}
-	router := NewRouter(&testConfig)
+	router := NewRouter(&testConfig, &state.State{}, noopCallback, nil, nil, nil)
 	got := httptest.NewRecorder()
 	req, _ := http.NewRequest(rt.reqMethod, rt.reqUrl, rt.reqBody)
 	router.ServeHTTP(got, req)
@@ -101,3 +112,16 @@ func Test_getVersion(t *testing.T) {
 		})
 	}
 }
+
+func Test_getMetrics(t *testing.T) {
+	router := NewRouter(&testConfig, &state.State{}, noopCallback, nil, nil, nil)
+	got := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(got, req)
+	if got.Code != 200 {
+		t.Errorf("GET /metrics -> code %d, want 200", got.Code)
+	}
+	if !strings.Contains(got.Body.String(), "revere_pubsub_messages_acked_total") {
+		t.Errorf("GET /metrics lacked Revere's metrics:\n%s", got.Body.String())
+	}
+}
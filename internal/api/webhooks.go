@@ -0,0 +1,78 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/alertmanager"
+	"github.com/broadinstitute/revere/internal/alerts"
+	"github.com/broadinstitute/revere/internal/cloudmonitoring"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/metrics"
+	"github.com/broadinstitute/revere/internal/pubsub/pubsubtypes"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// requireWebhookToken rejects requests that don't bear the token, either in their Authorization header like
+// requireAdminToken or as the password of their basic auth, since not every sender supports bearer tokens
+func requireWebhookToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if _, password, ok := c.Request.BasicAuth(); ok {
+			given = password
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or incorrect webhook token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// postCloudMonitoringWebhook accepts the same packets that Cloud Monitoring would send via Pub/Sub,
+// handling them identically.
+func postCloudMonitoringWebhook(config *configuration.Config, callback pubsubtypes.PerComponentHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metrics.AlertsReceived.WithLabelValues("webhook").Inc()
+		var packet cloudmonitoring.MonitoringPacket
+		if err := c.ShouldBindJSON(&packet); err != nil {
+			metrics.AlertsIgnored.WithLabelValues("webhook", "parse_failure").Inc()
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := alerts.HandleMonitoringPacket(config, "webhook", &packet, callback); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// postAlertmanagerWebhook accepts payloads from Prometheus Alertmanager, handling each alert as its own event.
+// Alerts that can't be understood are logged and ignored, like Cloud Monitoring packets.
+func postAlertmanagerWebhook(config *configuration.Config, callback pubsubtypes.PerComponentHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload alertmanager.WebhookPayload
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			metrics.AlertsIgnored.WithLabelValues("alertmanager", "parse_failure").Inc()
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, alert := range payload.Alerts {
+			metrics.AlertsReceived.WithLabelValues("alertmanager").Inc()
+			event, err := alert.ToAlertEvent("alertmanager")
+			if err != nil {
+				shared.LogLn(config, fmt.Sprintf("failed to parse labels from alertmanager alert %s, ignoring: %v", alert.Labels["alertname"], err))
+				metrics.AlertsIgnored.WithLabelValues("alertmanager", "label_failure").Inc()
+				continue
+			}
+			if err := alerts.HandleAlertEvent(config, event, callback); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
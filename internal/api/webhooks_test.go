@@ -0,0 +1,225 @@
+package api
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_requireWebhookToken(t *testing.T) {
+	// Each webhook is given a body it ignores, so the only failures are from authentication
+	webhooks := []struct {
+		name     string
+		reqUrl   string
+		reqBody  string
+		setToken func(config *configuration.Config, token string)
+	}{
+		{
+			name:    "cloudmonitoring",
+			reqUrl:  "/api/v1/webhooks/cloudmonitoring",
+			reqBody: `{"version": "1.2", "incident": {"incident_id": "abc", "state": "open"}}`,
+			setToken: func(config *configuration.Config, token string) {
+				config.Api.CloudMonitoringWebhookToken = token
+			},
+		},
+		{
+			name:    "alertmanager",
+			reqUrl:  "/api/v1/webhooks/alertmanager",
+			reqBody: `{"version": "4", "status": "firing", "alerts": []}`,
+			setToken: func(config *configuration.Config, token string) {
+				config.Api.AlertmanagerWebhookToken = token
+			},
+		},
+	}
+	tests := []struct {
+		name        string
+		configToken string
+		authorize   func(req *http.Request)
+		wantCode    int
+	}{
+		{
+			name:        "Not served without a configured token",
+			configToken: "",
+			authorize:   func(req *http.Request) {},
+			wantCode:    404,
+		},
+		{
+			name:        "Rejects requests without the token",
+			configToken: "secret",
+			authorize:   func(req *http.Request) {},
+			wantCode:    401,
+		},
+		{
+			name:        "Rejects requests with the wrong bearer token",
+			configToken: "secret",
+			authorize:   func(req *http.Request) { req.Header.Set("Authorization", "Bearer not secret") },
+			wantCode:    401,
+		},
+		{
+			name:        "Rejects requests with the wrong basic auth password",
+			configToken: "secret",
+			authorize:   func(req *http.Request) { req.SetBasicAuth("revere", "not secret") },
+			wantCode:    401,
+		},
+		{
+			name:        "Accepts the bearer token",
+			configToken: "secret",
+			authorize:   func(req *http.Request) { req.Header.Set("Authorization", "Bearer secret") },
+			wantCode:    200,
+		},
+		{
+			name:        "Accepts the token as the basic auth password",
+			configToken: "secret",
+			authorize:   func(req *http.Request) { req.SetBasicAuth("revere", "secret") },
+			wantCode:    200,
+		},
+	}
+	for _, webhook := range webhooks {
+		for _, tt := range tests {
+			t.Run(webhook.name+": "+tt.name, func(t *testing.T) {
+				config := testConfig
+				webhook.setToken(&config, tt.configToken)
+				router := NewRouter(&config, &state.State{}, noopCallback, nil, nil, nil)
+				got := httptest.NewRecorder()
+				req, _ := http.NewRequest("POST", webhook.reqUrl, strings.NewReader(webhook.reqBody))
+				tt.authorize(req)
+				router.ServeHTTP(got, req)
+				if got.Code != tt.wantCode {
+					t.Errorf("code %d, want %d: %s", got.Code, tt.wantCode, got.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func Test_postCloudMonitoringWebhook(t *testing.T) {
+	config := testConfig
+	config.Api.CloudMonitoringWebhookToken = "secret"
+	config.ServiceToComponentMapping = []configuration.ServiceToComponentMapping{
+		{ServiceName: "leonardo", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"notebooks", "ui"}},
+	}
+	tests := []struct {
+		name           string
+		reqBody        string
+		callbackErr    error
+		wantCode       int
+		wantComponents []string
+	}{
+		{
+			name: "Handles packet for each affected component",
+			reqBody: `{"version": "1.2", "incident": {"incident_id": "abc", "state": "open", "policy_user_labels": {
+				"revere-service-name": "leonardo", "revere-service-environment": "prod", "revere-alert-type": "major-outage"}}}`,
+			wantCode:       200,
+			wantComponents: []string{"notebooks", "ui"},
+		},
+		{
+			name: "Ignores packet without matching mapping",
+			reqBody: `{"version": "1.2", "incident": {"incident_id": "abc", "state": "open", "policy_user_labels": {
+				"revere-service-name": "sam", "revere-service-environment": "prod", "revere-alert-type": "major-outage"}}}`,
+			wantCode: 200,
+		},
+		{
+			name:     "Ignores packet without labels",
+			reqBody:  `{"version": "1.2", "incident": {"incident_id": "abc", "state": "open"}}`,
+			wantCode: 200,
+		},
+		{
+			name:     "Rejects malformed packet",
+			reqBody:  `{"version": `,
+			wantCode: 400,
+		},
+		{
+			name: "Errors if callback fails",
+			reqBody: `{"version": "1.2", "incident": {"incident_id": "abc", "state": "open", "policy_user_labels": {
+				"revere-service-name": "leonardo", "revere-service-environment": "prod", "revere-alert-type": "major-outage"}}}`,
+			callbackErr:    fmt.Errorf("some error"),
+			wantCode:       500,
+			wantComponents: []string{"notebooks"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotComponents []string
+			router := NewRouter(&config, &state.State{}, func(componentName string, _ *events.AlertEvent) error {
+				gotComponents = append(gotComponents, componentName)
+				return tt.callbackErr
+			}, nil, nil, nil)
+			got := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/webhooks/cloudmonitoring", strings.NewReader(tt.reqBody))
+			req.Header.Set("Authorization", "Bearer secret")
+			router.ServeHTTP(got, req)
+			if got.Code != tt.wantCode {
+				t.Errorf("code %d, want %d", got.Code, tt.wantCode)
+			}
+			if diff := cmp.Diff(tt.wantComponents, gotComponents); diff != "" {
+				t.Errorf("callback components mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_postAlertmanagerWebhook(t *testing.T) {
+	config := testConfig
+	config.Api.AlertmanagerWebhookToken = "secret"
+	config.ServiceToComponentMapping = []configuration.ServiceToComponentMapping{
+		{ServiceName: "rawls", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"workspaces"}},
+		{ServiceName: "leonardo", ServiceEnvironment: "prod", AffectsComponentsNamed: []string{"notebooks"}},
+	}
+	type call struct {
+		ComponentName string
+		IncidentID    string
+		Closed        bool
+	}
+	tests := []struct {
+		name      string
+		reqBody   string
+		wantCode  int
+		wantCalls []call
+	}{
+		{
+			name: "Handles each alert in the payload",
+			reqBody: `{"version": "4", "status": "firing", "alerts": [
+				{"status": "firing", "fingerprint": "abc", "startsAt": "2021-08-01T12:00:00Z", "labels": {
+					"revere_service_name": "rawls", "revere_service_environment": "prod", "revere_alert_type": "major-outage"}},
+				{"status": "resolved", "fingerprint": "def", "startsAt": "2021-08-01T12:00:00Z", "endsAt": "2021-08-01T13:00:00Z", "labels": {
+					"revere_service_name": "leonardo", "revere_service_environment": "prod", "revere_alert_type": "degraded-performance"}},
+				{"status": "firing", "fingerprint": "ghi", "startsAt": "2021-08-01T12:00:00Z", "labels": {"alertname": "Unlabeled"}}
+			]}`,
+			wantCode: 200,
+			wantCalls: []call{
+				{ComponentName: "workspaces", IncidentID: "abc", Closed: false},
+				{ComponentName: "notebooks", IncidentID: "def", Closed: true},
+			},
+		},
+		{
+			name:     "Rejects malformed payload",
+			reqBody:  `{"alerts": "foo"}`,
+			wantCode: 400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCalls []call
+			router := NewRouter(&config, &state.State{}, func(componentName string, event *events.AlertEvent) error {
+				gotCalls = append(gotCalls, call{ComponentName: componentName, IncidentID: event.IncidentID, Closed: event.Ended})
+				return nil
+			}, nil, nil, nil)
+			got := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/webhooks/alertmanager", strings.NewReader(tt.reqBody))
+			req.Header.Set("Authorization", "Bearer secret")
+			router.ServeHTTP(got, req)
+			if got.Code != tt.wantCode {
+				t.Errorf("code %d, want %d", got.Code, tt.wantCode)
+			}
+			if diff := cmp.Diff(tt.wantCalls, gotCalls); diff != "" {
+				t.Errorf("callback mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
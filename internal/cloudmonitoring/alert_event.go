@@ -0,0 +1,43 @@
+package cloudmonitoring
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/events"
+	"time"
+)
+
+// ToAlertEvent translates the packet to the form the rest of Revere handles, parsing Revere's labels.
+// The source is recorded in the event, so it's known how the packet arrived.
+func (p *MonitoringPacket) ToAlertEvent(source string) (*events.AlertEvent, error) {
+	if p.Incident == nil {
+		return nil, fmt.Errorf("packet lacked an incident")
+	}
+	labels, err := p.ParseLabels()
+	if err != nil {
+		return nil, err
+	}
+	event := &events.AlertEvent{
+		Source:             source,
+		IncidentID:         p.Incident.IncidentID,
+		Name:               p.Incident.PolicyName,
+		ServiceName:        labels.ServiceName,
+		ServiceEnvironment: labels.ServiceEnvironment,
+		Status:             labels.AlertType,
+		Ended:              p.Incident.HasEnded(),
+		Summary:            p.Incident.Summary,
+		Attributes:         p.Incident.attributes(),
+	}
+	if p.Incident.StartedAt > 0 {
+		event.StartedAt = time.Unix(p.Incident.StartedAt, 0).UTC()
+	}
+	if p.Incident.EndedAt > 0 {
+		event.EndedAt = time.Unix(p.Incident.EndedAt, 0).UTC()
+	}
+	if p.Incident.Documentation != nil {
+		event.Documentation = p.Incident.Documentation.GetContent()
+	}
+	if p.Incident.URL != "" {
+		event.Links = append(event.Links, events.Link{Name: "incident", URL: p.Incident.URL})
+	}
+	return event, nil
+}
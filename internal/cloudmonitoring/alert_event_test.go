@@ -0,0 +1,108 @@
+package cloudmonitoring
+
+import (
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/monitoring/v3"
+	"testing"
+	"time"
+)
+
+func TestMonitoringPacket_ToAlertEvent(t *testing.T) {
+	labels := map[string]string{
+		"revere-service-name":        "sam",
+		"revere-service-environment": "prod",
+		"revere-alert-type":          "partial-outage",
+	}
+	tests := []struct {
+		name    string
+		packet  MonitoringPacket
+		want    *events.AlertEvent
+		wantErr bool
+	}{
+		{
+			name: "Translates open incident",
+			packet: MonitoringPacket{Incident: &MonitoringIncident{
+				IncidentID:       "abc",
+				URL:              "https://console.cloud.google.com/monitoring/alerting/incidents/abc",
+				State:            "open",
+				StartedAt:        1627819200,
+				Summary:          "Latency is high",
+				Resource:         &MonitoringResource{Type: "k8s_container", Labels: map[string]string{"project_id": "broad-dsde-prod"}},
+				PolicyName:       "Sam latency",
+				PolicyUserLabels: labels,
+				Documentation:    &monitoring.AlertPolicy_Documentation{Content: "Check the dashboard"},
+			}},
+			want: &events.AlertEvent{
+				Source:             "test",
+				IncidentID:         "abc",
+				Name:               "Sam latency",
+				ServiceName:        "sam",
+				ServiceEnvironment: "prod",
+				Status:             statuspagetypes.PartialOutage,
+				StartedAt:          time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC),
+				Summary:            "Latency is high",
+				Documentation:      "Check the dashboard",
+				Links: []events.Link{
+					{Name: "incident", URL: "https://console.cloud.google.com/monitoring/alerting/incidents/abc"},
+				},
+				Attributes: map[string]string{
+					"policy.name":                              "Sam latency",
+					"resource.type":                            "k8s_container",
+					"resource.labels.project_id":               "broad-dsde-prod",
+					"policy.labels.revere-service-name":        "sam",
+					"policy.labels.revere-service-environment": "prod",
+					"policy.labels.revere-alert-type":          "partial-outage",
+				},
+			},
+		},
+		{
+			name: "Translates closed incident",
+			packet: MonitoringPacket{Incident: &MonitoringIncident{
+				IncidentID:       "abc",
+				State:            "closed",
+				StartedAt:        1627819200,
+				EndedAt:          1627822800,
+				PolicyUserLabels: labels,
+			}},
+			want: &events.AlertEvent{
+				Source:             "test",
+				IncidentID:         "abc",
+				ServiceName:        "sam",
+				ServiceEnvironment: "prod",
+				Status:             statuspagetypes.PartialOutage,
+				Ended:              true,
+				StartedAt:          time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC),
+				EndedAt:            time.Date(2021, 8, 1, 13, 0, 0, 0, time.UTC),
+				Attributes: map[string]string{
+					"policy.labels.revere-service-name":        "sam",
+					"policy.labels.revere-service-environment": "prod",
+					"policy.labels.revere-alert-type":          "partial-outage",
+				},
+			},
+		},
+		{
+			name:    "Errors without labels",
+			packet:  MonitoringPacket{Incident: &MonitoringIncident{IncidentID: "abc"}},
+			wantErr: true,
+		},
+		{
+			name:    "Errors without incident",
+			packet:  MonitoringPacket{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.packet.ToAlertEvent("test")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToAlertEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ToAlertEvent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package cloudmonitoring
 
-import "google.golang.org/genproto/googleapis/monitoring/v3"
+import (
+	"google.golang.org/genproto/googleapis/monitoring/v3"
+)
 
 // MonitoringPacket handles payloads from Webhook *or Pub/Sub*
 // https://cloud.google.com/monitoring/support/notification-options#webhooks
@@ -69,3 +71,29 @@ func (i *MonitoringIncident) HasEnded() bool {
 		return i.EndedAt > i.StartedAt
 	}
 }
+
+// attributes gathers every attribute of the incident that it has, keyed by name like "resource.labels.project_id"
+// (see configuration.IncidentMatcher)
+func (i *MonitoringIncident) attributes() map[string]string {
+	attributes := make(map[string]string)
+	setIfPresent := func(name string, value string) {
+		if value != "" {
+			attributes[name] = value
+		}
+	}
+	setIfPresent("policy.name", i.PolicyName)
+	setIfPresent("condition.name", i.ConditionName)
+	if i.Resource != nil {
+		setIfPresent("resource.type", i.Resource.Type)
+		for key, value := range i.Resource.Labels {
+			attributes["resource.labels."+key] = value
+		}
+	}
+	if i.Metric != nil {
+		setIfPresent("metric.type", i.Metric.Type)
+	}
+	for key, value := range i.PolicyUserLabels {
+		attributes["policy.labels."+key] = value
+	}
+	return attributes
+}
@@ -82,3 +82,33 @@ func TestMonitoringIncident_HasEnded(t *testing.T) {
 		})
 	}
 }
+
+func TestMonitoringIncident_attributes(t *testing.T) {
+	incident := &MonitoringIncident{
+		PolicyName:       "High latency",
+		PolicyUserLabels: map[string]string{"revere-service-name": "sam"},
+		Resource:         &MonitoringResource{Type: "k8s_container", Labels: map[string]string{"project_id": "broad-dsde-prod"}},
+	}
+	tests := []struct {
+		name        string
+		attribute   string
+		want        string
+		wantPresent bool
+	}{
+		{name: "policy name", attribute: "policy.name", want: "High latency", wantPresent: true},
+		{name: "policy label", attribute: "policy.labels.revere-service-name", want: "sam", wantPresent: true},
+		{name: "resource type", attribute: "resource.type", want: "k8s_container", wantPresent: true},
+		{name: "resource label", attribute: "resource.labels.project_id", want: "broad-dsde-prod", wantPresent: true},
+		{name: "missing resource label", attribute: "resource.labels.zone", wantPresent: false},
+		{name: "missing metric", attribute: "metric.type", wantPresent: false},
+		{name: "unknown attribute", attribute: "foo", wantPresent: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, present := incident.attributes()[tt.attribute]
+			if got != tt.want || present != tt.wantPresent {
+				t.Errorf("attributes()[%q] = %q, %v, want %q, %v", tt.attribute, got, present, tt.want, tt.wantPresent)
+			}
+		})
+	}
+}
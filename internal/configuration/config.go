@@ -3,8 +3,15 @@ package configuration
 import (
 	"fmt"
 	"gopkg.in/go-playground/validator.v9"
+	htmltemplate "html/template"
+	"net"
 	"os"
+	"path"
+	"regexp"
 	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -35,6 +42,12 @@ type Config struct {
 		Redirects int // default: 3
 		// Number of exponential-backoff retries to make
 		Retries int // default: 3
+		// Longest to wait before retrying, even if a rate-limited response's Retry-After header asks for longer;
+		// zero means Resty's default
+		MaxRetryWaitSeconds int // default: 60
+		// Most requests to make to Statuspage per second, so as to stay within its rate limit; zero means no limit.
+		// Other services' requests aren't limited
+		RequestsPerSecond float64 // default: 1
 	}
 
 	Statuspage struct {
@@ -42,10 +55,82 @@ type Config struct {
 		// NOTE: May be set via REVERE_STATUSPAGE_APIKEY in environment
 		ApiKey string `validate:"required"`
 		// ID of the particular page to interact with
-		PageID     string           `validate:"required"`
-		ApiRoot    string           // default: "https://api.statuspage.io/v1"
+		PageID  string `validate:"required"`
+		ApiRoot string // default: "https://api.statuspage.io/v1"
+		// Ungrouped components are positioned on the page in the order they're given here, followed by groups in
+		// the order they're given here; components within a group are also ordered as they're given here
 		Components []Component      `validate:"unique=Name,dive"`
 		Groups     []ComponentGroup `validate:"unique=Name,dive"`
+		// What "revere prepare" does with components and groups on Statuspage that aren't in this file, since
+		// deleting them also deletes their uptime history:
+		// - "never" leaves them alone
+		// - "managed" deletes only those that Revere created, as recorded in ManagedResourcesFile
+		// - "flag" deletes them only if AllowDelete is set
+		DeletionPolicy string `validate:"oneof=never managed flag"` // default: "flag"
+		// NOTE: May be set via --allow-delete command line flag to "revere prepare"
+		AllowDelete bool
+		// Where "revere prepare" records the IDs of components and groups it creates; only used by the "managed"
+		// policy, and must be kept between runs, so point it at persistent storage
+		// NOTE: May be set via REVERE_STATUSPAGE_MANAGEDRESOURCESFILE in environment
+		ManagedResourcesFile string // default: "revere-managed.json"
+	}
+
+	StatuspageIncidents struct {
+		// Whether to open a Statuspage incident when a component stops being operational, post updates to it
+		// as further alerts arrive, and resolve it when the component is operational again
+		Enabled bool
+		// Go text/template strings for the incident's title and messages, executed with
+		// statuspage.IncidentTemplateData
+		TitleTemplate        string // default: "{{.ComponentName}}: {{.Status}}"
+		BodyTemplate         string // default: the alert's documentation, or a generic message
+		ResolvedBodyTemplate string // default: a generic message
+		// Whether Statuspage should notify the page's subscribers about the incident
+		DeliverNotifications bool
+	}
+
+	StatusWriter struct {
+		// How often to compare each component's status on Statuspage with what Revere desires and correct it,
+		// besides whenever a desired status changes
+		IntervalSeconds int `validate:"min=1"` // default: 60
+	}
+
+	Slack struct {
+		// Whether to post to Slack whenever a component's status changes
+		Enabled bool
+		// Slack incoming webhook to post changes to components that no route matches, which aren't posted
+		// anywhere if it's empty
+		// NOTE: May be set via REVERE_SLACK_WEBHOOKURL in environment
+		WebhookURL string
+		// Routes post changes to particular components, or to components in particular groups, to other
+		// webhooks; every route matching a component is posted to
+		Routes []SlackRoute `validate:"dive"`
+	}
+
+	Email struct {
+		// Whether to email whenever a component's status changes
+		Enabled bool
+		// SMTP server to send through, like "smtp.gmail.com:587"
+		SmtpAddress string
+		// Credentials for the SMTP server, if it requires authentication
+		Username string
+		// NOTE: May be set via REVERE_EMAIL_PASSWORD in environment
+		Password string
+		// Address to send from
+		From string `validate:"omitempty,email"`
+		// Addresses to email about changes to components that no route matches, which aren't emailed anywhere
+		// if it's empty
+		Recipients []string `validate:"unique,dive,email"`
+		// Routes email changes to particular components, or to components in particular groups, to other
+		// addresses; every route matching a component is emailed
+		Routes []EmailRoute `validate:"dive"`
+		// Changes within this long of the first are batched into one email to each recipient; zero emails each
+		// change right away
+		BatchSeconds int `validate:"min=0"` // default: 60
+		// Go templates for each email's subject and its plain-text and HTML bodies, executed with
+		// email.BatchData; the HTML body is an html/template so its data is escaped
+		SubjectTemplate string // default: the component and its status, or a count of changes
+		TextTemplate    string // default: each change, its cause, and the component's open incidents
+		HTMLTemplate    string // default: the same as TextTemplate, as HTML
 	}
 
 	Pubsub struct {
@@ -63,10 +148,56 @@ type Config struct {
 		Debug bool
 		// Forcibly silence the request log
 		Silent bool
+		// Bearer token required by the /api/v1/admin endpoints, which are disabled if it is empty
+		// NOTE: May be set via REVERE_API_ADMINTOKEN in environment
+		AdminToken string
+		// Secret required by the /api/v1/webhooks/cloudmonitoring endpoint, which is disabled if it is empty; it may
+		// be given as a bearer token or as the password of basic auth
+		// NOTE: May be set via REVERE_API_CLOUDMONITORINGWEBHOOKTOKEN in environment
+		CloudMonitoringWebhookToken string
+		// Secret required by the /api/v1/webhooks/alertmanager endpoint, likewise
+		// NOTE: May be set via REVERE_API_ALERTMANAGERWEBHOOKTOKEN in environment
+		AlertmanagerWebhookToken string
+	}
+
+	Persistence struct {
+		// Where open incidents are stored so they survive restarts, either "memory" (not persisted) or "file"
+		Backend string `validate:"oneof=memory file"` // default: "memory"
+		// Path to the file used by the "file" backend
+		FilePath string // default: "revere-state.json"
+	}
+
+	DeadLetter struct {
+		// How many times to try handling an alert for a component before giving up on it
+		MaxAttempts int `validate:"min=1"` // default: 5
+		// How long to wait before the first retry; each retry waits twice as long as the last
+		InitialBackoffMillis int `validate:"min=0"` // default: 1000
+		// Where alerts that couldn't be handled are written, besides being listed by the admin API:
+		// - "file" appends them as JSON lines to FilePath
+		// - "pubsub" publishes them to TopicID, in Pubsub.ProjectID
+		// - "none" doesn't write them anywhere
+		Sink     string `validate:"oneof=none file pubsub"` // default: "file"
+		FilePath string // default: "revere-dead-letters.jsonl"
+		TopicID  string // required if Sink is "pubsub"
+	}
+
+	InheritedStatus struct {
+		// What to do when a component is already non-operational on Statuspage when Revere starts, but
+		// Revere has no stored incidents to explain why:
+		// - "keep" holds the status until an alert affecting the component is resolved
+		// - "reset" sets the component back to operational immediately
+		// - "wait" holds the status until an alert affecting the component arrives or WaitMinutes pass
+		Policy string `validate:"oneof=keep reset wait"` // default: "keep"
+		// How long the "wait" policy holds the status before setting the component back to operational
+		WaitMinutes int `validate:"min=0"` // default: 30
 	}
 
 	// Correlate developed services to user-facing components
 	ServiceToComponentMapping []ServiceToComponentMapping `validate:"dive"`
+
+	// Planned work to announce on Statuspage, during which affected components are shown as under maintenance
+	// NOTE: More may be scheduled via the /api/v1/admin/maintenance endpoints
+	MaintenanceWindows []MaintenanceWindow `validate:"unique=Name,dive"`
 }
 
 // Component configuration--note that leaving any of the below unfilled will use Go's "zero" value (false/empty)
@@ -74,12 +205,40 @@ type Component struct {
 	// Unique but user-readable component name
 	Name        string `validate:"required"`
 	Description string
+	// Names the component has had before, so renaming it patches the existing component on Statuspage
+	// (keeping its uptime history) instead of replacing it
+	PreviousNames []string `validate:"unique"`
+	// Optional Statuspage ID to pin the component to, taking precedence over matching by name
+	ID string
 	// If the component should be hidden to users while operational
 	OnlyShowIfDegraded bool
 	// If uptime data should be hidden and go unrecorded
 	HideUptime bool
 	// Date the component existed from, in the form YYYY-MM-DD
 	StartDate string `validate:"required"`
+	// Optional damping of status changes caused by alerts, for components with noisy alerts:
+	// How long alerts must keep the component's status worse before it's degraded
+	DegradeAfterSeconds int `validate:"min=0"`
+	// How long alerts must keep the component's status better before it recovers
+	RecoverAfterSeconds int `validate:"min=0"`
+	// At most MaxTransitions status changes are made per TransitionWindowMinutes; further changes wait
+	MaxTransitions          int `validate:"min=0"`
+	TransitionWindowMinutes int `validate:"min=0"`
+	// Optional rules making the component's status worse than its worst alert when many alerts are open at once
+	Escalations []Escalation `validate:"dive"`
+}
+
+// Escalation raises a component's status when enough of its alerts are open at once. At least one threshold
+// must be given; the escalation applies when any is met.
+type Escalation struct {
+	// Number of open alerts
+	MinIncidents int `validate:"min=0"`
+	// Number of distinct services with open alerts
+	MinServices int `validate:"min=0"`
+	// Only alerts at least this bad are counted, like "partial-outage"
+	CountingAtLeast string // default: "degraded-performance"
+	// Status to escalate to, like "major-outage"
+	EscalateTo string `validate:"required"`
 }
 
 // ComponentGroup configuration--note that leaving any of the below unfilled will use Go's "zero" value (false/empty)
@@ -87,16 +246,112 @@ type ComponentGroup struct {
 	// Unique but user-readable group name
 	Name        string `validate:"required"`
 	Description string
+	// Names the group has had before, so renaming it patches the existing group on Statuspage
+	PreviousNames []string `validate:"unique"`
+	// Optional Statuspage ID to pin the group to, taking precedence over matching by name
+	ID string
 	// Exact names of components to include in the group (components should never exist in more than one group)
 	ComponentNames []string `validate:"required,unique"`
 }
 
+// SlackRoute configuration, for posting changes to some components to a particular Slack channel
+type SlackRoute struct {
+	// Slack incoming webhook for the channel, defaulting to Slack.WebhookURL
+	WebhookURL string
+	// Exact names of components, or of groups of components, whose changes are posted to the channel
+	ComponentNames []string `validate:"unique"`
+	GroupNames     []string `validate:"unique"`
+}
+
+// EmailRoute configuration, for emailing changes to some components to particular addresses
+type EmailRoute struct {
+	Recipients []string `validate:"required,unique,dive,email"`
+	// Exact names of components, or of groups of components, whose changes are emailed to the recipients
+	ComponentNames []string `validate:"unique"`
+	GroupNames     []string `validate:"unique"`
+}
+
+// MaintenanceWindow configuration, for planned work on some components
+type MaintenanceWindow struct {
+	// Unique name, used as the title of the window's Statuspage scheduled incident
+	Name        string `validate:"required"`
+	Description string
+	// Exact names of components the work affects
+	AffectsComponentsNamed []string `validate:"required,unique"`
+	// When the work starts and ends, in RFC 3339 form like "2021-07-06T20:00:00-04:00"
+	Start string `validate:"required"`
+	End   string `validate:"required"`
+}
+
 // ServiceToComponentMapping correlates developed services ("Rawls", "Leonardo") in particular environments ("prod")
-// to user-facing components ("Notebooks", "Terra UI")
+// to user-facing components ("Notebooks", "Terra UI").
+// ServiceName, ServiceEnvironment, and MatchIncident patterns are globs like "dev*" (see path.Match), or regular
+// expressions like "dev|staging" if Regex is set; either way they must match the whole value, so plain names match
+// exactly.
 type ServiceToComponentMapping struct {
-	ServiceName            string   `validate:"required"`
-	ServiceEnvironment     string   `validate:"required"`
-	AffectsComponentsNamed []string `validate:"unique"`
+	ServiceName        string `validate:"required"`
+	ServiceEnvironment string `validate:"required"`
+	Regex              bool
+	// Optional further conditions on the alert's incident, all of which must match
+	MatchIncident          []IncidentMatcher `validate:"dive"`
+	AffectsComponentsNamed []string          `validate:"unique"`
+	// Optional changes to the status the service's alerts give particular components it affects
+	StatusTranslations []StatusTranslation `validate:"unique=ComponentName,dive"`
+	// regexps holds the patterns compiled by CompilePatterns, if Regex is set
+	regexps map[string]*regexp.Regexp
+}
+
+// CompilePatterns checks that the mapping's patterns are valid globs or regular expressions, compiling regular
+// expressions once so that MatchesPattern doesn't for every alert. Regular expressions are anchored, since globs
+// must match the whole value too.
+func (m *ServiceToComponentMapping) CompilePatterns() error {
+	patterns := []string{m.ServiceName, m.ServiceEnvironment}
+	for _, matcher := range m.MatchIncident {
+		patterns = append(patterns, matcher.Pattern)
+	}
+	m.regexps = make(map[string]*regexp.Regexp)
+	for _, pattern := range patterns {
+		if m.Regex {
+			compiled, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern))
+			if err != nil {
+				return fmt.Errorf("pattern %s invalid: %w", pattern, err)
+			}
+			m.regexps[pattern] = compiled
+		} else if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("pattern %s invalid: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// MatchesPattern matches the whole value against one of the mapping's patterns, as a glob or, if Regex is set, as
+// a regular expression. Regular expressions that CompilePatterns didn't compile never match.
+func (m *ServiceToComponentMapping) MatchesPattern(pattern string, value string) bool {
+	if m.Regex {
+		compiled, found := m.regexps[pattern]
+		return found && compiled.MatchString(value)
+	}
+	matched, _ := path.Match(pattern, value)
+	return matched
+}
+
+// IncidentMatcher is a condition on an attribute of an alert's incident
+type IncidentMatcher struct {
+	// One of "policy.name", "policy.labels.<label>", "condition.name", "resource.type", "resource.labels.<label>",
+	// or "metric.type"; attributes the incident doesn't have never match
+	Attribute string `validate:"required"`
+	Pattern   string
+}
+
+// StatusTranslation changes the status a service's alerts give one of the components it affects, for components
+// that aren't as badly affected as the alerts say
+type StatusTranslation struct {
+	// Exact name of a component the service affects
+	ComponentName string `validate:"required"`
+	// Alert statuses to replace with others, like "major-outage: partial-outage"
+	Translate map[string]string
+	// Worst status the service's alerts may give the component, like "degraded-performance", applied after Translate
+	AtMost string
 }
 
 // newDefaultConfig sets config defaults only as described above
@@ -104,8 +359,40 @@ func newDefaultConfig() *Config {
 	var config Config
 	config.Client.Redirects = 3
 	config.Client.Retries = 3
+	config.Client.MaxRetryWaitSeconds = 60
+	config.Client.RequestsPerSecond = 1
 	config.Statuspage.ApiRoot = "https://api.statuspage.io/v1"
+	config.Statuspage.DeletionPolicy = "flag"
+	config.Statuspage.ManagedResourcesFile = "revere-managed.json"
+	config.StatuspageIncidents.TitleTemplate = "{{.ComponentName}}: {{.Status}}"
+	config.StatuspageIncidents.BodyTemplate = "{{if .Documentation}}{{.Documentation}}{{else}}" +
+		"We are investigating reports of {{.Status}} affecting {{.ComponentName}}.{{end}}"
+	config.StatuspageIncidents.ResolvedBodyTemplate = "{{.ComponentName}} is operational again."
+	config.StatusWriter.IntervalSeconds = 60
+	config.Email.BatchSeconds = 60
+	config.Email.SubjectTemplate = "{{if eq (len .Changes) 1}}{{with index .Changes 0}}" +
+		"{{.ComponentName}} is now {{.NewStatus}}{{end}}{{else}}{{len .Changes}} status changes{{end}}"
+	config.Email.TextTemplate = "{{range .Changes}}" +
+		"{{.ComponentName}} is now {{.NewStatus}} (was {{.OldStatus}}) as of {{.At.UTC.Format \"2006-01-02 15:04 MST\"}}\n" +
+		"{{if .PolicyName}}Caused by {{.PolicyName}}{{if .Summary}}: {{.Summary}}{{end}}{{if .URL}} <{{.URL}}>{{end}}\n{{end}}" +
+		"{{range .Incidents}}- {{.Name}} ({{.Status}}){{if .Summary}}: {{.Summary}}{{end}}{{if .URL}} <{{.URL}}>{{end}}\n{{end}}" +
+		"\n{{end}}"
+	config.Email.HTMLTemplate = "{{range .Changes}}" +
+		"<p><b>{{.ComponentName}}</b> is now <b>{{.NewStatus}}</b> (was {{.OldStatus}}) as of {{.At.UTC.Format \"2006-01-02 15:04 MST\"}}</p>" +
+		"{{if .PolicyName}}<p>Caused by {{if .URL}}<a href=\"{{.URL}}\">{{.PolicyName}}</a>{{else}}{{.PolicyName}}{{end}}" +
+		"{{if .Summary}}: {{.Summary}}{{end}}</p>{{end}}" +
+		"{{if .Incidents}}<ul>{{range .Incidents}}<li>{{if .URL}}<a href=\"{{.URL}}\">{{.Name}}</a>{{else}}{{.Name}}{{end}} " +
+		"({{.Status}}){{if .Summary}}: {{.Summary}}{{end}}</li>{{end}}</ul>{{end}}" +
+		"{{end}}"
 	config.Api.Port = 8080
+	config.Persistence.Backend = "memory"
+	config.Persistence.FilePath = "revere-state.json"
+	config.DeadLetter.MaxAttempts = 5
+	config.DeadLetter.InitialBackoffMillis = 1000
+	config.DeadLetter.Sink = "file"
+	config.DeadLetter.FilePath = "revere-dead-letters.jsonl"
+	config.InheritedStatus.Policy = "keep"
+	config.InheritedStatus.WaitMinutes = 30
 	return &config
 }
 
@@ -115,6 +402,30 @@ func readEnvironmentVariables(config *Config) error {
 	if present {
 		config.Statuspage.ApiKey = apiKey
 	}
+	managedResourcesFile, present := os.LookupEnv("REVERE_STATUSPAGE_MANAGEDRESOURCESFILE")
+	if present {
+		config.Statuspage.ManagedResourcesFile = managedResourcesFile
+	}
+	slackWebhookURL, present := os.LookupEnv("REVERE_SLACK_WEBHOOKURL")
+	if present {
+		config.Slack.WebhookURL = slackWebhookURL
+	}
+	emailPassword, present := os.LookupEnv("REVERE_EMAIL_PASSWORD")
+	if present {
+		config.Email.Password = emailPassword
+	}
+	adminToken, present := os.LookupEnv("REVERE_API_ADMINTOKEN")
+	if present {
+		config.Api.AdminToken = adminToken
+	}
+	cloudMonitoringWebhookToken, present := os.LookupEnv("REVERE_API_CLOUDMONITORINGWEBHOOKTOKEN")
+	if present {
+		config.Api.CloudMonitoringWebhookToken = cloudMonitoringWebhookToken
+	}
+	alertmanagerWebhookToken, present := os.LookupEnv("REVERE_API_ALERTMANAGERWEBHOOKTOKEN")
+	if present {
+		config.Api.AlertmanagerWebhookToken = alertmanagerWebhookToken
+	}
 	stringPort, present := os.LookupEnv("REVERE_API_PORT")
 	if present {
 		intPort, err := strconv.Atoi(stringPort)
@@ -126,6 +437,171 @@ func readEnvironmentVariables(config *Config) error {
 	return nil
 }
 
+// identity holds what a component or group may be matched to Statuspage by
+type identity struct {
+	name          string
+	previousNames []string
+	id            string
+}
+
+// validateIdentities checks that no two components or groups could be matched to the same one on Statuspage
+func validateIdentities(kind string, identities []identity) error {
+	names := make(map[string]string)
+	ids := make(map[string]string)
+	for _, identity := range identities {
+		names[identity.name] = identity.name
+	}
+	for _, identity := range identities {
+		for _, previousName := range identity.previousNames {
+			if owner, present := names[previousName]; present {
+				return fmt.Errorf("%s %s has previous name %s, already used by %s", kind, identity.name, previousName, owner)
+			}
+			names[previousName] = identity.name
+		}
+		if identity.id != "" {
+			if owner, present := ids[identity.id]; present {
+				return fmt.Errorf("%s %s is pinned to ID %s, already pinned by %s", kind, identity.name, identity.id, owner)
+			}
+			ids[identity.id] = identity.name
+		}
+	}
+	return nil
+}
+
+// validateAlertStatus checks that the string is a kebab-case status an alert could give a component
+func validateAlertStatus(status string) error {
+	switch status {
+	case "degraded-performance", "partial-outage", "major-outage":
+		return nil
+	}
+	return fmt.Errorf("%s isn't one of degraded-performance, partial-outage, or major-outage", status)
+}
+
+// validateServiceMappingPatterns checks that the mapping only matches on known incident attributes and compiles
+// its patterns, which must be valid globs or regular expressions
+func validateServiceMappingPatterns(serviceMapping *ServiceToComponentMapping) error {
+	for _, matcher := range serviceMapping.MatchIncident {
+		if !validIncidentAttribute(matcher.Attribute) {
+			return fmt.Errorf("incident attribute %s unknown", matcher.Attribute)
+		}
+	}
+	return serviceMapping.CompilePatterns()
+}
+
+// validIncidentAttribute returns if the attribute is one IncidentMatcher supports
+func validIncidentAttribute(attribute string) bool {
+	switch attribute {
+	case "policy.name", "condition.name", "resource.type", "metric.type":
+		return true
+	}
+	for _, prefix := range []string{"policy.labels.", "resource.labels."} {
+		if strings.HasPrefix(attribute, prefix) && len(attribute) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateStatusTranslation checks that the translation is for a component the mapping affects and that it
+// only uses alert statuses
+func validateStatusTranslation(affectsComponentsNamed []string, translation StatusTranslation) error {
+	affected := false
+	for _, componentName := range affectsComponentsNamed {
+		if componentName == translation.ComponentName {
+			affected = true
+		}
+	}
+	if !affected {
+		return fmt.Errorf("component %s isn't affected by the service", translation.ComponentName)
+	}
+	for from, to := range translation.Translate {
+		if err := validateAlertStatus(from); err != nil {
+			return err
+		}
+		if err := validateAlertStatus(to); err != nil {
+			return err
+		}
+	}
+	if translation.AtMost != "" {
+		return validateAlertStatus(translation.AtMost)
+	}
+	return nil
+}
+
+// validateSlackRoutes checks that Slack has somewhere to post and that its routes refer to components and
+// groups that exist
+func validateSlackRoutes(config *Config, componentNames map[string]struct{}) error {
+	if config.Slack.WebhookURL == "" && len(config.Slack.Routes) == 0 {
+		return fmt.Errorf("slack needs a webhook URL or routes")
+	}
+	for index, route := range config.Slack.Routes {
+		if route.WebhookURL == "" && config.Slack.WebhookURL == "" {
+			return fmt.Errorf("slack route %d needs a webhook URL, since there's no default", index)
+		}
+		if err := validateRouteNames(config, componentNames, route.ComponentNames, route.GroupNames); err != nil {
+			return fmt.Errorf("slack route %d invalid: %w", index, err)
+		}
+	}
+	return nil
+}
+
+// validateEmail checks that email has a server, a sender, and recipients, that its routes refer to components
+// and groups that exist, and that its templates parse
+func validateEmail(config *Config, componentNames map[string]struct{}) error {
+	if config.Email.SmtpAddress == "" {
+		return fmt.Errorf("email needs an SMTP address")
+	}
+	if _, _, err := net.SplitHostPort(config.Email.SmtpAddress); err != nil {
+		return fmt.Errorf("email SMTP address invalid: %w", err)
+	}
+	if config.Email.From == "" {
+		return fmt.Errorf("email needs a from address")
+	}
+	if len(config.Email.Recipients) == 0 && len(config.Email.Routes) == 0 {
+		return fmt.Errorf("email needs recipients or routes")
+	}
+	for index, route := range config.Email.Routes {
+		if err := validateRouteNames(config, componentNames, route.ComponentNames, route.GroupNames); err != nil {
+			return fmt.Errorf("email route %d invalid: %w", index, err)
+		}
+	}
+	for name, text := range map[string]string{
+		"subject": config.Email.SubjectTemplate,
+		"text":    config.Email.TextTemplate,
+	} {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return fmt.Errorf("email %s template invalid: %w", name, err)
+		}
+	}
+	if _, err := htmltemplate.New("html").Parse(config.Email.HTMLTemplate); err != nil {
+		return fmt.Errorf("email html template invalid: %w", err)
+	}
+	return nil
+}
+
+// validateRouteNames checks that an output's route matches something and only refers to components and groups
+// that exist
+func validateRouteNames(config *Config, componentNames map[string]struct{}, routeComponentNames []string, routeGroupNames []string) error {
+	if len(routeComponentNames) == 0 && len(routeGroupNames) == 0 {
+		return fmt.Errorf("needs component or group names")
+	}
+	for _, componentName := range routeComponentNames {
+		if _, present := componentNames[componentName]; !present {
+			return fmt.Errorf("refers to non-existent component %s", componentName)
+		}
+	}
+	groupNames := make(map[string]struct{})
+	for _, group := range config.Statuspage.Groups {
+		groupNames[group.Name] = struct{}{}
+	}
+	for _, groupName := range routeGroupNames {
+		if _, present := groupNames[groupName]; !present {
+			return fmt.Errorf("refers to non-existent group %s", groupName)
+		}
+	}
+	return nil
+}
+
 // secondaryConfigValidation performs logical validation that can't be captured by struct tags
 func secondaryConfigValidation(config *Config) error {
 	// Go compiler optimized to use map[string]struct{} like a Set (no alloc for values)
@@ -133,13 +609,90 @@ func secondaryConfigValidation(config *Config) error {
 	for _, component := range config.Statuspage.Components {
 		componentNames[component.Name] = struct{}{}
 	}
-	for _, serviceMapping := range config.ServiceToComponentMapping {
+	for i := range config.ServiceToComponentMapping {
+		serviceMapping := &config.ServiceToComponentMapping[i]
 		for _, componentName := range serviceMapping.AffectsComponentsNamed {
 			if _, present := componentNames[componentName]; !present {
 				return fmt.Errorf("mapping for service %s affects non-existent component %s",
 					serviceMapping.ServiceName, componentName)
 			}
 		}
+		if err := validateServiceMappingPatterns(serviceMapping); err != nil {
+			return fmt.Errorf("mapping for service %s invalid: %w", serviceMapping.ServiceName, err)
+		}
+		for _, translation := range serviceMapping.StatusTranslations {
+			if err := validateStatusTranslation(serviceMapping.AffectsComponentsNamed, translation); err != nil {
+				return fmt.Errorf("mapping for service %s has invalid status translation: %w", serviceMapping.ServiceName, err)
+			}
+		}
+	}
+	for _, window := range config.MaintenanceWindows {
+		for _, componentName := range window.AffectsComponentsNamed {
+			if _, present := componentNames[componentName]; !present {
+				return fmt.Errorf("maintenance window %s affects non-existent component %s", window.Name, componentName)
+			}
+		}
+		start, err := time.Parse(time.RFC3339, window.Start)
+		if err != nil {
+			return fmt.Errorf("maintenance window %s start invalid: %w", window.Name, err)
+		}
+		end, err := time.Parse(time.RFC3339, window.End)
+		if err != nil {
+			return fmt.Errorf("maintenance window %s end invalid: %w", window.Name, err)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("maintenance window %s must end after it starts", window.Name)
+		}
+	}
+	for _, component := range config.Statuspage.Components {
+		for _, escalation := range component.Escalations {
+			if escalation.MinIncidents == 0 && escalation.MinServices == 0 {
+				return fmt.Errorf("escalation of component %s needs MinIncidents or MinServices", component.Name)
+			}
+			if err := validateAlertStatus(escalation.EscalateTo); err != nil {
+				return fmt.Errorf("escalation of component %s invalid: %w", component.Name, err)
+			}
+			if escalation.CountingAtLeast != "" {
+				if err := validateAlertStatus(escalation.CountingAtLeast); err != nil {
+					return fmt.Errorf("escalation of component %s invalid: %w", component.Name, err)
+				}
+			}
+		}
+	}
+	if config.DeadLetter.Sink == "pubsub" && config.DeadLetter.TopicID == "" {
+		return fmt.Errorf("dead letter topic ID is required for the pubsub sink")
+	}
+	if config.Slack.Enabled {
+		if err := validateSlackRoutes(config, componentNames); err != nil {
+			return err
+		}
+	}
+	if config.Email.Enabled {
+		if err := validateEmail(config, componentNames); err != nil {
+			return err
+		}
+	}
+	var componentIdentities, groupIdentities []identity
+	for _, component := range config.Statuspage.Components {
+		componentIdentities = append(componentIdentities, identity{component.Name, component.PreviousNames, component.ID})
+	}
+	for _, group := range config.Statuspage.Groups {
+		groupIdentities = append(groupIdentities, identity{group.Name, group.PreviousNames, group.ID})
+	}
+	if err := validateIdentities("component", componentIdentities); err != nil {
+		return err
+	}
+	if err := validateIdentities("group", groupIdentities); err != nil {
+		return err
+	}
+	for name, text := range map[string]string{
+		"title":         config.StatuspageIncidents.TitleTemplate,
+		"body":          config.StatuspageIncidents.BodyTemplate,
+		"resolved body": config.StatuspageIncidents.ResolvedBodyTemplate,
+	} {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return fmt.Errorf("statuspage incident %s template invalid: %w", name, err)
+		}
 	}
 	return nil
 }
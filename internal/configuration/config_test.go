@@ -22,6 +22,18 @@ func TestAssembleConfig(t *testing.T) {
 			want:           nil,
 			wantErr:        true,
 		},
+		{
+			name: "Errors on unknown persistence backend",
+			configureViper: func(v *viper.Viper) {
+				v.Set("Statuspage.ApiKey", "foo")
+				v.Set("Statuspage.PageID", "bar")
+				v.Set("Pubsub.ProjectID", "test-project")
+				v.Set("Pubsub.SubscriptionID", "test-subscription")
+				v.Set("Persistence.Backend", "postgres")
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "Correctly parses minimal config",
 			configureViper: func(v *viper.Viper) {
@@ -33,32 +45,103 @@ func TestAssembleConfig(t *testing.T) {
 			want: &Config{
 				Verbose: false,
 				Client: struct {
-					Redirects int
-					Retries   int
+					Redirects           int
+					Retries             int
+					MaxRetryWaitSeconds int
+					RequestsPerSecond   float64
 				}{
-					Redirects: 3,
-					Retries:   3,
+					Redirects:           3,
+					Retries:             3,
+					MaxRetryWaitSeconds: 60,
+					RequestsPerSecond:   1,
 				},
 				Statuspage: struct {
-					ApiKey     string `validate:"required"`
-					PageID     string `validate:"required"`
-					ApiRoot    string
-					Components []Component      `validate:"unique=Name,dive"`
-					Groups     []ComponentGroup `validate:"unique=Name,dive"`
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					ApiKey:               "foo",
+					PageID:               "bar",
+					ApiRoot:              "https://api.statuspage.io/v1",
+					DeletionPolicy:       "flag",
+					ManagedResourcesFile: "revere-managed.json",
+				},
+				StatuspageIncidents: struct {
+					Enabled              bool
+					TitleTemplate        string
+					BodyTemplate         string
+					ResolvedBodyTemplate string
+					DeliverNotifications bool
+				}{
+					TitleTemplate: "{{.ComponentName}}: {{.Status}}",
+					BodyTemplate: "{{if .Documentation}}{{.Documentation}}{{else}}" +
+						"We are investigating reports of {{.Status}} affecting {{.ComponentName}}.{{end}}",
+					ResolvedBodyTemplate: "{{.ComponentName}} is operational again.",
+				},
+				StatusWriter: struct {
+					IntervalSeconds int `validate:"min=1"`
+				}{IntervalSeconds: 60},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
 				}{
-					ApiKey:  "foo",
-					PageID:  "bar",
-					ApiRoot: "https://api.statuspage.io/v1",
+					BatchSeconds: 60,
+					SubjectTemplate: "{{if eq (len .Changes) 1}}{{with index .Changes 0}}" +
+						"{{.ComponentName}} is now {{.NewStatus}}{{end}}{{else}}{{len .Changes}} status changes{{end}}",
+					TextTemplate: "{{range .Changes}}" +
+						"{{.ComponentName}} is now {{.NewStatus}} (was {{.OldStatus}}) as of {{.At.UTC.Format \"2006-01-02 15:04 MST\"}}\n" +
+						"{{if .PolicyName}}Caused by {{.PolicyName}}{{if .Summary}}: {{.Summary}}{{end}}{{if .URL}} <{{.URL}}>{{end}}\n{{end}}" +
+						"{{range .Incidents}}- {{.Name}} ({{.Status}}){{if .Summary}}: {{.Summary}}{{end}}{{if .URL}} <{{.URL}}>{{end}}\n{{end}}" +
+						"\n{{end}}",
+					HTMLTemplate: "{{range .Changes}}" +
+						"<p><b>{{.ComponentName}}</b> is now <b>{{.NewStatus}}</b> (was {{.OldStatus}}) as of {{.At.UTC.Format \"2006-01-02 15:04 MST\"}}</p>" +
+						"{{if .PolicyName}}<p>Caused by {{if .URL}}<a href=\"{{.URL}}\">{{.PolicyName}}</a>{{else}}{{.PolicyName}}{{end}}" +
+						"{{if .Summary}}: {{.Summary}}{{end}}</p>{{end}}" +
+						"{{if .Incidents}}<ul>{{range .Incidents}}<li>{{if .URL}}<a href=\"{{.URL}}\">{{.Name}}</a>{{else}}{{.Name}}{{end}} " +
+						"({{.Status}}){{if .Summary}}: {{.Summary}}{{end}}</li>{{end}}</ul>{{end}}" +
+						"{{end}}",
 				},
 				Pubsub: struct {
 					ProjectID      string `validate:"required"`
 					SubscriptionID string `validate:"required"`
 				}{ProjectID: "test-project", SubscriptionID: "test-subscription"},
 				Api: struct {
-					Port   int
-					Debug  bool
-					Silent bool
+					Port                        int
+					Debug                       bool
+					Silent                      bool
+					AdminToken                  string
+					CloudMonitoringWebhookToken string
+					AlertmanagerWebhookToken    string
 				}{Port: 8080, Debug: false, Silent: false},
+				Persistence: struct {
+					Backend  string `validate:"oneof=memory file"`
+					FilePath string
+				}{Backend: "memory", FilePath: "revere-state.json"},
+				DeadLetter: struct {
+					MaxAttempts          int    `validate:"min=1"`
+					InitialBackoffMillis int    `validate:"min=0"`
+					Sink                 string `validate:"oneof=none file pubsub"`
+					FilePath             string
+					TopicID              string
+				}{MaxAttempts: 5, InitialBackoffMillis: 1000, Sink: "file", FilePath: "revere-dead-letters.jsonl"},
+				InheritedStatus: struct {
+					Policy      string `validate:"oneof=keep reset wait"`
+					WaitMinutes int    `validate:"min=0"`
+				}{Policy: "keep", WaitMinutes: 30},
 			},
 		},
 	}
@@ -88,26 +171,97 @@ func Test_newDefaultConfig(t *testing.T) {
 			want: &Config{
 				Verbose: false,
 				Client: struct {
-					Redirects int
-					Retries   int
+					Redirects           int
+					Retries             int
+					MaxRetryWaitSeconds int
+					RequestsPerSecond   float64
 				}{
-					Redirects: 3,
-					Retries:   3,
+					Redirects:           3,
+					Retries:             3,
+					MaxRetryWaitSeconds: 60,
+					RequestsPerSecond:   1,
 				},
 				Statuspage: struct {
-					ApiKey     string `validate:"required"`
-					PageID     string `validate:"required"`
-					ApiRoot    string
-					Components []Component      `validate:"unique=Name,dive"`
-					Groups     []ComponentGroup `validate:"unique=Name,dive"`
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					ApiRoot:              "https://api.statuspage.io/v1",
+					DeletionPolicy:       "flag",
+					ManagedResourcesFile: "revere-managed.json",
+				},
+				StatuspageIncidents: struct {
+					Enabled              bool
+					TitleTemplate        string
+					BodyTemplate         string
+					ResolvedBodyTemplate string
+					DeliverNotifications bool
 				}{
-					ApiRoot: "https://api.statuspage.io/v1",
+					TitleTemplate: "{{.ComponentName}}: {{.Status}}",
+					BodyTemplate: "{{if .Documentation}}{{.Documentation}}{{else}}" +
+						"We are investigating reports of {{.Status}} affecting {{.ComponentName}}.{{end}}",
+					ResolvedBodyTemplate: "{{.ComponentName}} is operational again.",
+				},
+				StatusWriter: struct {
+					IntervalSeconds int `validate:"min=1"`
+				}{IntervalSeconds: 60},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
+				}{
+					BatchSeconds: 60,
+					SubjectTemplate: "{{if eq (len .Changes) 1}}{{with index .Changes 0}}" +
+						"{{.ComponentName}} is now {{.NewStatus}}{{end}}{{else}}{{len .Changes}} status changes{{end}}",
+					TextTemplate: "{{range .Changes}}" +
+						"{{.ComponentName}} is now {{.NewStatus}} (was {{.OldStatus}}) as of {{.At.UTC.Format \"2006-01-02 15:04 MST\"}}\n" +
+						"{{if .PolicyName}}Caused by {{.PolicyName}}{{if .Summary}}: {{.Summary}}{{end}}{{if .URL}} <{{.URL}}>{{end}}\n{{end}}" +
+						"{{range .Incidents}}- {{.Name}} ({{.Status}}){{if .Summary}}: {{.Summary}}{{end}}{{if .URL}} <{{.URL}}>{{end}}\n{{end}}" +
+						"\n{{end}}",
+					HTMLTemplate: "{{range .Changes}}" +
+						"<p><b>{{.ComponentName}}</b> is now <b>{{.NewStatus}}</b> (was {{.OldStatus}}) as of {{.At.UTC.Format \"2006-01-02 15:04 MST\"}}</p>" +
+						"{{if .PolicyName}}<p>Caused by {{if .URL}}<a href=\"{{.URL}}\">{{.PolicyName}}</a>{{else}}{{.PolicyName}}{{end}}" +
+						"{{if .Summary}}: {{.Summary}}{{end}}</p>{{end}}" +
+						"{{if .Incidents}}<ul>{{range .Incidents}}<li>{{if .URL}}<a href=\"{{.URL}}\">{{.Name}}</a>{{else}}{{.Name}}{{end}} " +
+						"({{.Status}}){{if .Summary}}: {{.Summary}}{{end}}</li>{{end}}</ul>{{end}}" +
+						"{{end}}",
 				},
 				Api: struct {
-					Port   int
-					Debug  bool
-					Silent bool
+					Port                        int
+					Debug                       bool
+					Silent                      bool
+					AdminToken                  string
+					CloudMonitoringWebhookToken string
+					AlertmanagerWebhookToken    string
 				}{Port: 8080},
+				Persistence: struct {
+					Backend  string `validate:"oneof=memory file"`
+					FilePath string
+				}{Backend: "memory", FilePath: "revere-state.json"},
+				DeadLetter: struct {
+					MaxAttempts          int    `validate:"min=1"`
+					InitialBackoffMillis int    `validate:"min=0"`
+					Sink                 string `validate:"oneof=none file pubsub"`
+					FilePath             string
+					TopicID              string
+				}{MaxAttempts: 5, InitialBackoffMillis: 1000, Sink: "file", FilePath: "revere-dead-letters.jsonl"},
+				InheritedStatus: struct {
+					Policy      string `validate:"oneof=keep reset wait"`
+					WaitMinutes int    `validate:"min=0"`
+				}{Policy: "keep", WaitMinutes: 30},
 			},
 		},
 	}
@@ -141,6 +295,60 @@ func Test_readEnvironmentVariables(t *testing.T) {
 				return config.Statuspage.ApiKey
 			},
 		},
+		{
+			name:   "Reads API admin token",
+			args:   args{config: &Config{}},
+			envVal: "foobar",
+			envKey: "REVERE_API_ADMINTOKEN",
+			configAccess: func(config *Config) string {
+				return config.Api.AdminToken
+			},
+		},
+		{
+			name:   "Reads Cloud Monitoring webhook token",
+			args:   args{config: &Config{}},
+			envVal: "foobar",
+			envKey: "REVERE_API_CLOUDMONITORINGWEBHOOKTOKEN",
+			configAccess: func(config *Config) string {
+				return config.Api.CloudMonitoringWebhookToken
+			},
+		},
+		{
+			name:   "Reads Alertmanager webhook token",
+			args:   args{config: &Config{}},
+			envVal: "foobar",
+			envKey: "REVERE_API_ALERTMANAGERWEBHOOKTOKEN",
+			configAccess: func(config *Config) string {
+				return config.Api.AlertmanagerWebhookToken
+			},
+		},
+		{
+			name:   "Reads managed resources file",
+			args:   args{config: &Config{}},
+			envVal: "/var/lib/revere/managed.json",
+			envKey: "REVERE_STATUSPAGE_MANAGEDRESOURCESFILE",
+			configAccess: func(config *Config) string {
+				return config.Statuspage.ManagedResourcesFile
+			},
+		},
+		{
+			name:   "Reads Slack webhook URL",
+			args:   args{config: &Config{}},
+			envVal: "https://hooks.slack.com/services/foobar",
+			envKey: "REVERE_SLACK_WEBHOOKURL",
+			configAccess: func(config *Config) string {
+				return config.Slack.WebhookURL
+			},
+		},
+		{
+			name:   "Reads email password",
+			args:   args{config: &Config{}},
+			envVal: "foobar",
+			envKey: "REVERE_EMAIL_PASSWORD",
+			configAccess: func(config *Config) string {
+				return config.Email.Password
+			},
+		},
 		{
 			name:   "Reads API port",
 			args:   args{config: &Config{}},
@@ -202,11 +410,14 @@ func Test_secondaryConfigValidation(t *testing.T) {
 			name: "allows correct mappings",
 			args: args{config: &Config{
 				Statuspage: struct {
-					ApiKey     string `validate:"required"`
-					PageID     string `validate:"required"`
-					ApiRoot    string
-					Components []Component      `validate:"unique=Name,dive"`
-					Groups     []ComponentGroup `validate:"unique=Name,dive"`
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
 				}{
 					Components: []Component{
 						{Name: "notebooks"},
@@ -224,11 +435,14 @@ func Test_secondaryConfigValidation(t *testing.T) {
 			name: "rejects bad mappings",
 			args: args{config: &Config{
 				Statuspage: struct {
-					ApiKey     string `validate:"required"`
-					PageID     string `validate:"required"`
-					ApiRoot    string
-					Components []Component      `validate:"unique=Name,dive"`
-					Groups     []ComponentGroup `validate:"unique=Name,dive"`
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
 				}{
 					Components: []Component{
 						{Name: "notebooks"},
@@ -243,6 +457,689 @@ func Test_secondaryConfigValidation(t *testing.T) {
 			}},
 			wantErr: true,
 		},
+		{
+			name: "allows renamed components",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{
+						{Name: "notebooks", PreviousNames: []string{"jupyter"}, ID: "abc"},
+						{Name: "ui", ID: "def"},
+					},
+				},
+			}},
+		},
+		{
+			name: "rejects previous names in use",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{
+						{Name: "notebooks", PreviousNames: []string{"ui"}},
+						{Name: "ui"},
+					},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects duplicate pinned IDs",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{
+						{Name: "notebooks", ID: "abc"},
+						{Name: "ui", ID: "abc"},
+					},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects bad incident templates",
+			args: args{config: &Config{
+				StatuspageIncidents: struct {
+					Enabled              bool
+					TitleTemplate        string
+					BodyTemplate         string
+					ResolvedBodyTemplate string
+					DeliverNotifications bool
+				}{TitleTemplate: "{{.ComponentName"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "allows correct maintenance windows",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}},
+				},
+				MaintenanceWindows: []MaintenanceWindow{
+					{Name: "upgrade", AffectsComponentsNamed: []string{"notebooks"},
+						Start: "2021-07-06T20:00:00-04:00", End: "2021-07-06T22:00:00-04:00"},
+				},
+			}},
+		},
+		{
+			name: "rejects maintenance windows on non-existent components",
+			args: args{config: &Config{
+				MaintenanceWindows: []MaintenanceWindow{
+					{Name: "upgrade", AffectsComponentsNamed: []string{"notebooks"},
+						Start: "2021-07-06T20:00:00-04:00", End: "2021-07-06T22:00:00-04:00"},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects maintenance windows with bad times",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}},
+				},
+				MaintenanceWindows: []MaintenanceWindow{
+					{Name: "upgrade", AffectsComponentsNamed: []string{"notebooks"},
+						Start: "2021-07-06T22:00:00-04:00", End: "2021-07-06T20:00:00-04:00"},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "allows correct escalations",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks", Escalations: []Escalation{
+						{MinIncidents: 3, EscalateTo: "partial-outage"},
+						{MinServices: 2, CountingAtLeast: "partial-outage", EscalateTo: "major-outage"},
+					}}},
+				},
+			}},
+		},
+		{
+			name: "rejects escalations without thresholds",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks", Escalations: []Escalation{
+						{EscalateTo: "major-outage"},
+					}}},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects escalations to bad statuses",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks", Escalations: []Escalation{
+						{MinIncidents: 3, EscalateTo: "under-maintenance"},
+					}}},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "allows correct status translations",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+				},
+				ServiceToComponentMapping: []ServiceToComponentMapping{
+					{ServiceName: "leonardo", AffectsComponentsNamed: []string{"notebooks"}, StatusTranslations: []StatusTranslation{
+						{ComponentName: "notebooks", Translate: map[string]string{"major-outage": "partial-outage"}, AtMost: "partial-outage"},
+					}},
+				},
+			}},
+		},
+		{
+			name: "rejects status translations for unaffected components",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+				},
+				ServiceToComponentMapping: []ServiceToComponentMapping{
+					{ServiceName: "leonardo", AffectsComponentsNamed: []string{"notebooks"}, StatusTranslations: []StatusTranslation{
+						{ComponentName: "ui", AtMost: "partial-outage"},
+					}},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects status translations with bad statuses",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+				},
+				ServiceToComponentMapping: []ServiceToComponentMapping{
+					{ServiceName: "leonardo", AffectsComponentsNamed: []string{"notebooks"}, StatusTranslations: []StatusTranslation{
+						{ComponentName: "notebooks", Translate: map[string]string{"major-outage": "broken"}},
+					}},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "allows correct mapping patterns",
+			args: args{config: &Config{
+				ServiceToComponentMapping: []ServiceToComponentMapping{
+					{ServiceName: "sam", ServiceEnvironment: "*", MatchIncident: []IncidentMatcher{
+						{Attribute: "resource.labels.project_id", Pattern: "broad-dsde-*"},
+					}},
+					{ServiceName: "^sam$", ServiceEnvironment: "^(dev|staging)$", Regex: true},
+				},
+			}},
+		},
+		{
+			name: "rejects bad mapping globs",
+			args: args{config: &Config{
+				ServiceToComponentMapping: []ServiceToComponentMapping{
+					{ServiceName: "sam", ServiceEnvironment: "[dev"},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects bad mapping regular expressions",
+			args: args{config: &Config{
+				ServiceToComponentMapping: []ServiceToComponentMapping{
+					{ServiceName: "sam", ServiceEnvironment: "(dev", Regex: true},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects unknown incident attributes",
+			args: args{config: &Config{
+				ServiceToComponentMapping: []ServiceToComponentMapping{
+					{ServiceName: "sam", ServiceEnvironment: "dev", MatchIncident: []IncidentMatcher{
+						{Attribute: "resource.labels.", Pattern: "*"},
+					}},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects pubsub dead letter sink without a topic",
+			args: args{config: &Config{
+				DeadLetter: struct {
+					MaxAttempts          int    `validate:"min=1"`
+					InitialBackoffMillis int    `validate:"min=0"`
+					Sink                 string `validate:"oneof=none file pubsub"`
+					FilePath             string
+					TopicID              string
+				}{MaxAttempts: 5, Sink: "pubsub"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "allows slack routes",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Slack: struct {
+					Enabled    bool
+					WebhookURL string
+					Routes     []SlackRoute `validate:"dive"`
+				}{Enabled: true, WebhookURL: "https://hooks.slack.com/services/default", Routes: []SlackRoute{
+					{WebhookURL: "https://hooks.slack.com/services/notebooks", ComponentNames: []string{"notebooks"}},
+					{GroupNames: []string{"terra"}},
+				}},
+			}},
+		},
+		{
+			name: "rejects slack without a webhook",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Slack: struct {
+					Enabled    bool
+					WebhookURL string
+					Routes     []SlackRoute `validate:"dive"`
+				}{Enabled: true},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects slack routes without a webhook",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Slack: struct {
+					Enabled    bool
+					WebhookURL string
+					Routes     []SlackRoute `validate:"dive"`
+				}{Enabled: true, Routes: []SlackRoute{{ComponentNames: []string{"notebooks"}}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects slack routes to nothing",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Slack: struct {
+					Enabled    bool
+					WebhookURL string
+					Routes     []SlackRoute `validate:"dive"`
+				}{Enabled: true, WebhookURL: "https://hooks.slack.com/services/default", Routes: []SlackRoute{{}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects slack routes to non-existent components",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Slack: struct {
+					Enabled    bool
+					WebhookURL string
+					Routes     []SlackRoute `validate:"dive"`
+				}{Enabled: true, WebhookURL: "https://hooks.slack.com/services/default",
+					Routes: []SlackRoute{{ComponentNames: []string{"workspaces"}}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects slack routes to non-existent groups",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Slack: struct {
+					Enabled    bool
+					WebhookURL string
+					Routes     []SlackRoute `validate:"dive"`
+				}{Enabled: true, WebhookURL: "https://hooks.slack.com/services/default",
+					Routes: []SlackRoute{{GroupNames: []string{"workspaces"}}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "allows email recipients and routes",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
+				}{Enabled: true, SmtpAddress: "smtp.example.com:587", From: "revere@example.com", Recipients: []string{"oncall@example.com"},
+					Routes:       []EmailRoute{{Recipients: []string{"partners@example.com"}, GroupNames: []string{"terra"}}},
+					HTMLTemplate: "<p>{{len .Changes}} changes</p>"},
+			}},
+		},
+		{
+			name: "rejects email without an SMTP address",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
+				}{Enabled: true, From: "revere@example.com", Recipients: []string{"oncall@example.com"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects email SMTP addresses without a port",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
+				}{Enabled: true, SmtpAddress: "smtp.example.com", From: "revere@example.com", Recipients: []string{"oncall@example.com"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects email without a from address",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
+				}{Enabled: true, SmtpAddress: "smtp.example.com:587", Recipients: []string{"oncall@example.com"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects email without recipients",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
+				}{Enabled: true, SmtpAddress: "smtp.example.com:587", From: "revere@example.com"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects email routes to non-existent groups",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
+				}{Enabled: true, SmtpAddress: "smtp.example.com:587", From: "revere@example.com",
+					Routes: []EmailRoute{{Recipients: []string{"partners@example.com"}, GroupNames: []string{"workspaces"}}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "rejects invalid email templates",
+			args: args{config: &Config{
+				Statuspage: struct {
+					ApiKey               string `validate:"required"`
+					PageID               string `validate:"required"`
+					ApiRoot              string
+					Components           []Component      `validate:"unique=Name,dive"`
+					Groups               []ComponentGroup `validate:"unique=Name,dive"`
+					DeletionPolicy       string           `validate:"oneof=never managed flag"`
+					AllowDelete          bool
+					ManagedResourcesFile string
+				}{
+					Components: []Component{{Name: "notebooks"}, {Name: "ui"}},
+					Groups:     []ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}},
+				},
+				Email: struct {
+					Enabled         bool
+					SmtpAddress     string
+					Username        string
+					Password        string
+					From            string       `validate:"omitempty,email"`
+					Recipients      []string     `validate:"unique,dive,email"`
+					Routes          []EmailRoute `validate:"dive"`
+					BatchSeconds    int          `validate:"min=0"`
+					SubjectTemplate string
+					TextTemplate    string
+					HTMLTemplate    string
+				}{Enabled: true, SmtpAddress: "smtp.example.com:587", From: "revere@example.com", Recipients: []string{"oncall@example.com"}, HTMLTemplate: "{{.Changes"},
+			}},
+			wantErr: true,
+		},
 		{
 			name: "rejects bad mappings where there's no components",
 			args: args{config: &Config{
@@ -261,3 +1158,58 @@ func Test_secondaryConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceToComponentMapping_MatchesPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping ServiceToComponentMapping
+		// If CompilePatterns should be skipped, like for a mapping that was never validated
+		uncompiled bool
+		pattern    string
+		value      string
+		want       bool
+	}{
+		{
+			name:    "Globs match",
+			mapping: ServiceToComponentMapping{ServiceName: "sa?", ServiceEnvironment: "*"},
+			pattern: "sa?",
+			value:   "sam",
+			want:    true,
+		},
+		{
+			name:    "Regular expressions match",
+			mapping: ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "dev|staging", Regex: true},
+			pattern: "dev|staging",
+			value:   "staging",
+			want:    true,
+		},
+		{
+			name:    "Regular expressions are anchored",
+			mapping: ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "dev|staging", Regex: true},
+			pattern: "dev|staging",
+			value:   "staging-2",
+			want:    false,
+		},
+		{
+			name:       "Uncompiled regular expressions don't match",
+			mapping:    ServiceToComponentMapping{ServiceName: "sam", ServiceEnvironment: "dev", Regex: true},
+			uncompiled: true,
+			pattern:    "dev",
+			value:      "dev",
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.uncompiled {
+				if err := tt.mapping.CompilePatterns(); err != nil {
+					t.Errorf("CompilePatterns() error = %v", err)
+					return
+				}
+			}
+			if got := tt.mapping.MatchesPattern(tt.pattern, tt.value); got != tt.want {
+				t.Errorf("MatchesPattern() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package deadletter
+
+import (
+	"github.com/broadinstitute/revere/internal/events"
+	"sync"
+	"time"
+)
+
+// keptLetters is how many of the most recent Letters a Queue keeps to list
+const keptLetters = 100
+
+// Letter records an alert that couldn't be handled for a component, even after retrying
+type Letter struct {
+	ComponentName string             `json:"componentName"`
+	Event         *events.AlertEvent `json:"event"`
+	Attempts      int                `json:"attempts"`
+	Error         string             `json:"error"`
+	FailedAt      time.Time          `json:"failedAt"`
+}
+
+// Queue writes Letters to a Sink and keeps the most recent ones so they can be listed by the API.
+// It's safe for concurrent use.
+type Queue struct {
+	sink    Sink
+	letters []Letter
+	lock    *sync.Mutex
+}
+
+func NewQueue(sink Sink) *Queue {
+	return &Queue{
+		sink: sink,
+		lock: &sync.Mutex{},
+	}
+}
+
+// Add writes the letter to the Queue's Sink, keeping it to be listed even if that fails
+func (q *Queue) Add(letter Letter) error {
+	q.lock.Lock()
+	q.letters = append(q.letters, letter)
+	if len(q.letters) > keptLetters {
+		q.letters = q.letters[len(q.letters)-keptLetters:]
+	}
+	q.lock.Unlock()
+	return q.sink.Write(letter)
+}
+
+// List returns the most recently added letters, oldest first. A nil Queue has none.
+func (q *Queue) List() []Letter {
+	if q == nil {
+		return []Letter{}
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	letters := make([]Letter, len(q.letters))
+	copy(letters, q.letters)
+	return letters
+}
@@ -0,0 +1,25 @@
+package deadletter
+
+import (
+	"testing"
+)
+
+func TestQueue_List(t *testing.T) {
+	var nilQueue *Queue
+	if got := nilQueue.List(); len(got) != 0 {
+		t.Errorf("nil Queue List() = %v, want empty", got)
+	}
+	queue := NewQueue(NoneSink{})
+	for i := 0; i < keptLetters+5; i++ {
+		if err := queue.Add(Letter{Attempts: i}); err != nil {
+			t.Errorf("Add() error = %v", err)
+		}
+	}
+	got := queue.List()
+	if len(got) != keptLetters {
+		t.Errorf("List() returned %d letters, want %d", len(got), keptLetters)
+	}
+	if got[0].Attempts != 5 || got[len(got)-1].Attempts != keptLetters+4 {
+		t.Errorf("List() kept letters %d through %d, want 5 through %d", got[0].Attempts, got[len(got)-1].Attempts, keptLetters+4)
+	}
+}
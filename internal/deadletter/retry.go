@@ -0,0 +1,88 @@
+package deadletter
+
+import (
+	"context"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/metrics"
+	"github.com/broadinstitute/revere/internal/pubsub/pubsubtypes"
+	"github.com/broadinstitute/revere/internal/shared"
+	"time"
+)
+
+// Retrying wraps the handler so that failures are retried with exponential backoff, per the configuration's
+// DeadLetter settings. Once attempts run out, or ctx is done while waiting to retry, the alert is added to the
+// queue and the failure is swallowed, so that the alert is acknowledged instead of redelivered. Only a failure to
+// add to the queue is returned.
+func Retrying(ctx context.Context, config *configuration.Config, queue *Queue, handler pubsubtypes.PerComponentHandler) pubsubtypes.PerComponentHandler {
+	return func(componentName string, event *events.AlertEvent) error {
+		backoff := time.Duration(config.DeadLetter.InitialBackoffMillis) * time.Millisecond
+		var err error
+		attempts := 0
+		for attempts < config.DeadLetter.MaxAttempts {
+			if attempts > 0 {
+				shared.LogLn(config, fmt.Sprintf("retrying alert %s for %s in %s after: %v",
+					event.IncidentID, componentName, backoff, err))
+				if !wait(ctx, backoff) {
+					break
+				}
+				backoff *= 2
+			}
+			attempts++
+			if err = handler(componentName, event); err == nil {
+				return nil
+			}
+		}
+		return deadLetter(config, queue, componentName, event, attempts, err)
+	}
+}
+
+// DeadLettering wraps the handler so that a failure is added to the queue right away and then returned, without
+// retrying. It's for callers like webhooks whose senders are waiting on the result and retry on their own.
+func DeadLettering(config *configuration.Config, queue *Queue, handler pubsubtypes.PerComponentHandler) pubsubtypes.PerComponentHandler {
+	return func(componentName string, event *events.AlertEvent) error {
+		err := handler(componentName, event)
+		if err == nil {
+			return nil
+		}
+		if queueErr := deadLetter(config, queue, componentName, event, 1, err); queueErr != nil {
+			return queueErr
+		}
+		return err
+	}
+}
+
+// wait sleeps for the duration, returning false if ctx is done first
+func wait(ctx context.Context, duration time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// deadLetter adds the alert to the queue after its last failure, returning only a failure to do so
+func deadLetter(config *configuration.Config, queue *Queue, componentName string, event *events.AlertEvent,
+	attempts int, err error) error {
+	shared.LogLn(config, fmt.Sprintf("giving up on alert %s for %s after %d attempts: %v",
+		event.IncidentID, componentName, attempts, err))
+	metrics.AlertsDeadLettered.Inc()
+	letter := Letter{
+		ComponentName: componentName,
+		Event:         event,
+		Attempts:      attempts,
+		Error:         err.Error(),
+		FailedAt:      time.Now().UTC(),
+	}
+	if queueErr := queue.Add(letter); queueErr != nil {
+		return fmt.Errorf("failed to dead-letter alert %s for %s (%v): %w", event.IncidentID, componentName, err, queueErr)
+	}
+	return nil
+}
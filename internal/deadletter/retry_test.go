@@ -0,0 +1,154 @@
+package deadletter
+
+import (
+	"context"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"testing"
+)
+
+// failingSink fails every write
+type failingSink struct{}
+
+func (failingSink) Write(Letter) error {
+	return fmt.Errorf("some sink error")
+}
+
+func TestRetrying(t *testing.T) {
+	config := &configuration.Config{}
+	config.DeadLetter.MaxAttempts = 3
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name     string
+		failures int
+		sink     Sink
+		// Context that waiting to retry is bounded by, if not the background
+		ctx          context.Context
+		wantAttempts int
+		wantLetters  int
+		wantErr      bool
+	}{
+		{
+			name:         "succeeds first time",
+			failures:     0,
+			sink:         NoneSink{},
+			wantAttempts: 1,
+		},
+		{
+			name:         "succeeds after retrying",
+			failures:     2,
+			sink:         NoneSink{},
+			wantAttempts: 3,
+		},
+		{
+			name:         "dead-letters after too many failures",
+			failures:     5,
+			sink:         NoneSink{},
+			wantAttempts: 3,
+			wantLetters:  1,
+		},
+		{
+			name:         "errors if dead-lettering fails",
+			failures:     5,
+			sink:         failingSink{},
+			wantAttempts: 3,
+			wantLetters:  1,
+			wantErr:      true,
+		},
+		{
+			name:         "dead-letters without waiting to retry once cancelled",
+			failures:     5,
+			sink:         NoneSink{},
+			ctx:          cancelled,
+			wantAttempts: 1,
+			wantLetters:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			queue := NewQueue(tt.sink)
+			attempts := 0
+			handler := Retrying(ctx, config, queue, func(string, *events.AlertEvent) error {
+				attempts++
+				if attempts <= tt.failures {
+					return fmt.Errorf("some error")
+				}
+				return nil
+			})
+			err := handler("notebooks", &events.AlertEvent{IncidentID: "abc"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Retrying() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Retrying() made %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+			letters := queue.List()
+			if len(letters) != tt.wantLetters {
+				t.Errorf("Retrying() dead-lettered %d alerts, want %d", len(letters), tt.wantLetters)
+			}
+			for _, letter := range letters {
+				if letter.ComponentName != "notebooks" || letter.Event.IncidentID != "abc" || letter.Attempts != tt.wantAttempts {
+					t.Errorf("Retrying() dead-lettered %+v", letter)
+				}
+			}
+		})
+	}
+}
+
+func TestDeadLettering(t *testing.T) {
+	config := &configuration.Config{}
+	config.DeadLetter.MaxAttempts = 3
+	config.DeadLetter.InitialBackoffMillis = 60 * 1000
+	tests := []struct {
+		name        string
+		handlerErr  error
+		sink        Sink
+		wantLetters int
+		wantErr     bool
+	}{
+		{
+			name: "passes success through",
+			sink: NoneSink{},
+		},
+		{
+			name:        "dead-letters and returns a failure without retrying",
+			handlerErr:  fmt.Errorf("some error"),
+			sink:        NoneSink{},
+			wantLetters: 1,
+			wantErr:     true,
+		},
+		{
+			name:        "errors if dead-lettering fails",
+			handlerErr:  fmt.Errorf("some error"),
+			sink:        failingSink{},
+			wantLetters: 1,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := NewQueue(tt.sink)
+			attempts := 0
+			handler := DeadLettering(config, queue, func(string, *events.AlertEvent) error {
+				attempts++
+				return tt.handlerErr
+			})
+			err := handler("notebooks", &events.AlertEvent{IncidentID: "abc"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeadLettering() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != 1 {
+				t.Errorf("DeadLettering() made %d attempts, want 1", attempts)
+			}
+			if letters := queue.List(); len(letters) != tt.wantLetters {
+				t.Errorf("DeadLettering() dead-lettered %d alerts, want %d", len(letters), tt.wantLetters)
+			}
+		})
+	}
+}
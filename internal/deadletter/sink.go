@@ -0,0 +1,97 @@
+package deadletter
+
+import (
+	"cloud.google.com/go/pubsub"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"os"
+	"sync"
+)
+
+// Sink durably records Letters somewhere outside of Revere.
+// Implementations must be safe for concurrent use, since different components may fail simultaneously.
+type Sink interface {
+	Write(letter Letter) error
+}
+
+// NewSink creates the Sink described by the configuration's DeadLetter.Sink. The Pub/Sub client is only
+// used by the "pubsub" sink.
+func NewSink(config *configuration.Config, pubsubClient *pubsub.Client) (Sink, error) {
+	switch config.DeadLetter.Sink {
+	case "none", "":
+		return NoneSink{}, nil
+	case "file":
+		return NewFileSink(config.DeadLetter.FilePath), nil
+	case "pubsub":
+		return NewPubsubSink(pubsubClient.Topic(config.DeadLetter.TopicID)), nil
+	}
+	return nil, fmt.Errorf("unknown dead letter sink %s", config.DeadLetter.Sink)
+}
+
+// NoneSink doesn't record anything, but it does fulfill the Sink interface so that Queue doesn't need to
+// special-case having nowhere to write.
+type NoneSink struct{}
+
+// Write is a part of Sink, doing nothing
+func (NoneSink) Write(Letter) error {
+	return nil
+}
+
+// FileSink appends each Letter to a file on the local disk as a line of JSON.
+type FileSink struct {
+	path string
+	lock *sync.Mutex
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{
+		path: path,
+		lock: &sync.Mutex{},
+	}
+}
+
+// Write is a part of Sink, appending the letter to the file
+func (f *FileSink) Write(letter Letter) error {
+	contents, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to serialize dead letter: %w", err)
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file %s: %w", f.path, err)
+	}
+	if _, err := file.Write(append(contents, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write dead letter file %s: %w", f.path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close dead letter file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// PubsubSink publishes each Letter to a Pub/Sub topic as JSON.
+type PubsubSink struct {
+	topic *pubsub.Topic
+}
+
+func NewPubsubSink(topic *pubsub.Topic) *PubsubSink {
+	return &PubsubSink{topic: topic}
+}
+
+// Write is a part of Sink, publishing the letter and waiting for Pub/Sub to accept it
+func (p *PubsubSink) Write(letter Letter) error {
+	contents, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to serialize dead letter: %w", err)
+	}
+	ctx := context.Background()
+	if _, err := p.topic.Publish(ctx, &pubsub.Message{Data: contents}).Get(ctx); err != nil {
+		return fmt.Errorf("failed to publish dead letter to %s: %w", p.topic.ID(), err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"github.com/broadinstitute/revere/internal/events"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSink_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	sink := NewFileSink(path)
+	for _, incidentID := range []string{"abc", "def"} {
+		letter := Letter{ComponentName: "notebooks", Event: &events.AlertEvent{IncidentID: incidentID}}
+		if err := sink.Write(letter); err != nil {
+			t.Errorf("Write() error = %v", err)
+		}
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read dead letter file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("dead letter file has %d lines, want 2", len(lines))
+	}
+	var letter Letter
+	if err := json.Unmarshal([]byte(lines[1]), &letter); err != nil {
+		t.Errorf("failed to parse dead letter: %v", err)
+	}
+	if letter.ComponentName != "notebooks" || letter.Event.IncidentID != "def" {
+		t.Errorf("dead letter = %+v, want notebooks def", letter)
+	}
+}
@@ -0,0 +1,69 @@
+package email
+
+import (
+	"github.com/broadinstitute/revere/internal/state"
+	"time"
+)
+
+// BatchData is what the configuration's Email templates are executed with
+type BatchData struct {
+	// Changes are those in the email, oldest first
+	Changes []ChangeData
+}
+
+// ChangeData describes one change to a component's status. Alert fields describe the alert that caused it,
+// if known.
+type ChangeData struct {
+	ComponentName string
+	// Human-readable statuses, like "Major Outage"
+	OldStatus  string
+	NewStatus  string
+	At         time.Time
+	PolicyName string
+	Summary    string
+	URL        string
+	// Incidents are the component's open incidents after the change
+	Incidents []IncidentData
+}
+
+// IncidentData describes one of a component's open incidents
+type IncidentData struct {
+	// Name of the alert behind the incident, or its ID if the name isn't known
+	Name    string
+	Status  string
+	Service string
+	Summary string
+	URL     string
+}
+
+func newBatchData(transitions []state.StatusTransition) BatchData {
+	var data BatchData
+	for _, transition := range transitions {
+		change := ChangeData{
+			ComponentName: transition.ComponentName,
+			OldStatus:     transition.OldStatus.ToString(),
+			NewStatus:     transition.NewStatus.ToString(),
+			At:            transition.At,
+		}
+		if cause := transition.Cause; cause != nil {
+			change.PolicyName = cause.Name
+			change.Summary = cause.Summary
+			change.URL = cause.URL()
+		}
+		for _, incident := range transition.Incidents {
+			name := incident.Name
+			if name == "" {
+				name = incident.ID
+			}
+			change.Incidents = append(change.Incidents, IncidentData{
+				Name:    name,
+				Status:  incident.Status.ToString(),
+				Service: incident.Service,
+				Summary: incident.Summary,
+				URL:     incident.URL,
+			})
+		}
+		data.Changes = append(data.Changes, change)
+	}
+	return data
+}
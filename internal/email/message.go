@@ -0,0 +1,107 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templates are the configuration's Email templates, parsed
+type templates struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+func parseTemplates(subject string, text string, html string) (*templates, error) {
+	parsedSubject, err := template.New("subject").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email subject template: %w", err)
+	}
+	parsedText, err := template.New("text").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email text template: %w", err)
+	}
+	parsedHTML, err := htmltemplate.New("html").Parse(html)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email html template: %w", err)
+	}
+	return &templates{subject: parsedSubject, text: parsedText, html: parsedHTML}, nil
+}
+
+// executor is what text/template and html/template templates have in common
+type executor interface {
+	Execute(writer io.Writer, data interface{}) error
+}
+
+func execute(name string, parsed executor, data BatchData) (string, error) {
+	var buffer bytes.Buffer
+	if err := parsed.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("failed to execute email %s template: %w", name, err)
+	}
+	return buffer.String(), nil
+}
+
+// composeMessage renders the templates into a MIME email with both plain-text and HTML bodies, so that mail
+// clients show whichever they prefer
+func composeMessage(parsed *templates, from string, to string, date time.Time, data BatchData) ([]byte, error) {
+	subject, err := execute("subject", parsed.subject, data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := execute("text", parsed.text, data)
+	if err != nil {
+		return nil, err
+	}
+	html, err := execute("html", parsed.html, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	// Headers can't span lines, so the subject's whitespace is collapsed
+	subject = strings.Join(strings.Fields(subject), " ")
+	for _, header := range [][2]string{
+		{"From", from},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": writer.Boundary()})},
+	} {
+		buffer.WriteString(fmt.Sprintf("%s: %s\r\n", header[0], header[1]))
+	}
+	buffer.WriteString("\r\n")
+	for _, body := range [][2]string{
+		{"text/plain", text},
+		{"text/html", html},
+	} {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(body[0], map[string]string{"charset": "utf-8"})},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create email %s part: %w", body[0], err)
+		}
+		encoder := quotedprintable.NewWriter(part)
+		if _, err := encoder.Write([]byte(body[1])); err != nil {
+			return nil, fmt.Errorf("failed to write email %s part: %w", body[0], err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write email %s part: %w", body[0], err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish email: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,83 @@
+package email
+
+import (
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func Test_composeMessage(t *testing.T) {
+	transition := transitionTo("notebooks", statuspagetypes.PartialOutage)
+	transition.Cause = &events.AlertEvent{
+		IncidentID: "0.abc",
+		Name:       "Leonardo 5xx",
+		Summary:    "Error rate > 5%",
+		Links:      []events.Link{{Name: "Incident", URL: "https://console.cloud.google.com/monitoring/alerting/incidents/0.abc"}},
+	}
+	transition.Incidents = []state.OpenIncident{
+		{
+			ID:      "0.abc",
+			Status:  statuspagetypes.PartialOutage,
+			Name:    "Leonardo 5xx",
+			Summary: "Error rate > 5%",
+			URL:     "https://console.cloud.google.com/monitoring/alerting/incidents/0.abc",
+		},
+		{ID: "0.def", Status: statuspagetypes.DegradedPerformance},
+	}
+	output, sender := newTestOutput(t, testConfig(t, []string{"oncall@example.com"}), 0)
+	if err := output.Notify(transition); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	want := []sentEmail{{
+		To:      []string{"oncall@example.com"},
+		Subject: "notebooks is now Partial Outage",
+		Text: "notebooks is now Partial Outage (was Operational) as of 2021-07-06 20:00 UTC\n" +
+			"Caused by Leonardo 5xx: Error rate > 5% <https://console.cloud.google.com/monitoring/alerting/incidents/0.abc>\n" +
+			"- Leonardo 5xx (Partial Outage): Error rate > 5% <https://console.cloud.google.com/monitoring/alerting/incidents/0.abc>\n" +
+			"- 0.def (Degraded Performance)\n\n",
+		HTML: "<p><b>notebooks</b> is now <b>Partial Outage</b> (was Operational) as of 2021-07-06 20:00 UTC</p>" +
+			"<p>Caused by <a href=\"https://console.cloud.google.com/monitoring/alerting/incidents/0.abc\">Leonardo 5xx</a>: " +
+			"Error rate &gt; 5%</p>" +
+			"<ul><li><a href=\"https://console.cloud.google.com/monitoring/alerting/incidents/0.abc\">Leonardo 5xx</a> " +
+			"(Partial Outage): Error rate &gt; 5%</li><li>0.def (Degraded Performance)</li></ul>",
+	}}
+	if diff := cmp.Diff(want, sender.getSent()); diff != "" {
+		t.Errorf("Notify() sent unexpected email (-want +got):\n%s", diff)
+	}
+}
+
+func Test_parseTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		text    string
+		html    string
+		wantErr bool
+	}{
+		{
+			name:    "parses valid templates",
+			subject: "{{len .Changes}} changes",
+			text:    "{{range .Changes}}{{.ComponentName}}{{end}}",
+			html:    "<p>{{range .Changes}}{{.ComponentName}}{{end}}</p>",
+		},
+		{
+			name:    "rejects invalid subject",
+			subject: "{{len .Changes",
+			wantErr: true,
+		},
+		{
+			name:    "rejects invalid html",
+			html:    "{{range .Changes}}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTemplates(tt.subject, tt.text, tt.html); (err != nil) != tt.wantErr {
+				t.Errorf("parseTemplates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/metrics"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"net"
+	"net/smtp"
+	"sync"
+	"time"
+)
+
+// sendFunc sends an email like smtp.SendMail
+type sendFunc func(address string, auth smtp.Auth, from string, to []string, message []byte) error
+
+// Output emails changes to components' statuses to the recipients routed to them. Changes within
+// Email.BatchSeconds of each other are batched into one email to each recipient, sent by Run. It's email's
+// outputs.Output.
+type Output struct {
+	config    *configuration.Config
+	templates *templates
+	// window is how long changes are batched for, and send is smtp.SendMail; both are replaced in tests
+	window time.Duration
+	send   sendFunc
+	// pending are changes waiting to be sent, and ready is signalled when its window has passed
+	pending []state.StatusTransition
+	lock    sync.Mutex
+	ready   chan struct{}
+}
+
+func NewOutput(config *configuration.Config) (*Output, error) {
+	parsed, err := parseTemplates(config.Email.SubjectTemplate, config.Email.TextTemplate, config.Email.HTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &Output{
+		config:    config,
+		templates: parsed,
+		window:    time.Duration(config.Email.BatchSeconds) * time.Second,
+		send:      smtp.SendMail,
+		ready:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Name identifies the Output among Revere's outputs
+func (o *Output) Name() string {
+	return "email"
+}
+
+// Notify emails the transition to everyone routed to its component. If changes are batched, it's only queued
+// and any error sending it is logged later by Run.
+func (o *Output) Notify(transition state.StatusTransition) error {
+	if len(recipientsFor(o.config, transition.ComponentName)) == 0 {
+		return nil
+	}
+	if o.window == 0 {
+		return o.sendBatch([]state.StatusTransition{transition})
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.pending = append(o.pending, transition)
+	if len(o.pending) == 1 {
+		time.AfterFunc(o.window, func() {
+			select {
+			case o.ready <- struct{}{}:
+			default:
+			}
+		})
+	}
+	return nil
+}
+
+// Run sends each batch of changes once its window has passed, until the context is cancelled, upon which any
+// changes still pending are sent right away; Run returns once they have been. Failures are logged.
+func (o *Output) Run(ctx context.Context) {
+	for {
+		select {
+		case <-o.ready:
+			o.flush()
+		case <-ctx.Done():
+			o.flush()
+			return
+		}
+	}
+}
+
+func (o *Output) flush() {
+	o.lock.Lock()
+	transitions := o.pending
+	o.pending = nil
+	o.lock.Unlock()
+	if len(transitions) == 0 {
+		return
+	}
+	if err := o.sendBatch(transitions); err != nil {
+		shared.LogLn(o.config, fmt.Sprintf("email output failed to send %d changes: %v", len(transitions), err))
+		metrics.OutputNotificationsFailed.WithLabelValues(o.Name()).Add(float64(len(transitions)))
+	}
+}
+
+// sendBatch sends one email to each recipient, with the changes routed to them. Every recipient is tried even
+// if sending to some fails, and the first failure is returned.
+func (o *Output) sendBatch(transitions []state.StatusTransition) error {
+	var recipients []string
+	transitionsByRecipient := make(map[string][]state.StatusTransition)
+	for _, transition := range transitions {
+		for _, recipient := range recipientsFor(o.config, transition.ComponentName) {
+			if _, present := transitionsByRecipient[recipient]; !present {
+				recipients = append(recipients, recipient)
+			}
+			transitionsByRecipient[recipient] = append(transitionsByRecipient[recipient], transition)
+		}
+	}
+	var auth smtp.Auth
+	if o.config.Email.Username != "" {
+		host, _, err := net.SplitHostPort(o.config.Email.SmtpAddress)
+		if err != nil {
+			return fmt.Errorf("email SMTP address invalid: %w", err)
+		}
+		auth = smtp.PlainAuth("", o.config.Email.Username, o.config.Email.Password, host)
+	}
+	var firstErr error
+	for _, recipient := range recipients {
+		message, err := composeMessage(o.templates, o.config.Email.From, recipient, time.Now(),
+			newBatchData(transitionsByRecipient[recipient]))
+		if err == nil {
+			err = o.send(o.config.Email.SmtpAddress, auth, o.config.Email.From, []string{recipient}, message)
+			if err != nil {
+				err = fmt.Errorf("failed to email %s: %w", recipient, err)
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// recipientsFor returns the recipients of every route matching the component, either directly or via one of
+// its groups, without duplicates. Email.Recipients are used for components no route matches.
+func recipientsFor(config *configuration.Config, componentName string) []string {
+	groupsOfComponent := make(map[string]struct{})
+	for _, group := range config.Statuspage.Groups {
+		for _, name := range group.ComponentNames {
+			if name == componentName {
+				groupsOfComponent[group.Name] = struct{}{}
+			}
+		}
+	}
+	var recipients []string
+	seen := make(map[string]struct{})
+	matched := false
+	for _, route := range config.Email.Routes {
+		if !routeMatches(route, componentName, groupsOfComponent) {
+			continue
+		}
+		matched = true
+		for _, recipient := range route.Recipients {
+			if _, present := seen[recipient]; !present {
+				seen[recipient] = struct{}{}
+				recipients = append(recipients, recipient)
+			}
+		}
+	}
+	if !matched {
+		return config.Email.Recipients
+	}
+	return recipients
+}
+
+func routeMatches(route configuration.EmailRoute, componentName string, groupsOfComponent map[string]struct{}) bool {
+	for _, name := range route.ComponentNames {
+		if name == componentName {
+			return true
+		}
+	}
+	for _, name := range route.GroupNames {
+		if _, present := groupsOfComponent[name]; present {
+			return true
+		}
+	}
+	return false
+}
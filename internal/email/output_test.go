@@ -0,0 +1,251 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/viper"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sentEmail is an email as received by the recordingSender, decoded
+type sentEmail struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// recordingSender stands in for an SMTP server, recording what's sent and failing to send to addresses
+// starting with "fail"
+type recordingSender struct {
+	sent []sentEmail
+	lock sync.Mutex
+}
+
+func (s *recordingSender) send(_ string, _ smtp.Auth, _ string, to []string, message []byte) error {
+	parsed, err := mail.ReadMessage(strings.NewReader(string(message)))
+	if err != nil {
+		return err
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		return err
+	}
+	email := sentEmail{To: to, Subject: subject}
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		// Quoted-printable bodies have CRLF line endings
+		text := strings.ReplaceAll(string(body), "\r\n", "\n")
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+			email.HTML = text
+		} else {
+			email.Text = text
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sent = append(s.sent, email)
+	if strings.HasPrefix(to[0], "fail") {
+		return fmt.Errorf("mailbox unavailable")
+	}
+	return nil
+}
+
+func (s *recordingSender) getSent() []sentEmail {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]sentEmail{}, s.sent...)
+}
+
+// testConfig assembles a configuration with the default email templates
+func testConfig(t *testing.T, recipients []string, routes ...configuration.EmailRoute) *configuration.Config {
+	v := viper.New()
+	v.Set("Statuspage.ApiKey", "foo")
+	v.Set("Statuspage.PageID", "bar")
+	v.Set("Pubsub.ProjectID", "test-project")
+	v.Set("Pubsub.SubscriptionID", "test-subscription")
+	config, err := configuration.AssembleConfig(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Statuspage.Components = []configuration.Component{{Name: "notebooks"}, {Name: "ui"}, {Name: "workspaces"}}
+	config.Statuspage.Groups = []configuration.ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}}
+	config.Email.Enabled = true
+	config.Email.SmtpAddress = "smtp.example.com:587"
+	config.Email.From = "revere@example.com"
+	config.Email.Recipients = recipients
+	config.Email.Routes = routes
+	return config
+}
+
+func newTestOutput(t *testing.T, config *configuration.Config, window time.Duration) (*Output, *recordingSender) {
+	output, err := NewOutput(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := &recordingSender{}
+	output.send = sender.send
+	output.window = window
+	return output, sender
+}
+
+func transitionTo(componentName string, status statuspagetypes.Status) state.StatusTransition {
+	return state.StatusTransition{
+		ComponentName: componentName,
+		OldStatus:     statuspagetypes.Operational,
+		NewStatus:     status,
+		At:            time.Date(2021, 7, 6, 20, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestOutput_Notify(t *testing.T) {
+	tests := []struct {
+		name          string
+		recipients    []string
+		routes        []configuration.EmailRoute
+		componentName string
+		wantTo        [][]string
+		wantErr       bool
+	}{
+		{
+			name:          "emails the default recipients without routes",
+			recipients:    []string{"oncall@example.com", "partners@example.com"},
+			componentName: "notebooks",
+			wantTo:        [][]string{{"oncall@example.com"}, {"partners@example.com"}},
+		},
+		{
+			name:       "emails routes by component",
+			recipients: []string{"oncall@example.com"},
+			routes: []configuration.EmailRoute{
+				{Recipients: []string{"notebooks@example.com"}, ComponentNames: []string{"notebooks"}},
+				{Recipients: []string{"ui@example.com"}, ComponentNames: []string{"ui"}},
+			},
+			componentName: "notebooks",
+			wantTo:        [][]string{{"notebooks@example.com"}},
+		},
+		{
+			name: "emails every recipient of routes by group once",
+			routes: []configuration.EmailRoute{
+				{Recipients: []string{"terra@example.com"}, GroupNames: []string{"terra"}},
+				{Recipients: []string{"terra@example.com", "ui@example.com"}, ComponentNames: []string{"ui"}},
+			},
+			componentName: "ui",
+			wantTo:        [][]string{{"terra@example.com"}, {"ui@example.com"}},
+		},
+		{
+			name:       "falls back to the default recipients when no route matches",
+			recipients: []string{"oncall@example.com"},
+			routes: []configuration.EmailRoute{
+				{Recipients: []string{"terra@example.com"}, GroupNames: []string{"terra"}},
+			},
+			componentName: "workspaces",
+			wantTo:        [][]string{{"oncall@example.com"}},
+		},
+		{
+			name: "emails nobody without default recipients when no route matches",
+			routes: []configuration.EmailRoute{
+				{Recipients: []string{"terra@example.com"}, GroupNames: []string{"terra"}},
+			},
+			componentName: "workspaces",
+		},
+		{
+			name:          "tries every recipient but errors if one fails",
+			recipients:    []string{"fail@example.com", "oncall@example.com"},
+			componentName: "notebooks",
+			wantTo:        [][]string{{"fail@example.com"}, {"oncall@example.com"}},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, sender := newTestOutput(t, testConfig(t, tt.recipients, tt.routes...), 0)
+			err := output.Notify(transitionTo(tt.componentName, statuspagetypes.MajorOutage))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var gotTo [][]string
+			for _, email := range sender.getSent() {
+				gotTo = append(gotTo, email.To)
+			}
+			if diff := cmp.Diff(tt.wantTo, gotTo); diff != "" {
+				t.Errorf("Notify() emailed unexpected recipients (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOutput_Run_batches(t *testing.T) {
+	config := testConfig(t, []string{"oncall@example.com"}, configuration.EmailRoute{
+		Recipients: []string{"oncall@example.com", "partners@example.com"}, ComponentNames: []string{"ui"},
+	})
+	output, sender := newTestOutput(t, config, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go output.Run(ctx)
+
+	for _, transition := range []state.StatusTransition{
+		transitionTo("notebooks", statuspagetypes.PartialOutage),
+		transitionTo("ui", statuspagetypes.MajorOutage),
+	} {
+		if err := output.Notify(transition); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sender.getSent()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Run() sent %d emails, want 2", len(sender.getSent()))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var got []string
+	for _, email := range sender.getSent() {
+		got = append(got, fmt.Sprintf("%s: %s", email.To[0], email.Subject))
+	}
+	want := []string{"oncall@example.com: 2 status changes", "partners@example.com: ui is now Major Outage"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run() sent unexpected emails (-want +got):\n%s", diff)
+	}
+}
+
+func TestOutput_Run_flushesWhenCancelled(t *testing.T) {
+	output, sender := newTestOutput(t, testConfig(t, []string{"oncall@example.com"}), time.Hour)
+	if err := output.Notify(transitionTo("notebooks", statuspagetypes.MajorOutage)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(sender.getSent()) != 0 {
+		t.Errorf("Notify() sent before the batch window passed")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	output.Run(ctx)
+	if len(sender.getSent()) != 1 {
+		t.Errorf("Run() sent %d emails upon cancellation, want 1", len(sender.getSent()))
+	}
+}
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"time"
+)
+
+// AlertEvent is a single alert about a service, in a form that doesn't depend on where it came from.
+// Each input translates what it receives into AlertEvents, and everything downstream (mapping the service
+// to components, updating their state, publishing to Statuspage) only deals with AlertEvents.
+type AlertEvent struct {
+	// Source is how the alert arrived, like "pubsub" or "alertmanager"
+	Source string `json:"source"`
+	// IncidentID identifies the alert across notifications, so that the one closing it matches the one opening it
+	IncidentID string `json:"incidentId"`
+	// Name of the alert, or of the policy that raised it
+	Name               string                 `json:"name"`
+	ServiceName        string                 `json:"serviceName"`
+	ServiceEnvironment string                 `json:"serviceEnvironment"`
+	Status             statuspagetypes.Status `json:"status"`
+	// Ended is if the alert has closed, so that it no longer affects anything
+	Ended bool `json:"ended"`
+	// StartedAt and EndedAt are zero if the source didn't say
+	StartedAt     time.Time `json:"startedAt"`
+	EndedAt       time.Time `json:"endedAt"`
+	Summary       string    `json:"summary"`
+	Documentation string    `json:"documentation"`
+	Links         []Link    `json:"links"`
+	// Attributes are any other details of the alert that service mappings can match on, keyed like
+	// configuration.IncidentMatcher's Attribute, like "resource.labels.project_id"
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Link is somewhere with more information about an alert
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// URL returns the URL of the alert's first link, or an empty string if it has none
+func (e *AlertEvent) URL() string {
+	if len(e.Links) == 0 {
+		return ""
+	}
+	return e.Links[0].URL
+}
+
+// Attribute returns one of the alert's Attributes by name, and if the alert has it
+func (e *AlertEvent) Attribute(name string) (string, bool) {
+	value, present := e.Attributes[name]
+	return value, present
+}
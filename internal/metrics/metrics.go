@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"net/url"
+	"strings"
+)
+
+// Metrics are registered with Prometheus' default registry, served by the API at /metrics
+var (
+	// AlertsReceived counts alerts by how they arrived ("pubsub", "webhook", "alertmanager"), including
+	// ones that are later ignored
+	AlertsReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "revere_alerts_received_total",
+		Help: "Alerts received, by source",
+	}, []string{"source"})
+	// AlertsIgnored counts alerts that didn't affect any component, by source and reason
+	// ("parse_failure", "no_incident", "label_failure", "no_mapping")
+	AlertsIgnored = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "revere_alerts_ignored_total",
+		Help: "Alerts ignored, by source and reason",
+	}, []string{"source", "reason"})
+	// PubsubMessagesAcked counts Pub/Sub messages acknowledged after being handled (or ignored)
+	PubsubMessagesAcked = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "revere_pubsub_messages_acked_total",
+		Help: "Pub/Sub messages acknowledged",
+	})
+	// AlertsDeadLettered counts alerts that couldn't be handled for a component even after retrying
+	AlertsDeadLettered = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "revere_alerts_dead_lettered_total",
+		Help: "Alerts given up on after retrying",
+	})
+	// OutputNotificationsFailed counts status transitions that an output failed to communicate, by output
+	OutputNotificationsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "revere_output_notifications_failed_total",
+		Help: "Status transitions an output failed to communicate, by output",
+	}, []string{"output"})
+	// OutputNotificationsDropped counts status transitions never given to an output because it fell too far behind
+	OutputNotificationsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "revere_output_notifications_dropped_total",
+		Help: "Status transitions dropped because an output fell behind, by output",
+	}, []string{"output"})
+	statuspageRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "revere_statuspage_requests_total",
+		Help: "Requests made to Statuspage, by endpoint and HTTP status code",
+	}, []string{"endpoint", "code"})
+	componentStatusTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "revere_component_status_transitions_total",
+		Help: "Changes to each component's desired status",
+	}, []string{"component", "from", "to"})
+	componentStatus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "revere_component_status",
+		Help: "Each component's desired status: 0 operational, 1 degraded performance, 2 partial outage, " +
+			"3 major outage, 4 under maintenance",
+	}, []string{"component"})
+)
+
+// RecordStatuspageResponse counts a request to Statuspage. Requests that failed without a response have
+// a code of "error".
+func RecordStatuspageResponse(response *resty.Response, err error) {
+	if response == nil || response.Request == nil {
+		statuspageRequests.WithLabelValues("unknown", "error").Inc()
+		return
+	}
+	code := "error"
+	if err == nil {
+		code = fmt.Sprintf("%d", response.StatusCode())
+	}
+	statuspageRequests.WithLabelValues(endpointOf(response.Request.Method, response.Request.URL), code).Inc()
+}
+
+// namedSegments are the path segments that follow a collection but name an endpoint instead of being an ID,
+// like the incident lists in "/pages/{id}/incidents/unresolved"
+var namedSegments = map[string]bool{
+	"unresolved":         true,
+	"scheduled":          true,
+	"active_maintenance": true,
+}
+
+// endpointOf turns a request into a low-cardinality label like "PATCH /pages/{id}/components/{id}" by
+// replacing the IDs that follow each collection in the path
+func endpointOf(method string, rawURL string) string {
+	path := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil {
+		path = parsed.Path
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	// Skip any prefix of the API root, like "/v1"
+	start := 0
+	for i, segment := range segments {
+		if segment == "pages" {
+			start = i
+			break
+		}
+	}
+	for i := start + 1; i < len(segments); i += 2 {
+		if !namedSegments[segments[i]] {
+			segments[i] = "{id}"
+		}
+	}
+	return fmt.Sprintf("%s /%s", method, strings.Join(segments[start:], "/"))
+}
+
+// SetComponentStatus records a component's desired status without counting it as a transition, as when
+// it's first seen
+func SetComponentStatus(componentName string, status statuspagetypes.Status) {
+	componentStatus.WithLabelValues(componentName).Set(float64(status))
+}
+
+// RecordComponentTransition records a change to a component's desired status
+func RecordComponentTransition(componentName string, from statuspagetypes.Status, to statuspagetypes.Status) {
+	componentStatusTransitions.WithLabelValues(componentName, from.ToSnakeCase(), to.ToSnakeCase()).Inc()
+	SetComponentStatus(componentName, to)
+}
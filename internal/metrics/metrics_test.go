@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"net/http"
+	"testing"
+)
+
+func Test_endpointOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		rawURL string
+		want   string
+	}{
+		{
+			name:   "Replaces page ID",
+			method: "GET",
+			rawURL: "https://api.statuspage.io/v1/pages/abc123/components",
+			want:   "GET /pages/{id}/components",
+		},
+		{
+			name:   "Replaces component ID",
+			method: "PATCH",
+			rawURL: "https://api.statuspage.io/v1/pages/abc123/components/def456",
+			want:   "PATCH /pages/{id}/components/{id}",
+		},
+		{
+			name:   "Keeps unresolved incidents endpoint",
+			method: "GET",
+			rawURL: "https://localhost/pages/abc123/incidents/unresolved",
+			want:   "GET /pages/{id}/incidents/unresolved",
+		},
+		{
+			name:   "Keeps maintenance incident endpoints",
+			method: "GET",
+			rawURL: "https://localhost/pages/abc123/incidents/active_maintenance",
+			want:   "GET /pages/{id}/incidents/active_maintenance",
+		},
+		{
+			name:   "Replaces incident ID",
+			method: "PATCH",
+			rawURL: "https://localhost/pages/abc123/incidents/ghi789",
+			want:   "PATCH /pages/{id}/incidents/{id}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := endpointOf(tt.method, tt.rawURL); got != tt.want {
+				t.Errorf("endpointOf() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordStatuspageResponse(t *testing.T) {
+	response := &resty.Response{
+		Request:     &resty.Request{Method: "DELETE", URL: "https://localhost/pages/abc/components/def"},
+		RawResponse: &http.Response{StatusCode: 404},
+	}
+	counter := statuspageRequests.WithLabelValues("DELETE /pages/{id}/components/{id}", "404")
+	errorCounter := statuspageRequests.WithLabelValues("DELETE /pages/{id}/components/{id}", "error")
+	before, beforeErrors := testutil.ToFloat64(counter), testutil.ToFloat64(errorCounter)
+	RecordStatuspageResponse(response, nil)
+	RecordStatuspageResponse(response, fmt.Errorf("some error"))
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("recorded %v responses, want 1", got)
+	}
+	if got := testutil.ToFloat64(errorCounter) - beforeErrors; got != 1 {
+		t.Errorf("recorded %v errors, want 1", got)
+	}
+}
+
+func TestRecordComponentTransition(t *testing.T) {
+	counter := componentStatusTransitions.WithLabelValues("metrics test component", "operational", "major_outage")
+	SetComponentStatus("metrics test component", statuspagetypes.Operational)
+	RecordComponentTransition("metrics test component", statuspagetypes.Operational, statuspagetypes.MajorOutage)
+	if got := testutil.ToFloat64(counter); got != 1 {
+		t.Errorf("recorded %v transitions, want 1", got)
+	}
+	if got := testutil.ToFloat64(componentStatus.WithLabelValues("metrics test component")); got != float64(statuspagetypes.MajorOutage) {
+		t.Errorf("status gauge %v, want %v", got, float64(statuspagetypes.MajorOutage))
+	}
+}
@@ -0,0 +1,85 @@
+package outputs
+
+import (
+	"context"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/metrics"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"sync"
+)
+
+// queuedTransitions is how many transitions each output may fall behind by before new ones are dropped for it
+const queuedTransitions = 256
+
+// FanOut notifies every output of each status transition. Each output has its own queue and goroutine, so a
+// slow or failing output doesn't hold up or break the others.
+type FanOut struct {
+	config  *configuration.Config
+	outputs []Output
+	queues  []chan state.StatusTransition
+}
+
+func NewFanOut(config *configuration.Config, outputs ...Output) *FanOut {
+	queues := make([]chan state.StatusTransition, len(outputs))
+	for i := range queues {
+		queues[i] = make(chan state.StatusTransition, queuedTransitions)
+	}
+	return &FanOut{
+		config:  config,
+		outputs: outputs,
+		queues:  queues,
+	}
+}
+
+// Handle queues the transition for every output, without blocking. It's a state.StatusChangeHandler.
+// Transitions handled before Run are queued until it's called.
+func (f *FanOut) Handle(transition state.StatusTransition) {
+	for i, output := range f.outputs {
+		select {
+		case f.queues[i] <- transition:
+		default:
+			shared.LogLn(f.config, fmt.Sprintf("%s output is behind, dropping %s transition to %s",
+				output.Name(), transition.ComponentName, transition.NewStatus.ToSnakeCase()))
+			metrics.OutputNotificationsDropped.WithLabelValues(output.Name()).Inc()
+		}
+	}
+}
+
+// Run notifies each output of its queued transitions until the context is cancelled. Transitions already queued
+// by then are still notified before Run returns, so that they aren't lost upon shutdown.
+func (f *FanOut) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i, output := range f.outputs {
+		wg.Add(1)
+		go func(output Output, queue chan state.StatusTransition) {
+			defer wg.Done()
+			for {
+				select {
+				case transition := <-queue:
+					f.notify(output, transition)
+				case <-ctx.Done():
+					for {
+						select {
+						case transition := <-queue:
+							f.notify(output, transition)
+						default:
+							return
+						}
+					}
+				}
+			}
+		}(output, f.queues[i])
+	}
+	wg.Wait()
+}
+
+// notify gives a single transition to the output, logging and counting any failure
+func (f *FanOut) notify(output Output, transition state.StatusTransition) {
+	if err := output.Notify(transition); err != nil {
+		shared.LogLn(f.config, fmt.Sprintf("failed to notify %s output of %s transition to %s: %v",
+			output.Name(), transition.ComponentName, transition.NewStatus.ToSnakeCase(), err))
+		metrics.OutputNotificationsFailed.WithLabelValues(output.Name()).Inc()
+	}
+}
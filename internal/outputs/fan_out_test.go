@@ -0,0 +1,116 @@
+package outputs
+
+import (
+	"context"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/email"
+	"github.com/broadinstitute/revere/internal/slack"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The StatusWriter is Statuspage's output
+var _ Output = (*statuspage.StatusWriter)(nil)
+
+var _ Output = (*slack.Output)(nil)
+
+var _ Output = (*email.Output)(nil)
+
+// recordingOutput records the components it's notified of, failing if it's told to
+type recordingOutput struct {
+	name     string
+	fail     bool
+	notified []string
+	lock     sync.Mutex
+}
+
+func (o *recordingOutput) Name() string {
+	return o.name
+}
+
+func (o *recordingOutput) Notify(transition state.StatusTransition) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.notified = append(o.notified, transition.ComponentName)
+	if o.fail {
+		return fmt.Errorf("some error")
+	}
+	return nil
+}
+
+func (o *recordingOutput) getNotified() []string {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return append([]string{}, o.notified...)
+}
+
+// waitForNotified waits for the output to have been notified of the given number of transitions
+func waitForNotified(t *testing.T, output *recordingOutput, count int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(output.getNotified()) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s output was notified of %d transitions, want %d", output.name, len(output.getNotified()), count)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	config := &configuration.Config{}
+	failing := &recordingOutput{name: "failing", fail: true}
+	working := &recordingOutput{name: "working"}
+	fanOut := NewFanOut(config, failing, working)
+	// transitions before running are queued
+	fanOut.Handle(state.StatusTransition{ComponentName: "foo", NewStatus: statuspagetypes.MajorOutage})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		fanOut.Run(ctx)
+		close(done)
+	}()
+	fanOut.Handle(state.StatusTransition{ComponentName: "bar", NewStatus: statuspagetypes.PartialOutage})
+	waitForNotified(t, failing, 2)
+	waitForNotified(t, working, 2)
+	cancel()
+	<-done
+	for _, output := range []*recordingOutput{failing, working} {
+		if diff := cmp.Diff([]string{"foo", "bar"}, output.getNotified()); diff != "" {
+			t.Errorf("%s output notifications mismatch (-want +got):\n%s", output.name, diff)
+		}
+	}
+}
+
+func TestFanOut_notifiesQueuedWhenCancelled(t *testing.T) {
+	output := &recordingOutput{name: "working"}
+	fanOut := NewFanOut(&configuration.Config{}, output)
+	fanOut.Handle(state.StatusTransition{ComponentName: "foo", NewStatus: statuspagetypes.MajorOutage})
+	fanOut.Handle(state.StatusTransition{ComponentName: "bar", NewStatus: statuspagetypes.PartialOutage})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fanOut.Run(ctx)
+	if diff := cmp.Diff([]string{"foo", "bar"}, output.getNotified()); diff != "" {
+		t.Errorf("Run() notifications upon cancellation mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFanOut_dropsWhenBehind(t *testing.T) {
+	output := &recordingOutput{name: "slow"}
+	fanOut := NewFanOut(&configuration.Config{}, output)
+	for i := 0; i < queuedTransitions+10; i++ {
+		fanOut.Handle(state.StatusTransition{ComponentName: fmt.Sprintf("component %d", i)})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fanOut.Run(ctx)
+	waitForNotified(t, output, queuedTransitions)
+	time.Sleep(10 * time.Millisecond)
+	if got := len(output.getNotified()); got != queuedTransitions {
+		t.Errorf("output was notified of %d transitions, want %d", got, queuedTransitions)
+	}
+}
@@ -0,0 +1,14 @@
+package outputs
+
+import "github.com/broadinstitute/revere/internal/state"
+
+// Output is somewhere Revere communicates changes to components' statuses, like Statuspage. Outputs are
+// independent of each other and of how alerts are handled: they're only told about status transitions,
+// one at a time and in the order they happened, by a FanOut.
+type Output interface {
+	// Name identifies the output in logs and metrics, like "statuspage"
+	Name() string
+	// Notify communicates a single transition. Errors are logged and counted, but the transition isn't retried,
+	// so outputs that need to converge on the current status should also do so on their own.
+	Notify(transition state.StatusTransition) error
+}
@@ -5,66 +5,59 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/broadinstitute/revere/internal/alerts"
 	"github.com/broadinstitute/revere/internal/cloudmonitoring"
 	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/metrics"
 	"github.com/broadinstitute/revere/internal/pubsub/pubsubtypes"
 	"github.com/broadinstitute/revere/internal/shared"
-	"os"
+	"sync"
 )
 
 // receiveOnce should handle a single message; will run asynchronously
 func receiveOnce(config *configuration.Config, msg *pubsub.Message, callback pubsubtypes.PerComponentHandler) error {
+	metrics.AlertsReceived.WithLabelValues("pubsub").Inc()
+
 	// parse Google's data structure
 	var packet *cloudmonitoring.MonitoringPacket
 	if err := json.Unmarshal(msg.Data, &packet); err != nil {
 		shared.LogLn(config, fmt.Sprintf("failed to parse packet, ignoring: %v", err))
+		metrics.AlertsIgnored.WithLabelValues("pubsub", "parse_failure").Inc()
 		return nil
 	}
 
-	// parse Revere's labels
-	labels, err := packet.ParseLabels()
-	if err != nil {
-		shared.LogLn(config, fmt.Sprintf("failed to parse labels from %s packet, ignoring: %v", packet.Incident.PolicyName, err))
+	// translate it to an event, the handling of which is shared with other inputs
+	event := alerts.EventFromMonitoringPacket(config, "pubsub", packet)
+	if event == nil {
 		return nil
 	}
-	shared.LogLn(config, fmt.Sprintf("pubsub alert %s (closed: %v) -- parsed %+v (%s)",
-		packet.Incident.PolicyName, packet.Incident.HasEnded(), labels, labels.AlertType.ToString()))
-
-	// execute callback for each affected component
-	affectedSomeComponents := false
-	for _, serviceMapping := range config.ServiceToComponentMapping {
-		if serviceMapping.ServiceName == labels.ServiceName &&
-			serviceMapping.ServiceEnvironment == labels.ServiceEnvironment {
-			for _, componentName := range serviceMapping.AffectsComponentsNamed {
-				affectedSomeComponents = true
-				shared.LogLn(config,
-					fmt.Sprintf("pubsub alert %s affects %s, executing callback...", packet.Incident.IncidentID, componentName))
-				if err := callback(componentName, labels, packet.Incident); err != nil {
-					shared.LogLn(config,
-						"failed to execute callback", fmt.Sprintf("%+v", err))
-					return err
-				}
-			}
-		}
-	}
-	if !affectedSomeComponents {
-		shared.LogLn(config, fmt.Sprintf("pubsub alert %s affected no components, ignoring", packet.Incident.PolicyName))
-	}
-	return nil
+	return alerts.HandleAlertEvent(config, event, callback)
 }
 
-// ReceiveMessages should never terminate, it continually pulls messages from the subscription
+// ReceiveMessages continually pulls messages from the subscription until the context is cancelled. Failures
+// to handle alerts are expected to have been retried and dead-lettered by the callback, so any error it still
+// returns is unrecoverable: the message is left for redelivery and ReceiveMessages stops, returning the error.
 func ReceiveMessages(config *configuration.Config, client *pubsub.Client, ctx context.Context, callback pubsubtypes.PerComponentHandler) error {
 	subscription := client.Subscription(config.Pubsub.SubscriptionID)
-	return subscription.Receive(ctx, func(cctx context.Context, msg *pubsub.Message) {
+	receiveCtx, cancelReceive := context.WithCancel(ctx)
+	defer cancelReceive()
+	var failure error
+	var failureOnce sync.Once
+	err := subscription.Receive(receiveCtx, func(cctx context.Context, msg *pubsub.Message) {
 		if err := receiveOnce(config, msg, callback); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, err)
-			// There may be multiple infinite goroutines (see serve.go), to exit we have to do so forcibly.
-			// We specifically don't call msg.Nack before doing so because we want to let the lease expire,
-			// instead of pubsub retrying it immediately and *then* having it time out because we've exited.
-			os.Exit(1)
+			shared.LogLn(config, fmt.Sprintf("failed to handle pubsub message %s, stopping: %v", msg.ID, err))
+			msg.Nack()
+			failureOnce.Do(func() {
+				failure = err
+				cancelReceive()
+			})
 		} else {
 			msg.Ack()
+			metrics.PubsubMessagesAcked.Inc()
 		}
 	})
+	if failure != nil {
+		return failure
+	}
+	return err
 }
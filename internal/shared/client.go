@@ -3,20 +3,28 @@ package shared
 import (
 	"fmt"
 	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/metrics"
 	"github.com/go-resty/resty/v2"
+	"time"
 )
 
 // BaseClient should configure Resty "globally", not in any service-dependent way
 func BaseClient(config *configuration.Config) *resty.Client {
-	return resty.New().
+	client := resty.New().
 		SetRedirectPolicy(resty.FlexibleRedirectPolicy(config.Client.Redirects)).
 		SetRetryCount(config.Client.Retries)
+	if config.Client.MaxRetryWaitSeconds > 0 {
+		client.SetRetryMaxWaitTime(time.Duration(config.Client.MaxRetryWaitSeconds) * time.Second)
+	}
+	return client
 }
 
 // CheckResponse returns an error if the response wasn't successful.
 // This helps us safely call response.Result() when err is nil, because a non-200-series response
 // normally does not produce any sort of error but the Result() will be empty.
+// Every response checked is counted in Revere's metrics.
 func CheckResponse(response *resty.Response, err error) error {
+	metrics.RecordStatuspageResponse(response, err)
 	if err != nil {
 		return err
 	} else if response.StatusCode() < 200 || response.StatusCode() > 299 {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestBaseClient(t *testing.T) {
@@ -23,8 +24,10 @@ func TestBaseClient(t *testing.T) {
 			name: "Uses config redirects",
 			args: args{config: &configuration.Config{
 				Client: struct {
-					Redirects int
-					Retries   int
+					Redirects           int
+					Retries             int
+					MaxRetryWaitSeconds int
+					RequestsPerSecond   float64
 				}{Retries: 2},
 			}},
 			selector: func(client *resty.Client) interface{} {
@@ -36,8 +39,10 @@ func TestBaseClient(t *testing.T) {
 			name: "Sets redirection function when redirects is passed",
 			args: args{config: &configuration.Config{
 				Client: struct {
-					Redirects int
-					Retries   int
+					Redirects           int
+					Retries             int
+					MaxRetryWaitSeconds int
+					RequestsPerSecond   float64
 				}{Redirects: 2},
 			}},
 			selector: func(client *resty.Client) interface{} {
@@ -45,6 +50,36 @@ func TestBaseClient(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "Uses config max retry wait",
+			args: args{config: &configuration.Config{
+				Client: struct {
+					Redirects           int
+					Retries             int
+					MaxRetryWaitSeconds int
+					RequestsPerSecond   float64
+				}{MaxRetryWaitSeconds: 30},
+			}},
+			selector: func(client *resty.Client) interface{} {
+				return client.RetryMaxWaitTime
+			},
+			want: 30 * time.Second,
+		},
+		{
+			name: "Leaves rate limiting to each service's client",
+			args: args{config: &configuration.Config{
+				Client: struct {
+					Redirects           int
+					Retries             int
+					MaxRetryWaitSeconds int
+					RequestsPerSecond   float64
+				}{RequestsPerSecond: 1},
+			}},
+			selector: func(client *resty.Client) interface{} {
+				return len(client.RetryConditions) == 0 && client.RetryAfter == nil
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
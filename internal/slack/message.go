@@ -0,0 +1,65 @@
+package slack
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/state"
+	"strings"
+)
+
+// formatMessage describes the transition in Slack's mrkdwn: the new status, the alert that caused it, and the
+// component's open incidents
+func formatMessage(transition state.StatusTransition) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("*%s* is now %s (was %s)", escape(transition.ComponentName),
+		transition.NewStatus.ToString(), transition.OldStatus.ToString()))
+	if cause := transition.Cause; cause != nil {
+		name := cause.Name
+		if name == "" {
+			name = cause.IncidentID
+		}
+		builder.WriteString("\nCaused by ")
+		builder.WriteString(link(name, cause.URL()))
+		if cause.Ended {
+			builder.WriteString(" resolving")
+		}
+		if cause.Summary != "" {
+			builder.WriteString(": ")
+			builder.WriteString(escape(cause.Summary))
+		}
+	}
+	if len(transition.Incidents) > 0 {
+		builder.WriteString("\nOpen incidents:")
+		for _, incident := range transition.Incidents {
+			name := incident.Name
+			if name == "" {
+				name = incident.ID
+			}
+			builder.WriteString("\n• ")
+			builder.WriteString(link(name, incident.URL))
+			builder.WriteString(fmt.Sprintf(" (%s", incident.Status.ToString()))
+			if incident.Service != "" {
+				builder.WriteString(fmt.Sprintf(", %s", escape(incident.Service)))
+			}
+			builder.WriteString(")")
+			if incident.Summary != "" {
+				builder.WriteString(": ")
+				builder.WriteString(escape(incident.Summary))
+			}
+		}
+	}
+	return builder.String()
+}
+
+// link formats text as a link to url if there is one
+func link(text string, url string) string {
+	if url == "" {
+		return escape(text)
+	}
+	return fmt.Sprintf("<%s|%s>", url, escape(text))
+}
+
+// escape replaces the characters that Slack's mrkdwn gives meaning to, per
+// https://api.slack.com/reference/surfaces/formatting#escaping
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,71 @@
+package slack
+
+import (
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"testing"
+)
+
+var sortStrings = cmpopts.SortSlices(func(a, b string) bool { return a < b })
+
+func Test_formatMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		transition state.StatusTransition
+		want       string
+	}{
+		{
+			name: "status alone",
+			transition: state.StatusTransition{
+				ComponentName: "notebooks",
+				OldStatus:     statuspagetypes.Operational,
+				NewStatus:     statuspagetypes.DegradedPerformance,
+			},
+			want: "*notebooks* is now Degraded Performance (was Operational)",
+		},
+		{
+			name: "resolving cause without a name or link",
+			transition: state.StatusTransition{
+				ComponentName: "notebooks",
+				OldStatus:     statuspagetypes.MajorOutage,
+				NewStatus:     statuspagetypes.Operational,
+				Cause:         &events.AlertEvent{IncidentID: "0.abc", Ended: true},
+			},
+			want: "*notebooks* is now Operational (was Major Outage)\nCaused by 0.abc resolving",
+		},
+		{
+			name: "incidents without details",
+			transition: state.StatusTransition{
+				ComponentName: "notebooks",
+				OldStatus:     statuspagetypes.Operational,
+				NewStatus:     statuspagetypes.MajorOutage,
+				Incidents: []state.OpenIncident{
+					{ID: "0.abc", Status: statuspagetypes.MajorOutage},
+					{ID: "0.def", Status: statuspagetypes.PartialOutage, Service: "leonardo"},
+				},
+			},
+			want: "*notebooks* is now Major Outage (was Operational)\nOpen incidents:\n" +
+				"• 0.abc (Major Outage)\n• 0.def (Partial Outage, leonardo)",
+		},
+		{
+			name: "escapes mrkdwn",
+			transition: state.StatusTransition{
+				ComponentName: "<notebooks>",
+				OldStatus:     statuspagetypes.Operational,
+				NewStatus:     statuspagetypes.MajorOutage,
+				Cause:         &events.AlertEvent{Name: "a & b", Summary: "<!channel>"},
+			},
+			want: "*&lt;notebooks&gt;* is now Major Outage (was Operational)\nCaused by a &amp; b: &lt;!channel&gt;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, formatMessage(tt.transition)); diff != "" {
+				t.Errorf("formatMessage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package slack
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/go-resty/resty/v2"
+)
+
+// Output posts each change to a component's status to the Slack channels routed to it. It's Slack's
+// outputs.Output.
+type Output struct {
+	config *configuration.Config
+	client *resty.Client
+}
+
+func NewOutput(config *configuration.Config) *Output {
+	return &Output{
+		config: config,
+		client: shared.BaseClient(config),
+	}
+}
+
+// Name identifies the Output among Revere's outputs
+func (o *Output) Name() string {
+	return "slack"
+}
+
+// Notify posts the transition to every webhook routed to its component. Every webhook is tried even if
+// some fail, and the first failure is returned.
+func (o *Output) Notify(transition state.StatusTransition) error {
+	var firstErr error
+	for _, webhookURL := range webhooksFor(o.config, transition.ComponentName) {
+		if err := o.post(webhookURL, formatMessage(transition)); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// post sends text to an incoming webhook. Slack's responses aren't Statuspage's, so shared.CheckResponse
+// isn't used.
+func (o *Output) post(webhookURL string, text string) error {
+	resp, err := o.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]string{"text": text}).
+		Post(webhookURL)
+	if err != nil {
+		return fmt.Errorf("failed to post to slack: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("slack responded %s: %s", resp.Status(), resp.String())
+	}
+	return nil
+}
+
+// webhooksFor returns the webhooks of every route matching the component, either directly or via one of its
+// groups, without duplicates. Slack.WebhookURL is used for components no route matches, if it's set.
+func webhooksFor(config *configuration.Config, componentName string) []string {
+	groupsOfComponent := make(map[string]struct{})
+	for _, group := range config.Statuspage.Groups {
+		for _, name := range group.ComponentNames {
+			if name == componentName {
+				groupsOfComponent[group.Name] = struct{}{}
+			}
+		}
+	}
+	var webhooks []string
+	seen := make(map[string]struct{})
+	for _, route := range config.Slack.Routes {
+		if !routeMatches(route, componentName, groupsOfComponent) {
+			continue
+		}
+		webhookURL := route.WebhookURL
+		if webhookURL == "" {
+			webhookURL = config.Slack.WebhookURL
+		}
+		if _, present := seen[webhookURL]; !present {
+			seen[webhookURL] = struct{}{}
+			webhooks = append(webhooks, webhookURL)
+		}
+	}
+	if len(webhooks) == 0 && config.Slack.WebhookURL != "" {
+		webhooks = append(webhooks, config.Slack.WebhookURL)
+	}
+	return webhooks
+}
+
+func routeMatches(route configuration.SlackRoute, componentName string, groupsOfComponent map[string]struct{}) bool {
+	for _, name := range route.ComponentNames {
+		if name == componentName {
+			return true
+		}
+	}
+	for _, name := range route.GroupNames {
+		if _, present := groupsOfComponent[name]; present {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package slack
+
+import (
+	"encoding/json"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// webhookStandIn stands in for Slack's incoming webhooks, recording the text posted to each path and responding
+// with failure to paths starting with /fail
+type webhookStandIn struct {
+	server *httptest.Server
+	posted map[string][]string
+	lock   sync.Mutex
+}
+
+func newWebhookStandIn() *webhookStandIn {
+	standIn := &webhookStandIn{posted: make(map[string][]string)}
+	standIn.server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			http.Error(writer, "invalid_payload", http.StatusBadRequest)
+			return
+		}
+		standIn.lock.Lock()
+		standIn.posted[request.URL.Path] = append(standIn.posted[request.URL.Path], body.Text)
+		standIn.lock.Unlock()
+		if strings.HasPrefix(request.URL.Path, "/fail") {
+			http.Error(writer, "no_service", http.StatusNotFound)
+			return
+		}
+		_, _ = writer.Write([]byte("ok"))
+	}))
+	return standIn
+}
+
+func (s *webhookStandIn) getPostedPaths() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var paths []string
+	for path := range s.posted {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func testConfig(standIn *webhookStandIn, defaultPath string, routes ...configuration.SlackRoute) *configuration.Config {
+	config := &configuration.Config{}
+	config.Statuspage.Components = []configuration.Component{{Name: "notebooks"}, {Name: "ui"}, {Name: "workspaces"}}
+	config.Statuspage.Groups = []configuration.ComponentGroup{{Name: "terra", ComponentNames: []string{"notebooks", "ui"}}}
+	config.Slack.Enabled = true
+	if defaultPath != "" {
+		config.Slack.WebhookURL = standIn.server.URL + defaultPath
+	}
+	for _, route := range routes {
+		if route.WebhookURL != "" {
+			route.WebhookURL = standIn.server.URL + route.WebhookURL
+		}
+		config.Slack.Routes = append(config.Slack.Routes, route)
+	}
+	return config
+}
+
+func TestOutput_Notify(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultPath   string
+		routes        []configuration.SlackRoute
+		componentName string
+		wantPaths     []string
+		wantErr       bool
+	}{
+		{
+			name:          "posts to the default webhook without routes",
+			defaultPath:   "/default",
+			componentName: "notebooks",
+			wantPaths:     []string{"/default"},
+		},
+		{
+			name:        "posts to routes by component",
+			defaultPath: "/default",
+			routes: []configuration.SlackRoute{
+				{WebhookURL: "/notebooks", ComponentNames: []string{"notebooks"}},
+				{WebhookURL: "/ui", ComponentNames: []string{"ui"}},
+			},
+			componentName: "notebooks",
+			wantPaths:     []string{"/notebooks"},
+		},
+		{
+			name:        "posts to routes by group",
+			defaultPath: "/default",
+			routes: []configuration.SlackRoute{
+				{WebhookURL: "/terra", GroupNames: []string{"terra"}},
+				{WebhookURL: "/workspaces", ComponentNames: []string{"workspaces"}},
+			},
+			componentName: "ui",
+			wantPaths:     []string{"/terra"},
+		},
+		{
+			name:        "posts to every matching route once",
+			defaultPath: "/default",
+			routes: []configuration.SlackRoute{
+				{WebhookURL: "/terra", GroupNames: []string{"terra"}},
+				{WebhookURL: "/terra", ComponentNames: []string{"notebooks"}},
+				{ComponentNames: []string{"notebooks"}},
+			},
+			componentName: "notebooks",
+			wantPaths:     []string{"/default", "/terra"},
+		},
+		{
+			name:        "falls back to the default webhook when no route matches",
+			defaultPath: "/default",
+			routes: []configuration.SlackRoute{
+				{WebhookURL: "/terra", GroupNames: []string{"terra"}},
+			},
+			componentName: "workspaces",
+			wantPaths:     []string{"/default"},
+		},
+		{
+			name: "posts nowhere without a default webhook when no route matches",
+			routes: []configuration.SlackRoute{
+				{WebhookURL: "/terra", GroupNames: []string{"terra"}},
+			},
+			componentName: "workspaces",
+		},
+		{
+			name:        "tries every webhook but errors if one fails",
+			defaultPath: "/default",
+			routes: []configuration.SlackRoute{
+				{WebhookURL: "/fail", ComponentNames: []string{"notebooks"}},
+				{WebhookURL: "/terra", GroupNames: []string{"terra"}},
+			},
+			componentName: "notebooks",
+			wantPaths:     []string{"/fail", "/terra"},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standIn := newWebhookStandIn()
+			defer standIn.server.Close()
+			output := NewOutput(testConfig(standIn, tt.defaultPath, tt.routes...))
+			err := output.Notify(state.StatusTransition{
+				ComponentName: tt.componentName,
+				OldStatus:     statuspagetypes.Operational,
+				NewStatus:     statuspagetypes.MajorOutage,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantPaths, standIn.getPostedPaths(), sortStrings); diff != "" {
+				t.Errorf("Notify() posted to unexpected webhooks (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOutput_Notify_message(t *testing.T) {
+	standIn := newWebhookStandIn()
+	defer standIn.server.Close()
+	output := NewOutput(testConfig(standIn, "/default"))
+	err := output.Notify(state.StatusTransition{
+		ComponentName: "notebooks",
+		OldStatus:     statuspagetypes.Operational,
+		NewStatus:     statuspagetypes.PartialOutage,
+		Cause: &events.AlertEvent{
+			IncidentID: "0.abc",
+			Name:       "Leonardo 5xx",
+			Summary:    "Error rate > 5%",
+			Links:      []events.Link{{Name: "Incident", URL: "https://console.cloud.google.com/monitoring/alerting/incidents/0.abc"}},
+		},
+		Incidents: []state.OpenIncident{{
+			ID:      "0.abc",
+			Status:  statuspagetypes.PartialOutage,
+			Service: "leonardo",
+			Name:    "Leonardo 5xx",
+			Summary: "Error rate > 5%",
+			URL:     "https://console.cloud.google.com/monitoring/alerting/incidents/0.abc",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	want := []string{"*notebooks* is now Partial Outage (was Operational)\n" +
+		"Caused by <https://console.cloud.google.com/monitoring/alerting/incidents/0.abc|Leonardo 5xx>: Error rate &gt; 5%\n" +
+		"Open incidents:\n" +
+		"• <https://console.cloud.google.com/monitoring/alerting/incidents/0.abc|Leonardo 5xx> (Partial Outage, leonardo): Error rate &gt; 5%"}
+	if diff := cmp.Diff(want, standIn.posted["/default"]); diff != "" {
+		t.Errorf("Notify() posted unexpected message (-want +got):\n%s", diff)
+	}
+}
@@ -1,10 +1,40 @@
 package state
 
 import (
+	"github.com/broadinstitute/revere/internal/events"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"sort"
 	"sync"
+	"time"
 )
 
+// InheritedIncidentID identifies the placeholder incident standing in for a component's status on Statuspage
+// when Revere starts without any other incidents to explain it. See configuration.Config's InheritedStatus.
+const InheritedIncidentID = "revere-inherited"
+
+// OpenIncident describes one of the incidents affecting a component, see ComponentState.GetOpenIncidents
+type OpenIncident struct {
+	ID     string                 `json:"id"`
+	Status statuspagetypes.Status `json:"status"`
+	// The rest isn't persisted, so it's unknown for incidents restored from a Store
+	Service  string     `json:"service,omitempty"`
+	Source   string     `json:"source,omitempty"`
+	OpenedAt *time.Time `json:"openedAt,omitempty"`
+	// Name, Summary, and URL describe the alert behind the incident, as of its latest notification
+	Name    string `json:"name,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// incidentDetails records information about an open incident that doesn't affect the component's status
+type incidentDetails struct {
+	source   string
+	openedAt time.Time
+	name     string
+	summary  string
+	url      string
+}
+
 // ComponentState records information about components that's derived during continuous operation.
 // Its fields shouldn't be operated on in parallel; it contains a sync.Mutex to help state.State
 // manage attempts at concurrent access.
@@ -13,19 +43,85 @@ type ComponentState struct {
 	desiredStatus statuspagetypes.Status
 	id            string
 	lock          *sync.Mutex
+	// incidentsChanged records if openIncidents and incidentServices must be persisted, see State.UseComponent
+	incidentsChanged bool
+	// inheritedStatusPolicy determines when the InheritedIncidentID incident is implicitly resolved
+	inheritedStatusPolicy string
+	// statuspageIncidentID is the ID of the Statuspage incident currently open for this component, if any
+	statuspageIncidentID string
+	// incidentDetails describes the incidents in openIncidents, if known, see DescribeIncident
+	incidentDetails map[string]incidentDetails
+	// override is a manually set status that wins over openIncidents while active, see Override
+	override *Override
+	// underMaintenance records if a MaintenanceWindow affecting the component is in progress, which wins over
+	// openIncidents but not over an override
+	underMaintenance bool
+	// incidentServices records which service each incident in openIncidents is for, if known, see LogServiceIncident
+	incidentServices map[string]string
+	// escalations may make the status worse than the worst of openIncidents, see escalation
+	escalations []escalation
+	// damping holds back status changes caused by openIncidents, if configured; pending is the change being
+	// held back and transitions are the times of recent damped changes
+	damping     *damping
+	pending     *pendingChange
+	transitions []time.Time
+	settleTimer *time.Timer
+	// cause is the alert behind the latest change to the component, see SetCause
+	cause *events.AlertEvent
+	// incidentSync is a pending request to sync the component's Statuspage incident, see RequestIncidentSync
+	incidentSync *IncidentSync
+}
+
+// IncidentSync is a request for a component's Statuspage incident to be opened, updated, or resolved to
+// match its desired status
+type IncidentSync struct {
+	// Event is the latest alert affecting the component, which the incident's messages describe
+	Event *events.AlertEvent
+	// Update is if an already-open incident should be updated even if it isn't resolved
+	Update bool
 }
 
 // recalculateDesiresStatus updates the cached desiresStatus and returns a bool representing if the value changed.
+// An active override replaces whatever status the open incidents would give, as does maintenance otherwise.
 func (c *ComponentState) recalculateDesiredStatus() bool {
+	return c.recalculateDesiredStatusAt(time.Now())
+}
+
+// recalculateDesiredStatusAt is recalculateDesiredStatus as of the given time. Changes caused by the open
+// incidents are held back while damping requires, see damping.
+func (c *ComponentState) recalculateDesiredStatusAt(now time.Time) bool {
 	worstStatusSoFar := statuspagetypes.Operational
-	for _, status := range c.openIncidents {
-		worstStatusSoFar = worstStatusSoFar.WorstWith(status)
+	damped := false
+	if c.override != nil && c.override.activeAt(now) {
+		worstStatusSoFar = c.override.Status
+	} else if c.underMaintenance {
+		worstStatusSoFar = statuspagetypes.UnderMaintenance
+	} else {
+		for _, status := range c.openIncidents {
+			worstStatusSoFar = worstStatusSoFar.WorstWith(status)
+		}
+		worstStatusSoFar = worstStatusSoFar.WorstWith(c.escalatedStatus())
+		damped = c.damping != nil
 	}
-	if worstStatusSoFar != c.desiredStatus {
-		c.desiredStatus = worstStatusSoFar
-		return true
+	if worstStatusSoFar == c.desiredStatus {
+		c.clearPending()
+		return false
 	}
-	return false
+	if damped {
+		if c.pending == nil || c.pending.status != worstStatusSoFar {
+			c.pending = &pendingChange{status: worstStatusSoFar, since: now}
+		}
+		c.transitions = c.damping.recentTransitions(c.transitions, now)
+		if now.Before(c.damping.settleAt(*c.pending, c.desiredStatus, c.transitions)) {
+			return false
+		}
+		if c.damping.maxTransitions > 0 {
+			c.transitions = append(c.transitions, now)
+		}
+	}
+	c.clearPending()
+	c.desiredStatus = worstStatusSoFar
+	return true
 }
 
 // GetID returns the Statuspage ID correlating to this component.
@@ -39,17 +135,160 @@ func (c *ComponentState) GetDesiredStatus() statuspagetypes.Status {
 	return c.desiredStatus
 }
 
+// GetStatuspageIncidentID returns the ID of the Statuspage incident open for this component, or an empty string.
+func (c *ComponentState) GetStatuspageIncidentID() string {
+	return c.statuspageIncidentID
+}
+
+// SetStatuspageIncidentID records the ID of the Statuspage incident open for this component; an empty
+// string records that there's no such incident.
+func (c *ComponentState) SetStatuspageIncidentID(statuspageIncidentID string) {
+	c.statuspageIncidentID = statuspageIncidentID
+}
+
+// HasOpenIncident returns if the incident is currently affecting the component.
+func (c *ComponentState) HasOpenIncident(incidentID string) bool {
+	_, found := c.openIncidents[incidentID]
+	return found
+}
+
+// GetOpenIncidents returns the incidents currently affecting the component, sorted by ID.
+func (c *ComponentState) GetOpenIncidents() []OpenIncident {
+	openIncidents := make([]OpenIncident, 0, len(c.openIncidents))
+	for incidentID, status := range c.openIncidents {
+		openIncident := OpenIncident{ID: incidentID, Status: status, Service: c.incidentServices[incidentID]}
+		if details, found := c.incidentDetails[incidentID]; found {
+			openIncident.Source = details.source
+			if !details.openedAt.IsZero() {
+				openedAt := details.openedAt
+				openIncident.OpenedAt = &openedAt
+			}
+			openIncident.Name = details.name
+			openIncident.Summary = details.summary
+			openIncident.URL = details.url
+		}
+		openIncidents = append(openIncidents, openIncident)
+	}
+	sort.Slice(openIncidents, func(i, j int) bool {
+		return openIncidents[i].ID < openIncidents[j].ID
+	})
+	return openIncidents
+}
+
+// DescribeIncident records where an open incident came from and when it was opened, unless that's already
+// known. Has no effect if the incident isn't open.
+func (c *ComponentState) DescribeIncident(incidentID string, source string, openedAt time.Time) {
+	if _, found := c.openIncidents[incidentID]; !found {
+		return
+	}
+	if details, found := c.incidentDetails[incidentID]; found && !details.openedAt.IsZero() {
+		return
+	}
+	if c.incidentDetails == nil {
+		c.incidentDetails = make(map[string]incidentDetails)
+	}
+	details := c.incidentDetails[incidentID]
+	details.source = source
+	details.openedAt = openedAt
+	c.incidentDetails[incidentID] = details
+}
+
+// SummarizeIncident records the name, summary, and URL of the alert behind an open incident, replacing any
+// recorded from earlier notifications. Has no effect if the incident isn't open.
+func (c *ComponentState) SummarizeIncident(incidentID string, name string, summary string, url string) {
+	if _, found := c.openIncidents[incidentID]; !found {
+		return
+	}
+	if c.incidentDetails == nil {
+		c.incidentDetails = make(map[string]incidentDetails)
+	}
+	details := c.incidentDetails[incidentID]
+	details.name = name
+	details.summary = summary
+	details.url = url
+	c.incidentDetails[incidentID] = details
+}
+
+// SetCause records the alert behind whatever change is being made to the component, so that it's given
+// with the resulting StatusTransition, if any. It only applies within the current State.UseComponent hook.
+func (c *ComponentState) SetCause(event *events.AlertEvent) {
+	c.cause = event
+}
+
+// RequestIncidentSync asks for the component's Statuspage incident to be synced with its desired status, by
+// something outside the State.UseComponent hook (see statuspage.IncidentWriter). Requests made before an earlier
+// one is taken are merged with it.
+func (c *ComponentState) RequestIncidentSync(event *events.AlertEvent, update bool) {
+	if c.incidentSync != nil {
+		update = update || c.incidentSync.Update
+	}
+	c.incidentSync = &IncidentSync{Event: event, Update: update}
+}
+
+// TakeIncidentSync returns the pending request to sync the component's Statuspage incident, if any, so that
+// it's no longer pending.
+func (c *ComponentState) TakeIncidentSync() *IncidentSync {
+	incidentSync := c.incidentSync
+	c.incidentSync = nil
+	return incidentSync
+}
+
+// ReturnIncidentSync puts back a request taken by TakeIncidentSync that couldn't be carried out, so it's
+// retried. A newer request wins over it, except that its Update is kept.
+func (c *ComponentState) ReturnIncidentSync(incidentSync *IncidentSync) {
+	if c.incidentSync != nil {
+		c.incidentSync.Update = c.incidentSync.Update || incidentSync.Update
+		return
+	}
+	c.incidentSync = incidentSync
+}
+
 // LogIncident notes a new/updated incident affecting the status of the component.
+// Under the "wait" inherited status policy, any other incident supersedes the inherited one.
 // The returned bool represents if the component's entire status changed based on the new incident.
 func (c *ComponentState) LogIncident(incidentID string, componentStatus statuspagetypes.Status) bool {
-	c.openIncidents[incidentID] = componentStatus
+	if existingStatus, found := c.openIncidents[incidentID]; !found || existingStatus != componentStatus {
+		c.openIncidents[incidentID] = componentStatus
+		c.incidentsChanged = true
+	}
+	if c.inheritedStatusPolicy == "wait" && incidentID != InheritedIncidentID {
+		c.removeIncident(InheritedIncidentID)
+	}
 	return c.recalculateDesiredStatus()
 }
 
+// LogServiceIncident is LogIncident for an incident known to be for a particular service, which escalations
+// may count. An empty serviceName isn't recorded, so the incident isn't counted for any service.
+func (c *ComponentState) LogServiceIncident(incidentID string, serviceName string, componentStatus statuspagetypes.Status) bool {
+	if serviceName != "" && c.incidentServices[incidentID] != serviceName {
+		if c.incidentServices == nil {
+			c.incidentServices = make(map[string]string)
+		}
+		c.incidentServices[incidentID] = serviceName
+		c.incidentsChanged = true
+	}
+	return c.LogIncident(incidentID, componentStatus)
+}
+
 // ResolveIncident notes than an incident is no longer affecting the status of the component.
-// Has no effect if the incident has already been resolved or never existed/
+// Has no effect if the incident has already been resolved or never existed.
+// Under the "keep" inherited status policy, resolving any other open incident resolves the inherited one too.
 // The return bool represents if the component's entire status changed based on the resolved incident.
 func (c *ComponentState) ResolveIncident(incidentID string) bool {
-	delete(c.openIncidents, incidentID)
+	if c.removeIncident(incidentID) && c.inheritedStatusPolicy == "keep" && incidentID != InheritedIncidentID {
+		c.removeIncident(InheritedIncidentID)
+	}
 	return c.recalculateDesiredStatus()
 }
+
+// removeIncident deletes an incident without recalculating the desired status, returning if it was present.
+func (c *ComponentState) removeIncident(incidentID string) bool {
+	if _, found := c.openIncidents[incidentID]; found {
+		delete(c.openIncidents, incidentID)
+		delete(c.incidentDetails, incidentID)
+		delete(c.incidentServices, incidentID)
+		c.incidentsChanged = true
+		return true
+	}
+	return false
+}
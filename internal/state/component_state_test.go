@@ -1,10 +1,12 @@
 package state
 
 import (
+	"github.com/broadinstitute/revere/internal/events"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
 	"github.com/google/go-cmp/cmp"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestComponentState_GetDesiredStatus(t *testing.T) {
@@ -331,3 +333,158 @@ func TestComponentState_ResolveIncident(t *testing.T) {
 		})
 	}
 }
+
+func TestComponentState_inheritedIncidentPolicies(t *testing.T) {
+	tests := []struct {
+		name              string
+		policy            string
+		action            func(c *ComponentState) bool
+		want              bool
+		wantIncidents     map[string]statuspagetypes.Status
+		wantDesiredStatus statuspagetypes.Status
+	}{
+		{
+			name:   "Keep holds inherited incident through new incidents",
+			policy: "keep",
+			action: func(c *ComponentState) bool {
+				return c.LogIncident("abc", statuspagetypes.DegradedPerformance)
+			},
+			want: false,
+			wantIncidents: map[string]statuspagetypes.Status{
+				InheritedIncidentID: statuspagetypes.MajorOutage,
+				"abc":               statuspagetypes.DegradedPerformance,
+			},
+			wantDesiredStatus: statuspagetypes.MajorOutage,
+		},
+		{
+			name:   "Keep resolves inherited incident alongside others",
+			policy: "keep",
+			action: func(c *ComponentState) bool {
+				c.LogIncident("abc", statuspagetypes.DegradedPerformance)
+				return c.ResolveIncident("abc")
+			},
+			want:              true,
+			wantIncidents:     map[string]statuspagetypes.Status{},
+			wantDesiredStatus: statuspagetypes.Operational,
+		},
+		{
+			name:   "Keep ignores resolving unknown incidents",
+			policy: "keep",
+			action: func(c *ComponentState) bool {
+				return c.ResolveIncident("abc")
+			},
+			want: false,
+			wantIncidents: map[string]statuspagetypes.Status{
+				InheritedIncidentID: statuspagetypes.MajorOutage,
+			},
+			wantDesiredStatus: statuspagetypes.MajorOutage,
+		},
+		{
+			name:   "Wait supersedes inherited incident with new incidents",
+			policy: "wait",
+			action: func(c *ComponentState) bool {
+				return c.LogIncident("abc", statuspagetypes.DegradedPerformance)
+			},
+			want: true,
+			wantIncidents: map[string]statuspagetypes.Status{
+				"abc": statuspagetypes.DegradedPerformance,
+			},
+			wantDesiredStatus: statuspagetypes.DegradedPerformance,
+		},
+		{
+			name:   "Inherited incident can be resolved directly",
+			policy: "wait",
+			action: func(c *ComponentState) bool {
+				return c.ResolveIncident(InheritedIncidentID)
+			},
+			want:              true,
+			wantIncidents:     map[string]statuspagetypes.Status{},
+			wantDesiredStatus: statuspagetypes.Operational,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ComponentState{
+				openIncidents: map[string]statuspagetypes.Status{
+					InheritedIncidentID: statuspagetypes.MajorOutage,
+				},
+				desiredStatus:         statuspagetypes.MajorOutage,
+				inheritedStatusPolicy: tt.policy,
+			}
+			if got := tt.action(c); got != tt.want {
+				t.Errorf("action = %v, want %v", got, tt.want)
+			}
+			if diff := cmp.Diff(tt.wantIncidents, c.openIncidents); diff != "" {
+				t.Errorf("action bad effect: %s", diff)
+			}
+			if c.desiredStatus != tt.wantDesiredStatus {
+				t.Errorf("action bad effect: got desiredStatus %v, want %v", c.desiredStatus, tt.wantDesiredStatus)
+			}
+		})
+	}
+}
+
+func TestComponentState_GetOpenIncidents(t *testing.T) {
+	openedAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := &ComponentState{openIncidents: map[string]statuspagetypes.Status{}}
+	c.LogIncident("def", statuspagetypes.MajorOutage)
+	c.DescribeIncident("def", "pubsub", openedAt)
+	// details are only recorded once per incident
+	c.DescribeIncident("def", "alertmanager", openedAt.Add(time.Hour))
+	// summaries are replaced by each notification
+	c.SummarizeIncident("def", "Sam latency", "Latency is high", "https://example.com/old")
+	c.SummarizeIncident("def", "Sam latency", "Latency is very high", "https://example.com/def")
+	c.LogIncident("abc", statuspagetypes.DegradedPerformance)
+	// details can't be recorded for incidents that aren't open
+	c.DescribeIncident("ghi", "pubsub", openedAt)
+	c.SummarizeIncident("ghi", "Rawls errors", "", "")
+	want := []OpenIncident{
+		{ID: "abc", Status: statuspagetypes.DegradedPerformance},
+		{ID: "def", Status: statuspagetypes.MajorOutage, Source: "pubsub", OpenedAt: &openedAt,
+			Name: "Sam latency", Summary: "Latency is very high", URL: "https://example.com/def"},
+	}
+	if diff := cmp.Diff(want, c.GetOpenIncidents()); diff != "" {
+		t.Errorf("GetOpenIncidents() mismatch (-want +got):\n%s", diff)
+	}
+	c.ResolveIncident("def")
+	c.LogIncident("def", statuspagetypes.MajorOutage)
+	want = []OpenIncident{
+		{ID: "abc", Status: statuspagetypes.DegradedPerformance},
+		{ID: "def", Status: statuspagetypes.MajorOutage},
+	}
+	if diff := cmp.Diff(want, c.GetOpenIncidents()); diff != "" {
+		t.Errorf("GetOpenIncidents() after resolving mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestComponentState_IncidentSync(t *testing.T) {
+	first := &events.AlertEvent{IncidentID: "first"}
+	second := &events.AlertEvent{IncidentID: "second"}
+	c := &ComponentState{}
+	if got := c.TakeIncidentSync(); got != nil {
+		t.Errorf("TakeIncidentSync() = %+v before any request, want nil", got)
+	}
+	// requests are merged until taken
+	c.RequestIncidentSync(first, true)
+	c.RequestIncidentSync(second, false)
+	want := &IncidentSync{Event: second, Update: true}
+	taken := c.TakeIncidentSync()
+	if diff := cmp.Diff(want, taken); diff != "" {
+		t.Errorf("TakeIncidentSync() mismatch (-want +got):\n%s", diff)
+	}
+	if got := c.TakeIncidentSync(); got != nil {
+		t.Errorf("TakeIncidentSync() = %+v after taking, want nil", got)
+	}
+	// a returned request is retried
+	c.ReturnIncidentSync(taken)
+	if diff := cmp.Diff(want, c.TakeIncidentSync()); diff != "" {
+		t.Errorf("TakeIncidentSync() after return mismatch (-want +got):\n%s", diff)
+	}
+	// unless a newer request was made meanwhile
+	c.RequestIncidentSync(first, false)
+	c.ReturnIncidentSync(taken)
+	want = &IncidentSync{Event: first, Update: true}
+	if diff := cmp.Diff(want, c.TakeIncidentSync()); diff != "" {
+		t.Errorf("TakeIncidentSync() after newer request mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,106 @@
+package state
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"time"
+)
+
+// damping holds back changes to a component's status caused by its incidents, so that noisy alerts don't make
+// it flap. Overrides and maintenance are never damped.
+type damping struct {
+	// How long a worse status must persist before the component takes it
+	degradeAfter time.Duration
+	// How long a better status must persist before the component takes it
+	recoverAfter time.Duration
+	// At most maxTransitions damped changes are made per transitionWindow; zero means no limit
+	maxTransitions   int
+	transitionWindow time.Duration
+}
+
+// newDamping reads the component's damping configuration, returning nil if it doesn't have any
+func newDamping(component configuration.Component) *damping {
+	d := &damping{
+		degradeAfter:     time.Duration(component.DegradeAfterSeconds) * time.Second,
+		recoverAfter:     time.Duration(component.RecoverAfterSeconds) * time.Second,
+		maxTransitions:   component.MaxTransitions,
+		transitionWindow: time.Duration(component.TransitionWindowMinutes) * time.Minute,
+	}
+	if d.maxTransitions == 0 || d.transitionWindow == 0 {
+		d.maxTransitions, d.transitionWindow = 0, 0
+	}
+	if *d == (damping{}) {
+		return nil
+	}
+	return d
+}
+
+// pendingChange is a damped status change waiting to be made
+type pendingChange struct {
+	status statuspagetypes.Status
+	since  time.Time
+}
+
+// settleAt returns when the pending change may be made, given the status it would replace and the times of
+// recent transitions
+func (d *damping) settleAt(pending pendingChange, current statuspagetypes.Status, transitions []time.Time) time.Time {
+	hold := d.recoverAfter
+	if pending.status.WorstWith(current) == pending.status {
+		hold = d.degradeAfter
+	}
+	at := pending.since.Add(hold)
+	if d.maxTransitions > 0 && len(transitions) >= d.maxTransitions {
+		// Wait until enough transitions have aged out of the window
+		if windowOpens := transitions[len(transitions)-d.maxTransitions].Add(d.transitionWindow); windowOpens.After(at) {
+			at = windowOpens
+		}
+	}
+	return at
+}
+
+// recentTransitions drops transitions that have aged out of the window, if there's a limit at all
+func (d *damping) recentTransitions(transitions []time.Time, now time.Time) []time.Time {
+	if d.maxTransitions == 0 {
+		return nil
+	}
+	var recent []time.Time
+	for _, transition := range transitions {
+		if now.Sub(transition) < d.transitionWindow {
+			recent = append(recent, transition)
+		}
+	}
+	return recent
+}
+
+// clearPending forgets any pending damped status change, along with the timer that would have made it
+func (c *ComponentState) clearPending() {
+	c.pending = nil
+	if c.settleTimer != nil {
+		c.settleTimer.Stop()
+		c.settleTimer = nil
+	}
+}
+
+// GetPendingStatus returns the status the component is waiting to take because of damping and when it
+// will, if there's such a change pending.
+func (c *ComponentState) GetPendingStatus() (statuspagetypes.Status, time.Time, bool) {
+	if c.damping == nil || c.pending == nil {
+		return 0, time.Time{}, false
+	}
+	return c.pending.status, c.damping.settleAt(*c.pending, c.desiredStatus, c.transitions), true
+}
+
+// SettleStatus makes any pending damped status change that's due by the given time.
+// The returned bool represents if the component's entire status changed.
+func (c *ComponentState) SettleStatus(now time.Time) bool {
+	return c.recalculateDesiredStatusAt(now)
+}
+
+// ScheduleSettle runs the function at the given time, replacing whatever was previously scheduled. The
+// function should call SettleStatus from within a State.UseComponent hook.
+func (c *ComponentState) ScheduleSettle(at time.Time, settle func()) {
+	if c.settleTimer != nil {
+		c.settleTimer.Stop()
+	}
+	c.settleTimer = time.AfterFunc(time.Until(at), settle)
+}
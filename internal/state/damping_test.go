@@ -0,0 +1,131 @@
+package state
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"testing"
+	"time"
+)
+
+func Test_newDamping(t *testing.T) {
+	tests := []struct {
+		name      string
+		component configuration.Component
+		want      *damping
+	}{
+		{
+			name:      "nothing configured",
+			component: configuration.Component{Name: "foo"},
+			want:      nil,
+		},
+		{
+			name:      "transition limit needs a window",
+			component: configuration.Component{Name: "foo", MaxTransitions: 3},
+			want:      nil,
+		},
+		{
+			name:      "hold times",
+			component: configuration.Component{Name: "foo", DegradeAfterSeconds: 60, RecoverAfterSeconds: 300},
+			want:      &damping{degradeAfter: time.Minute, recoverAfter: 5 * time.Minute},
+		},
+		{
+			name:      "transition limit",
+			component: configuration.Component{Name: "foo", MaxTransitions: 3, TransitionWindowMinutes: 10},
+			want:      &damping{maxTransitions: 3, transitionWindow: 10 * time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newDamping(tt.component)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("newDamping() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentState_damping(t *testing.T) {
+	type step struct {
+		// exactly one of these is used per step
+		logIncident   string
+		incidentState statuspagetypes.Status
+		resolve       string
+		settleAfter   time.Duration
+		// expected results
+		wantChanged bool
+		wantStatus  statuspagetypes.Status
+		wantPending bool
+	}
+	tests := []struct {
+		name    string
+		damping damping
+		steps   []step
+	}{
+		{
+			name:    "degrading is held",
+			damping: damping{degradeAfter: time.Minute},
+			steps: []step{
+				{logIncident: "foo", incidentState: statuspagetypes.MajorOutage, wantChanged: false, wantStatus: statuspagetypes.Operational, wantPending: true},
+				{settleAfter: 30 * time.Second, wantChanged: false, wantStatus: statuspagetypes.Operational, wantPending: true},
+				{settleAfter: 2 * time.Minute, wantChanged: true, wantStatus: statuspagetypes.MajorOutage},
+				{resolve: "foo", wantChanged: true, wantStatus: statuspagetypes.Operational},
+			},
+		},
+		{
+			name:    "recovering is held",
+			damping: damping{recoverAfter: time.Minute},
+			steps: []step{
+				{logIncident: "foo", incidentState: statuspagetypes.MajorOutage, wantChanged: true, wantStatus: statuspagetypes.MajorOutage},
+				{resolve: "foo", wantChanged: false, wantStatus: statuspagetypes.MajorOutage, wantPending: true},
+				{settleAfter: 2 * time.Minute, wantChanged: true, wantStatus: statuspagetypes.Operational},
+			},
+		},
+		{
+			name:    "blip is suppressed",
+			damping: damping{degradeAfter: time.Minute},
+			steps: []step{
+				{logIncident: "foo", incidentState: statuspagetypes.PartialOutage, wantChanged: false, wantStatus: statuspagetypes.Operational, wantPending: true},
+				{resolve: "foo", wantChanged: false, wantStatus: statuspagetypes.Operational},
+				{settleAfter: 2 * time.Minute, wantChanged: false, wantStatus: statuspagetypes.Operational},
+			},
+		},
+		{
+			name:    "transitions are limited",
+			damping: damping{maxTransitions: 2, transitionWindow: 10 * time.Minute},
+			steps: []step{
+				{logIncident: "foo", incidentState: statuspagetypes.MajorOutage, wantChanged: true, wantStatus: statuspagetypes.MajorOutage},
+				{resolve: "foo", wantChanged: true, wantStatus: statuspagetypes.Operational},
+				{logIncident: "bar", incidentState: statuspagetypes.MajorOutage, wantChanged: false, wantStatus: statuspagetypes.Operational, wantPending: true},
+				{settleAfter: 5 * time.Minute, wantChanged: false, wantStatus: statuspagetypes.Operational, wantPending: true},
+				{settleAfter: 11 * time.Minute, wantChanged: true, wantStatus: statuspagetypes.MajorOutage},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.damping
+			c := &ComponentState{openIncidents: map[string]statuspagetypes.Status{}, damping: &d}
+			start := time.Now()
+			for i, s := range tt.steps {
+				var changed bool
+				switch {
+				case s.logIncident != "":
+					changed = c.LogIncident(s.logIncident, s.incidentState)
+				case s.resolve != "":
+					changed = c.ResolveIncident(s.resolve)
+				default:
+					changed = c.SettleStatus(start.Add(s.settleAfter))
+				}
+				if changed != s.wantChanged {
+					t.Errorf("step %d changed = %v, want %v", i, changed, s.wantChanged)
+				}
+				if got := c.GetDesiredStatus(); got != s.wantStatus {
+					t.Errorf("step %d GetDesiredStatus() = %v, want %v", i, got, s.wantStatus)
+				}
+				if _, _, pending := c.GetPendingStatus(); pending != s.wantPending {
+					t.Errorf("step %d pending = %v, want %v", i, pending, s.wantPending)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package state
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+)
+
+// escalation raises a component's status past that of its worst open incident when enough are open at once,
+// see configuration.Escalation
+type escalation struct {
+	minIncidents    int
+	minServices     int
+	countingAtLeast statuspagetypes.Status
+	escalateTo      statuspagetypes.Status
+}
+
+// newEscalations reads the component's escalations, which were already validated with the rest of the
+// configuration
+func newEscalations(component configuration.Component) []escalation {
+	var escalations []escalation
+	for _, configEscalation := range component.Escalations {
+		e := escalation{
+			minIncidents:    configEscalation.MinIncidents,
+			minServices:     configEscalation.MinServices,
+			countingAtLeast: statuspagetypes.DegradedPerformance,
+		}
+		e.escalateTo, _ = statuspagetypes.StatusFromKebabCase(configEscalation.EscalateTo)
+		if configEscalation.CountingAtLeast != "" {
+			e.countingAtLeast, _ = statuspagetypes.StatusFromKebabCase(configEscalation.CountingAtLeast)
+		}
+		escalations = append(escalations, e)
+	}
+	return escalations
+}
+
+// escalatedStatus returns the worst status the component's escalations give based on its open incidents, or
+// Operational if none apply. The InheritedIncidentID incident isn't counted and incidents without a known
+// service don't count towards distinct services.
+func (c *ComponentState) escalatedStatus() statuspagetypes.Status {
+	escalatedStatus := statuspagetypes.Operational
+	for _, e := range c.escalations {
+		incidents := 0
+		services := make(map[string]struct{})
+		for incidentID, status := range c.openIncidents {
+			if incidentID == InheritedIncidentID || status < e.countingAtLeast {
+				continue
+			}
+			incidents++
+			if service, found := c.incidentServices[incidentID]; found {
+				services[service] = struct{}{}
+			}
+		}
+		if (e.minIncidents > 0 && incidents >= e.minIncidents) || (e.minServices > 0 && len(services) >= e.minServices) {
+			escalatedStatus = escalatedStatus.WorstWith(e.escalateTo)
+		}
+	}
+	return escalatedStatus
+}
@@ -0,0 +1,105 @@
+package state
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func Test_newEscalations(t *testing.T) {
+	component := configuration.Component{Name: "foo", Escalations: []configuration.Escalation{
+		{MinIncidents: 3, EscalateTo: "partial-outage"},
+		{MinServices: 2, CountingAtLeast: "partial-outage", EscalateTo: "major-outage"},
+	}}
+	want := []escalation{
+		{minIncidents: 3, countingAtLeast: statuspagetypes.DegradedPerformance, escalateTo: statuspagetypes.PartialOutage},
+		{minServices: 2, countingAtLeast: statuspagetypes.PartialOutage, escalateTo: statuspagetypes.MajorOutage},
+	}
+	if diff := cmp.Diff(want, newEscalations(component), cmp.AllowUnexported(escalation{})); diff != "" {
+		t.Errorf("newEscalations() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestComponentState_escalations(t *testing.T) {
+	escalations := []escalation{
+		{minIncidents: 3, countingAtLeast: statuspagetypes.DegradedPerformance, escalateTo: statuspagetypes.PartialOutage},
+		{minServices: 2, countingAtLeast: statuspagetypes.PartialOutage, escalateTo: statuspagetypes.MajorOutage},
+	}
+	type step struct {
+		// exactly one of these is used per step
+		logIncident   string
+		service       string
+		incidentState statuspagetypes.Status
+		resolve       string
+		// expected results
+		wantChanged bool
+		wantStatus  statuspagetypes.Status
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "escalates by number of incidents",
+			steps: []step{
+				{logIncident: "a", service: "sam", incidentState: statuspagetypes.DegradedPerformance, wantChanged: true, wantStatus: statuspagetypes.DegradedPerformance},
+				{logIncident: "b", service: "sam", incidentState: statuspagetypes.DegradedPerformance, wantChanged: false, wantStatus: statuspagetypes.DegradedPerformance},
+				{logIncident: "c", service: "sam", incidentState: statuspagetypes.DegradedPerformance, wantChanged: true, wantStatus: statuspagetypes.PartialOutage},
+				{resolve: "b", wantChanged: true, wantStatus: statuspagetypes.DegradedPerformance},
+			},
+		},
+		{
+			name: "escalates by number of services",
+			steps: []step{
+				{logIncident: "a", service: "sam", incidentState: statuspagetypes.PartialOutage, wantChanged: true, wantStatus: statuspagetypes.PartialOutage},
+				{logIncident: "b", service: "sam", incidentState: statuspagetypes.PartialOutage, wantChanged: false, wantStatus: statuspagetypes.PartialOutage},
+				{logIncident: "c", service: "rawls", incidentState: statuspagetypes.PartialOutage, wantChanged: true, wantStatus: statuspagetypes.MajorOutage},
+				{resolve: "c", wantChanged: true, wantStatus: statuspagetypes.PartialOutage},
+			},
+		},
+		{
+			name: "doesn't count incidents without a service as a service",
+			steps: []step{
+				{logIncident: "a", service: "sam", incidentState: statuspagetypes.PartialOutage, wantChanged: true, wantStatus: statuspagetypes.PartialOutage},
+				{logIncident: "b", service: "", incidentState: statuspagetypes.PartialOutage, wantChanged: false, wantStatus: statuspagetypes.PartialOutage},
+			},
+		},
+		{
+			name: "only counts bad enough incidents",
+			steps: []step{
+				{logIncident: "a", service: "sam", incidentState: statuspagetypes.PartialOutage, wantChanged: true, wantStatus: statuspagetypes.PartialOutage},
+				{logIncident: "b", service: "rawls", incidentState: statuspagetypes.DegradedPerformance, wantChanged: false, wantStatus: statuspagetypes.PartialOutage},
+			},
+		},
+		{
+			name: "incidents without a service don't count as services",
+			steps: []step{
+				{logIncident: "a", incidentState: statuspagetypes.PartialOutage, wantChanged: true, wantStatus: statuspagetypes.PartialOutage},
+				{logIncident: "b", service: "rawls", incidentState: statuspagetypes.PartialOutage, wantChanged: false, wantStatus: statuspagetypes.PartialOutage},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ComponentState{openIncidents: map[string]statuspagetypes.Status{}, escalations: escalations}
+			for i, s := range tt.steps {
+				var changed bool
+				switch {
+				case s.resolve != "":
+					changed = c.ResolveIncident(s.resolve)
+				case s.service != "":
+					changed = c.LogServiceIncident(s.logIncident, s.service, s.incidentState)
+				default:
+					changed = c.LogIncident(s.logIncident, s.incidentState)
+				}
+				if changed != s.wantChanged {
+					t.Errorf("step %d changed = %v, want %v", i, changed, s.wantChanged)
+				}
+				if got := c.GetDesiredStatus(); got != s.wantStatus {
+					t.Errorf("step %d GetDesiredStatus() = %v, want %v", i, got, s.wantStatus)
+				}
+			}
+		})
+	}
+}
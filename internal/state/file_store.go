@@ -0,0 +1,104 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore persists open incidents, and the services they're for, to a single JSON file on the local disk.
+// The entire file is rewritten on each save, which is fine for the handful of
+// components and incidents Revere deals with at once.
+type FileStore struct {
+	path string
+	// componentNameToIncidents caches the file's contents, lazily read on first use
+	componentNameToIncidents map[string]StoredIncidents
+	lock                     *sync.Mutex
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+		lock: &sync.Mutex{},
+	}
+}
+
+// readIfNecessary populates the cache from the file if it hasn't been already; a missing file is empty.
+// Should only be called while holding the lock.
+func (f *FileStore) readIfNecessary() error {
+	if f.componentNameToIncidents != nil {
+		return nil
+	}
+	contents, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		f.componentNameToIncidents = map[string]StoredIncidents{}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read state file %s: %w", f.path, err)
+	}
+	var componentNameToIncidents map[string]StoredIncidents
+	if err := json.Unmarshal(contents, &componentNameToIncidents); err != nil {
+		return fmt.Errorf("failed to parse state file %s: %w", f.path, err)
+	}
+	if componentNameToIncidents == nil {
+		componentNameToIncidents = map[string]StoredIncidents{}
+	}
+	f.componentNameToIncidents = componentNameToIncidents
+	return nil
+}
+
+// write replaces the file with the cache's contents. It writes to a temporary file and renames it
+// into place so that a crash mid-write can't leave a truncated file behind.
+// Should only be called while holding the lock.
+func (f *FileStore) write() error {
+	contents, err := json.MarshalIndent(f.componentNameToIncidents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize state: %w", err)
+	}
+	temporaryFile, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %w", err)
+	}
+	if _, err := temporaryFile.Write(contents); err != nil {
+		_ = temporaryFile.Close()
+		_ = os.Remove(temporaryFile.Name())
+		return fmt.Errorf("failed to write temporary state file %s: %w", temporaryFile.Name(), err)
+	}
+	if err := temporaryFile.Close(); err != nil {
+		_ = os.Remove(temporaryFile.Name())
+		return fmt.Errorf("failed to close temporary state file %s: %w", temporaryFile.Name(), err)
+	}
+	if err := os.Rename(temporaryFile.Name(), f.path); err != nil {
+		_ = os.Remove(temporaryFile.Name())
+		return fmt.Errorf("failed to replace state file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// Load is a part of Store, reading the file's contents
+func (f *FileStore) Load() (map[string]StoredIncidents, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.readIfNecessary(); err != nil {
+		return nil, err
+	}
+	return copyComponentIncidents(f.componentNameToIncidents), nil
+}
+
+// Save is a part of Store, rewriting the file to contain the new incidents
+func (f *FileStore) Save(componentName string, incidents StoredIncidents) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.readIfNecessary(); err != nil {
+		return err
+	}
+	if len(incidents.Statuses) == 0 {
+		delete(f.componentNameToIncidents, componentName)
+	} else {
+		f.componentNameToIncidents[componentName] = copyStoredIncidents(incidents)
+	}
+	return f.write()
+}
@@ -0,0 +1,106 @@
+package state
+
+import (
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileContents string
+		saves        map[string]StoredIncidents
+		want         map[string]StoredIncidents
+		wantFile     string
+		wantErr      bool
+	}{
+		{
+			name: "Missing file is empty",
+			want: map[string]StoredIncidents{},
+		},
+		{
+			name:         "Reads existing file",
+			fileContents: `{"foo": {"statuses": {"abc": "major-outage"}, "services": {"abc": "rawls"}}}`,
+			want: map[string]StoredIncidents{
+				"foo": {
+					Statuses: map[string]statuspagetypes.Status{"abc": statuspagetypes.MajorOutage},
+					Services: map[string]string{"abc": "rawls"},
+				},
+			},
+		},
+		{
+			name:         "Saves alongside existing file contents",
+			fileContents: `{"foo": {"statuses": {"abc": "major-outage"}}, "bar": {"statuses": {"def": "partial-outage"}}}`,
+			saves: map[string]StoredIncidents{
+				"foo": {},
+				"baz": {
+					Statuses: map[string]statuspagetypes.Status{"ghi": statuspagetypes.DegradedPerformance},
+					Services: map[string]string{"ghi": "sam"},
+				},
+			},
+			want: map[string]StoredIncidents{
+				"bar": {Statuses: map[string]statuspagetypes.Status{"def": statuspagetypes.PartialOutage}},
+				"baz": {
+					Statuses: map[string]statuspagetypes.Status{"ghi": statuspagetypes.DegradedPerformance},
+					Services: map[string]string{"ghi": "sam"},
+				},
+			},
+			wantFile: `{
+  "bar": {
+    "statuses": {
+      "def": "partial-outage"
+    }
+  },
+  "baz": {
+    "statuses": {
+      "ghi": "degraded-performance"
+    },
+    "services": {
+      "ghi": "sam"
+    }
+  }
+}`,
+		},
+		{
+			name:         "Errors on corrupt file",
+			fileContents: `{"foo": {"statuses": {"abc": "not-a-status"}}}`,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			if tt.fileContents != "" {
+				if err := os.WriteFile(path, []byte(tt.fileContents), 0644); err != nil {
+					t.Errorf("failed to write test file: %v", err)
+					return
+				}
+			}
+			store := NewFileStore(path)
+			for name, incidents := range tt.saves {
+				if err := store.Save(name, incidents); err != nil {
+					t.Errorf("Save() error %v", err)
+					return
+				}
+			}
+			// Use a new store to make sure we read from disk rather than the cache
+			got, err := NewFileStore(path).Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantFile != "" {
+				gotFile, _ := os.ReadFile(path)
+				if diff := cmp.Diff(tt.wantFile, string(gotFile)); diff != "" {
+					t.Errorf("file mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
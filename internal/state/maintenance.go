@@ -0,0 +1,96 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// MaintenanceWindow is a period of planned work during which the components it affects are shown as under
+// maintenance, taking precedence over their open incidents
+type MaintenanceWindow struct {
+	// Name identifies the window; only one window with a given name may be scheduled at a time
+	Name           string    `json:"name"`
+	Description    string    `json:"description,omitempty"`
+	ComponentNames []string  `json:"components"`
+	StartsAt       time.Time `json:"startsAt"`
+	EndsAt         time.Time `json:"endsAt"`
+	// StatuspageIncidentID is the ID of the scheduled incident announcing the window on Statuspage, if any
+	StatuspageIncidentID string `json:"statuspageIncidentId,omitempty"`
+}
+
+// MaintenanceHandler schedules the maintenance window with the given name, or cancels it if given nil.
+// See statuspage.MaintenanceUpdater for the handler that also updates Statuspage.
+type MaintenanceHandler func(name string, window *MaintenanceWindow) error
+
+// ActiveAt returns if the window is in progress at the given time
+func (w *MaintenanceWindow) ActiveAt(now time.Time) bool {
+	return !now.Before(w.StartsAt) && now.Before(w.EndsAt)
+}
+
+// Affects returns if the window includes the component
+func (w *MaintenanceWindow) Affects(componentName string) bool {
+	for _, name := range w.ComponentNames {
+		if name == componentName {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMaintenanceWindows returns copies of every scheduled maintenance window, sorted by start time and then name.
+func (s *State) GetMaintenanceWindows() []MaintenanceWindow {
+	s.maintenanceLock.Lock()
+	defer s.maintenanceLock.Unlock()
+	windows := make([]MaintenanceWindow, 0, len(s.maintenanceWindows))
+	for _, window := range s.maintenanceWindows {
+		windows = append(windows, window)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].StartsAt.Equal(windows[j].StartsAt) {
+			return windows[i].StartsAt.Before(windows[j].StartsAt)
+		}
+		return windows[i].Name < windows[j].Name
+	})
+	return windows
+}
+
+// GetMaintenanceWindow returns a copy of the maintenance window with the given name, if it's scheduled.
+func (s *State) GetMaintenanceWindow(name string) (MaintenanceWindow, bool) {
+	s.maintenanceLock.Lock()
+	defer s.maintenanceLock.Unlock()
+	window, found := s.maintenanceWindows[name]
+	return window, found
+}
+
+// PutMaintenanceWindow schedules the maintenance window, replacing any with the same name.
+// It doesn't change any component's status, see ComponentState.SetUnderMaintenance.
+func (s *State) PutMaintenanceWindow(window MaintenanceWindow) {
+	s.maintenanceLock.Lock()
+	defer s.maintenanceLock.Unlock()
+	if s.maintenanceWindows == nil {
+		s.maintenanceWindows = make(map[string]MaintenanceWindow)
+	}
+	s.maintenanceWindows[window.Name] = window
+}
+
+// RemoveMaintenanceWindow unschedules the maintenance window with the given name, returning it if it existed.
+func (s *State) RemoveMaintenanceWindow(name string) (MaintenanceWindow, bool) {
+	s.maintenanceLock.Lock()
+	defer s.maintenanceLock.Unlock()
+	window, found := s.maintenanceWindows[name]
+	delete(s.maintenanceWindows, name)
+	return window, found
+}
+
+// IsUnderMaintenance returns if the component is being shown as under maintenance.
+func (c *ComponentState) IsUnderMaintenance() bool {
+	return c.underMaintenance
+}
+
+// SetUnderMaintenance records if a maintenance window affecting the component is in progress. Open incidents
+// are still logged and resolved while it is, they just don't affect the component's status until it isn't.
+// The returned bool represents if the component's entire status changed.
+func (c *ComponentState) SetUnderMaintenance(underMaintenance bool) bool {
+	c.underMaintenance = underMaintenance
+	return c.recalculateDesiredStatus()
+}
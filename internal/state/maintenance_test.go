@@ -0,0 +1,57 @@
+package state
+
+import (
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestComponentState_SetUnderMaintenance(t *testing.T) {
+	c := &ComponentState{lock: &sync.Mutex{}, openIncidents: map[string]statuspagetypes.Status{}}
+	type step struct {
+		name        string
+		do          func() bool
+		wantChanged bool
+		wantStatus  statuspagetypes.Status
+	}
+	for _, s := range []step{
+		{name: "maintenance starts", do: func() bool { return c.SetUnderMaintenance(true) },
+			wantChanged: true, wantStatus: statuspagetypes.UnderMaintenance},
+		{name: "incidents are suppressed", do: func() bool { return c.LogIncident("foo", statuspagetypes.MajorOutage) },
+			wantChanged: false, wantStatus: statuspagetypes.UnderMaintenance},
+		{name: "overrides still win", do: func() bool { return c.SetOverride(Override{Status: statuspagetypes.Operational}) },
+			wantChanged: true, wantStatus: statuspagetypes.Operational},
+		{name: "override cleared", do: func() bool { return c.ClearOverride() },
+			wantChanged: true, wantStatus: statuspagetypes.UnderMaintenance},
+		{name: "maintenance ends", do: func() bool { return c.SetUnderMaintenance(false) },
+			wantChanged: true, wantStatus: statuspagetypes.MajorOutage},
+	} {
+		if changed := s.do(); changed != s.wantChanged {
+			t.Errorf("%s: changed %v, want %v", s.name, changed, s.wantChanged)
+		}
+		if status := c.GetDesiredStatus(); status != s.wantStatus {
+			t.Errorf("%s: status %s, want %s", s.name, status.ToString(), s.wantStatus.ToString())
+		}
+	}
+}
+
+func TestState_maintenanceWindows(t *testing.T) {
+	now := time.Now()
+	s := &State{}
+	s.PutMaintenanceWindow(MaintenanceWindow{Name: "later", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)})
+	s.PutMaintenanceWindow(MaintenanceWindow{Name: "sooner", StartsAt: now, EndsAt: now.Add(time.Hour)})
+	windows := s.GetMaintenanceWindows()
+	if len(windows) != 2 || windows[0].Name != "sooner" || windows[1].Name != "later" {
+		t.Errorf("GetMaintenanceWindows() = %+v, want sooner then later", windows)
+	}
+	if !windows[0].ActiveAt(now) || windows[1].ActiveAt(now) {
+		t.Errorf("only sooner should be active now")
+	}
+	if _, found := s.RemoveMaintenanceWindow("sooner"); !found {
+		t.Errorf("RemoveMaintenanceWindow() didn't find sooner")
+	}
+	if _, found := s.GetMaintenanceWindow("sooner"); found {
+		t.Errorf("GetMaintenanceWindow() found sooner after removal")
+	}
+}
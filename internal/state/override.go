@@ -0,0 +1,56 @@
+package state
+
+import (
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"time"
+)
+
+// Override is a status manually forced upon a component, taking precedence over its open incidents
+type Override struct {
+	Status statuspagetypes.Status `json:"status"`
+	// Reason is an optional human-readable explanation
+	Reason string `json:"reason,omitempty"`
+	// ExpiresAt is when the override stops applying; if nil it applies until cleared
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// OverrideHandler sets a component's override, or clears it if given nil.
+// See statuspage.OverrideUpdater for the handler that also updates Statuspage.
+type OverrideHandler func(componentName string, override *Override) error
+
+// activeAt returns if the override should apply at the given time
+func (o *Override) activeAt(now time.Time) bool {
+	return o.ExpiresAt == nil || now.Before(*o.ExpiresAt)
+}
+
+// GetOverride returns a copy of the component's override, or nil if there isn't one.
+func (c *ComponentState) GetOverride() *Override {
+	if c.override == nil {
+		return nil
+	}
+	override := *c.override
+	return &override
+}
+
+// SetOverride replaces the component's override, if any.
+// The returned bool represents if the component's entire status changed based on the override.
+func (c *ComponentState) SetOverride(override Override) bool {
+	c.override = &override
+	return c.recalculateDesiredStatus()
+}
+
+// ClearOverride removes the component's override so its status is derived from its open incidents again.
+// The returned bool represents if the component's entire status changed.
+func (c *ComponentState) ClearOverride() bool {
+	c.override = nil
+	return c.recalculateDesiredStatus()
+}
+
+// ExpireOverride clears the component's override if it expired by the given time.
+// The returned bool represents if the component's entire status changed.
+func (c *ComponentState) ExpireOverride(now time.Time) bool {
+	if c.override != nil && !c.override.activeAt(now) {
+		return c.ClearOverride()
+	}
+	return false
+}
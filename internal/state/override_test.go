@@ -0,0 +1,90 @@
+package state
+
+import (
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestComponentState_overrides(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	type step struct {
+		// exactly one of these is used per step
+		set           *Override
+		clear         bool
+		expireAt      *time.Time
+		logIncident   string
+		incidentState statuspagetypes.Status
+		// expected results
+		wantChanged bool
+		wantStatus  statuspagetypes.Status
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "override wins over incidents until cleared",
+			steps: []step{
+				{logIncident: "foo", incidentState: statuspagetypes.PartialOutage, wantChanged: true, wantStatus: statuspagetypes.PartialOutage},
+				{set: &Override{Status: statuspagetypes.Operational}, wantChanged: true, wantStatus: statuspagetypes.Operational},
+				{logIncident: "bar", incidentState: statuspagetypes.MajorOutage, wantChanged: false, wantStatus: statuspagetypes.Operational},
+				{clear: true, wantChanged: true, wantStatus: statuspagetypes.MajorOutage},
+			},
+		},
+		{
+			name: "override can force an outage",
+			steps: []step{
+				{set: &Override{Status: statuspagetypes.MajorOutage}, wantChanged: true, wantStatus: statuspagetypes.MajorOutage},
+				{set: &Override{Status: statuspagetypes.MajorOutage, Reason: "still broken"}, wantChanged: false, wantStatus: statuspagetypes.MajorOutage},
+				{clear: true, wantChanged: true, wantStatus: statuspagetypes.Operational},
+			},
+		},
+		{
+			name: "override expires",
+			steps: []step{
+				{set: &Override{Status: statuspagetypes.DegradedPerformance, ExpiresAt: &future}, wantChanged: true, wantStatus: statuspagetypes.DegradedPerformance},
+				{expireAt: &past, wantChanged: false, wantStatus: statuspagetypes.DegradedPerformance},
+				{expireAt: &future, wantChanged: true, wantStatus: statuspagetypes.Operational},
+			},
+		},
+		{
+			name: "expired override is ignored",
+			steps: []step{
+				{set: &Override{Status: statuspagetypes.MajorOutage, ExpiresAt: &past}, wantChanged: false, wantStatus: statuspagetypes.Operational},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ComponentState{openIncidents: map[string]statuspagetypes.Status{}}
+			for i, s := range tt.steps {
+				var changed bool
+				switch {
+				case s.set != nil:
+					changed = c.SetOverride(*s.set)
+					if diff := cmp.Diff(s.set, c.GetOverride()); diff != "" {
+						t.Errorf("step %d GetOverride() mismatch (-want +got):\n%s", i, diff)
+					}
+				case s.clear:
+					changed = c.ClearOverride()
+					if c.GetOverride() != nil {
+						t.Errorf("step %d GetOverride() = %v, want nil", i, c.GetOverride())
+					}
+				case s.expireAt != nil:
+					changed = c.ExpireOverride(*s.expireAt)
+				default:
+					changed = c.LogIncident(s.logIncident, s.incidentState)
+				}
+				if changed != s.wantChanged {
+					t.Errorf("step %d changed = %v, want %v", i, changed, s.wantChanged)
+				}
+				if got := c.GetDesiredStatus(); got != s.wantStatus {
+					t.Errorf("step %d GetDesiredStatus() = %v, want %v", i, got, s.wantStatus)
+				}
+			}
+		})
+	}
+}
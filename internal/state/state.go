@@ -2,51 +2,213 @@ package state
 
 import (
 	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/metrics"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"sort"
 	"sync"
+	"time"
 )
 
 // State contains information necessary for continuous operation that's derived throughout
 // the course of operation. Information not meeting that constraint should exist elsewhere
 // (like configuration.Config).
-// Right now, the only information meeting this criteria is per-component state.
+// Right now, the only information meeting this criteria is per-component state and the
+// scheduled maintenance windows.
 //
 // This object is responsible for making sure that concurrent users don't step on each
-// other.
+// other, and for persisting open incidents to its Store (if it has one) as they change.
 type State struct {
 	componentNameToState *sync.Map
+	config               *configuration.Config
+	store                Store
+	// maintenanceWindows are keyed by name and guarded by maintenanceLock, see MaintenanceWindow
+	maintenanceWindows map[string]MaintenanceWindow
+	maintenanceLock    sync.Mutex
+	// statusChangeHandler is notified when a component's desired status changes, see OnStatusChange
+	statusChangeHandler StatusChangeHandler
 }
 
-// Seed the State with the component ID information obtained from Statuspage.
-func (s *State) Seed(componentNamesToIDs map[string]string) {
+// StatusTransition describes a change to a component's desired status
+type StatusTransition struct {
+	ComponentName string
+	OldStatus     statuspagetypes.Status
+	NewStatus     statuspagetypes.Status
+	// Incidents are the component's open incidents after the change, which contributed to its new status
+	Incidents []OpenIncident
+	// Cause is the alert that made the change, if known; overrides and maintenance give stand-in alerts
+	Cause *events.AlertEvent
+	At    time.Time
+}
+
+// StatusChangeHandler is notified of changes to a component's desired status. It's called from within
+// State.UseComponent, so it must not block or use the component itself.
+// See outputs.FanOut for the handler that communicates changes to each of Revere's outputs.
+type StatusChangeHandler func(transition StatusTransition)
+
+// OnStatusChange sets the handler to notify when any component's desired status changes. It should be called
+// before the State is used concurrently.
+func (s *State) OnStatusChange(handler StatusChangeHandler) {
+	s.statusChangeHandler = handler
+}
+
+// NewState creates a State that behaves according to the config and persists open incidents
+// to the given Store. The zero value of State is also usable, it just doesn't persist anything
+// and behaves according to the default configuration.
+func NewState(config *configuration.Config, store Store) *State {
+	return &State{config: config, store: store}
+}
+
+// inheritedStatusPolicy reads the policy from the config if there is one
+func (s *State) inheritedStatusPolicy() string {
+	if s.config == nil || s.config.InheritedStatus.Policy == "" {
+		return "keep"
+	}
+	return s.config.InheritedStatus.Policy
+}
+
+// componentConfig finds the component's configuration, if there is any
+func (s *State) componentConfig(componentName string) (configuration.Component, bool) {
+	if s.config != nil {
+		for _, component := range s.config.Statuspage.Components {
+			if component.Name == componentName {
+				return component, true
+			}
+		}
+	}
+	return configuration.Component{}, false
+}
+
+// Seed the State with the component information obtained from Statuspage.
+// Components seen for the first time have their open incidents, and the services those are
+// for, restored from the Store. If there are none but the component isn't operational on
+// Statuspage, an incident with InheritedIncidentID (and "statuspage" as its source) is
+// synthesized to hold that status, unless the inherited status policy is "reset". Components
+// under maintenance on Statuspage aren't given such an incident, since Revere re-derives
+// maintenance from its windows.
+func (s *State) Seed(remoteComponents []statuspagetypes.Component) error {
 	if s.componentNameToState == nil {
 		s.componentNameToState = &sync.Map{}
 	}
-	for name, id := range componentNamesToIDs {
-		uncastedComponentState, _ := s.componentNameToState.LoadOrStore(name, &ComponentState{
-			lock:          &sync.Mutex{},
-			openIncidents: map[string]statuspagetypes.Status{},
+	var storedIncidents map[string]StoredIncidents
+	if s.store != nil {
+		var err error
+		if storedIncidents, err = s.store.Load(); err != nil {
+			return fmt.Errorf("failed to load stored incidents: %w", err)
+		}
+	}
+	policy := s.inheritedStatusPolicy()
+	for _, remoteComponent := range remoteComponents {
+		remoteStatus, err := statuspagetypes.StatusFromSnakeCase(remoteComponent.Status)
+		if err != nil {
+			return fmt.Errorf("failed to read status of component %s: %w", remoteComponent.Name, err)
+		}
+		uncastedComponentState, loaded := s.componentNameToState.LoadOrStore(remoteComponent.Name, &ComponentState{
+			lock:                  &sync.Mutex{},
+			openIncidents:         map[string]statuspagetypes.Status{},
+			inheritedStatusPolicy: policy,
 		})
 		componentState := uncastedComponentState.(*ComponentState)
 		componentState.lock.Lock()
-		componentState.id = id
+		componentState.id = remoteComponent.ID
+		if !loaded {
+			if incidents, found := storedIncidents[remoteComponent.Name]; found && len(incidents.Statuses) > 0 {
+				componentState.openIncidents = copyIncidents(incidents.Statuses)
+				componentState.incidentServices = copyServices(incidents.Services)
+			} else if remoteStatus != statuspagetypes.Operational && remoteStatus != statuspagetypes.UnderMaintenance &&
+				policy != "reset" {
+				componentState.openIncidents[InheritedIncidentID] = remoteStatus
+				componentState.incidentsChanged = true
+				componentState.DescribeIncident(InheritedIncidentID, "statuspage", time.Now().UTC())
+			}
+			componentConfig, configured := s.componentConfig(remoteComponent.Name)
+			if configured {
+				componentState.escalations = newEscalations(componentConfig)
+			}
+			componentState.recalculateDesiredStatus()
+			// Damping only applies to changes from here on, not to restoring what was already known
+			if configured {
+				componentState.damping = newDamping(componentConfig)
+			}
+			metrics.SetComponentStatus(remoteComponent.Name, componentState.desiredStatus)
+		}
 		componentState.lock.Unlock()
 	}
+	return nil
+}
+
+// ComponentNames lists the names of every component in the State, sorted.
+func (s *State) ComponentNames() []string {
+	var names []string
+	if s.componentNameToState != nil {
+		s.componentNameToState.Range(func(key, _ interface{}) bool {
+			names = append(names, key.(string))
+			return true
+		})
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasComponent returns if the State knows of a component with the given name.
+func (s *State) HasComponent(componentName string) bool {
+	if s.componentNameToState == nil {
+		return false
+	}
+	_, found := s.componentNameToState.Load(componentName)
+	return found
 }
 
 // UseComponent runs a hook function with the state of some component. This function should
 // ensure that hooks never run simultaneously against the same component so long as callers
 // never copy the reference to the ComponentState object.
+// If the hook changed the component's open incidents, they are persisted before returning,
+// even if the hook errored (in-memory state has already changed by then). Likewise, changes
+// to the component's desired status are recorded in Revere's metrics.
 //
 // For more explanation, see the usage of this function in statuspage.StatusUpdater()
 func (s *State) UseComponent(componentName string, hook func(c *ComponentState) error) error {
+	if s.componentNameToState == nil {
+		return fmt.Errorf("did not find component named %s, state was never seeded", componentName)
+	}
 	uncastedComponentState, found := s.componentNameToState.Load(componentName)
 	if !found {
 		return fmt.Errorf("did not find component named %s", componentName)
 	}
 	componentState := uncastedComponentState.(*ComponentState)
 	componentState.lock.Lock()
+	defer componentState.lock.Unlock()
+	previousStatus := componentState.desiredStatus
+	componentState.cause = nil
 	err := hook(componentState)
-	componentState.lock.Unlock()
+	if componentState.desiredStatus != previousStatus {
+		metrics.RecordComponentTransition(componentName, previousStatus, componentState.desiredStatus)
+		if s.statusChangeHandler != nil {
+			s.statusChangeHandler(StatusTransition{
+				ComponentName: componentName,
+				OldStatus:     previousStatus,
+				NewStatus:     componentState.desiredStatus,
+				Incidents:     componentState.GetOpenIncidents(),
+				Cause:         componentState.cause,
+				At:            time.Now().UTC(),
+			})
+		}
+	}
+	if componentState.incidentsChanged && s.store != nil {
+		storeErr := s.store.Save(componentName, StoredIncidents{
+			Statuses: componentState.openIncidents,
+			Services: componentState.incidentServices,
+		})
+		if storeErr != nil {
+			if err == nil {
+				err = fmt.Errorf("failed to persist incidents for %s: %w", componentName, storeErr)
+			} else {
+				err = fmt.Errorf("%v (and failed to persist incidents for %s: %w)", err, componentName, storeErr)
+			}
+		} else {
+			componentState.incidentsChanged = false
+		}
+	}
 	return err
 }
@@ -1,16 +1,30 @@
 package state
 
 import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
 	"testing"
 )
 
+// operationalComponents makes operational Statuspage components from a map of names to IDs
+func operationalComponents(componentNamesToIDs map[string]string) []statuspagetypes.Component {
+	var components []statuspagetypes.Component
+	for name, id := range componentNamesToIDs {
+		components = append(components, statuspagetypes.Component{Name: name, ID: id, Status: "operational"})
+	}
+	return components
+}
+
 func dummyState() *State {
 	s := &State{}
-	s.Seed(map[string]string{
+	_ = s.Seed(operationalComponents(map[string]string{
 		"foo": "foo-id",
 		"bar": "bar-id",
 		"baz": "baz-id",
-	})
+	}))
 	return s
 }
 
@@ -64,7 +78,10 @@ func TestInMemoryComponentState_GetID(t *testing.T) {
 			} else {
 				s = &State{}
 			}
-			s.Seed(tt.seed)
+			if err := s.Seed(operationalComponents(tt.seed)); err != nil {
+				t.Errorf("Seed() error %v", err)
+				return
+			}
 			var got string
 			err := s.UseComponent(tt.wantName, func(c *ComponentState) error {
 				got = c.GetID()
@@ -80,3 +97,305 @@ func TestInMemoryComponentState_GetID(t *testing.T) {
 		})
 	}
 }
+
+func TestState_Seed_restoresFromStore(t *testing.T) {
+	tests := []struct {
+		name              string
+		stored            map[string]map[string]statuspagetypes.Status
+		seed              map[string]string
+		wantName          string
+		wantIncidents     map[string]statuspagetypes.Status
+		wantDesiredStatus statuspagetypes.Status
+	}{
+		{
+			name: "Restores stored incidents",
+			stored: map[string]map[string]statuspagetypes.Status{
+				"foo": {"abc": statuspagetypes.PartialOutage, "def": statuspagetypes.DegradedPerformance},
+			},
+			seed:              map[string]string{"foo": "foo-id"},
+			wantName:          "foo",
+			wantIncidents:     map[string]statuspagetypes.Status{"abc": statuspagetypes.PartialOutage, "def": statuspagetypes.DegradedPerformance},
+			wantDesiredStatus: statuspagetypes.PartialOutage,
+		},
+		{
+			name: "Components without stored incidents are operational",
+			stored: map[string]map[string]statuspagetypes.Status{
+				"bar": {"abc": statuspagetypes.PartialOutage},
+			},
+			seed:              map[string]string{"foo": "foo-id", "bar": "bar-id"},
+			wantName:          "foo",
+			wantIncidents:     map[string]statuspagetypes.Status{},
+			wantDesiredStatus: statuspagetypes.Operational,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			for name, incidents := range tt.stored {
+				_ = store.Save(name, StoredIncidents{Statuses: incidents})
+			}
+			s := NewState(nil, store)
+			if err := s.Seed(operationalComponents(tt.seed)); err != nil {
+				t.Errorf("Seed() error %v", err)
+				return
+			}
+			err := s.UseComponent(tt.wantName, func(c *ComponentState) error {
+				if diff := cmp.Diff(tt.wantIncidents, c.openIncidents); diff != "" {
+					t.Errorf("Seed() incidents mismatch (-want +got):\n%s", diff)
+				}
+				if c.GetDesiredStatus() != tt.wantDesiredStatus {
+					t.Errorf("Seed() desired status %s, want %s", c.GetDesiredStatus().ToString(), tt.wantDesiredStatus.ToString())
+				}
+				return nil
+			})
+			if err != nil {
+				t.Errorf("UseComponent() error %v", err)
+			}
+		})
+	}
+}
+
+func TestState_Seed_restoresServices(t *testing.T) {
+	store := NewMemoryStore()
+	_ = store.Save("foo", StoredIncidents{
+		Statuses: map[string]statuspagetypes.Status{"abc": statuspagetypes.PartialOutage},
+		Services: map[string]string{"abc": "rawls"},
+	})
+	s := NewState(nil, store)
+	if err := s.Seed(operationalComponents(map[string]string{"foo": "foo-id"})); err != nil {
+		t.Errorf("Seed() error %v", err)
+		return
+	}
+	err := s.UseComponent("foo", func(c *ComponentState) error {
+		if diff := cmp.Diff(map[string]string{"abc": "rawls"}, c.incidentServices); diff != "" {
+			t.Errorf("Seed() services mismatch (-want +got):\n%s", diff)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("UseComponent() error %v", err)
+	}
+}
+
+func TestState_UseComponent_persists(t *testing.T) {
+	tests := []struct {
+		name       string
+		hook       func(c *ComponentState) error
+		wantStored map[string]StoredIncidents
+		wantErr    bool
+	}{
+		{
+			name: "Persists logged incidents",
+			hook: func(c *ComponentState) error {
+				c.LogIncident("abc", statuspagetypes.MajorOutage)
+				return nil
+			},
+			wantStored: map[string]StoredIncidents{
+				"foo": {Statuses: map[string]statuspagetypes.Status{"abc": statuspagetypes.MajorOutage}},
+			},
+		},
+		{
+			name: "Persists resolved incidents",
+			hook: func(c *ComponentState) error {
+				c.LogIncident("abc", statuspagetypes.MajorOutage)
+				c.LogIncident("def", statuspagetypes.MajorOutage)
+				c.ResolveIncident("abc")
+				return nil
+			},
+			wantStored: map[string]StoredIncidents{
+				"foo": {Statuses: map[string]statuspagetypes.Status{"def": statuspagetypes.MajorOutage}},
+			},
+		},
+		{
+			name: "Persists the services of incidents",
+			hook: func(c *ComponentState) error {
+				c.LogServiceIncident("abc", "rawls", statuspagetypes.MajorOutage)
+				c.LogServiceIncident("def", "", statuspagetypes.MajorOutage)
+				return nil
+			},
+			wantStored: map[string]StoredIncidents{
+				"foo": {
+					Statuses: map[string]statuspagetypes.Status{"abc": statuspagetypes.MajorOutage, "def": statuspagetypes.MajorOutage},
+					Services: map[string]string{"abc": "rawls"},
+				},
+			},
+		},
+		{
+			name: "Persists even when the hook errors",
+			hook: func(c *ComponentState) error {
+				c.LogIncident("abc", statuspagetypes.MajorOutage)
+				return fmt.Errorf("some error")
+			},
+			wantStored: map[string]StoredIncidents{
+				"foo": {Statuses: map[string]statuspagetypes.Status{"abc": statuspagetypes.MajorOutage}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Doesn't persist without changes",
+			hook: func(c *ComponentState) error {
+				c.ResolveIncident("abc")
+				return nil
+			},
+			wantStored: map[string]StoredIncidents{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			s := NewState(nil, store)
+			if err := s.Seed(operationalComponents(map[string]string{"foo": "foo-id", "bar": "bar-id"})); err != nil {
+				t.Errorf("Seed() error %v", err)
+				return
+			}
+			if err := s.UseComponent("foo", tt.hook); (err != nil) != tt.wantErr {
+				t.Errorf("UseComponent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got, _ := store.Load()
+			if diff := cmp.Diff(tt.wantStored, got); diff != "" {
+				t.Errorf("UseComponent() stored mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestState_Seed_inheritsRemoteStatus(t *testing.T) {
+	tests := []struct {
+		name              string
+		policy            string
+		stored            map[string]map[string]statuspagetypes.Status
+		remoteStatus      string
+		wantIncidents     map[string]statuspagetypes.Status
+		wantDesiredStatus statuspagetypes.Status
+		wantErr           bool
+	}{
+		{
+			name:              "Operational components inherit nothing",
+			policy:            "keep",
+			remoteStatus:      "operational",
+			wantIncidents:     map[string]statuspagetypes.Status{},
+			wantDesiredStatus: statuspagetypes.Operational,
+		},
+		{
+			name:              "Keep inherits remote status",
+			policy:            "keep",
+			remoteStatus:      "partial_outage",
+			wantIncidents:     map[string]statuspagetypes.Status{InheritedIncidentID: statuspagetypes.PartialOutage},
+			wantDesiredStatus: statuspagetypes.PartialOutage,
+		},
+		{
+			name:              "Wait inherits remote status",
+			policy:            "wait",
+			remoteStatus:      "degraded_performance",
+			wantIncidents:     map[string]statuspagetypes.Status{InheritedIncidentID: statuspagetypes.DegradedPerformance},
+			wantDesiredStatus: statuspagetypes.DegradedPerformance,
+		},
+		{
+			name:              "Maintenance isn't inherited",
+			policy:            "keep",
+			remoteStatus:      "under_maintenance",
+			wantIncidents:     map[string]statuspagetypes.Status{},
+			wantDesiredStatus: statuspagetypes.Operational,
+		},
+		{
+			name:              "Reset inherits nothing",
+			policy:            "reset",
+			remoteStatus:      "partial_outage",
+			wantIncidents:     map[string]statuspagetypes.Status{},
+			wantDesiredStatus: statuspagetypes.Operational,
+		},
+		{
+			name:              "Stored incidents take precedence",
+			policy:            "keep",
+			stored:            map[string]map[string]statuspagetypes.Status{"foo": {"abc": statuspagetypes.DegradedPerformance}},
+			remoteStatus:      "major_outage",
+			wantIncidents:     map[string]statuspagetypes.Status{"abc": statuspagetypes.DegradedPerformance},
+			wantDesiredStatus: statuspagetypes.DegradedPerformance,
+		},
+		{
+			name:         "Errors on unknown remote status",
+			policy:       "keep",
+			remoteStatus: "on_fire",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configuration.Config{}
+			config.InheritedStatus.Policy = tt.policy
+			store := NewMemoryStore()
+			for name, incidents := range tt.stored {
+				_ = store.Save(name, StoredIncidents{Statuses: incidents})
+			}
+			s := NewState(config, store)
+			err := s.Seed([]statuspagetypes.Component{{Name: "foo", ID: "foo-id", Status: tt.remoteStatus}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Seed() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			err = s.UseComponent("foo", func(c *ComponentState) error {
+				if diff := cmp.Diff(tt.wantIncidents, c.openIncidents); diff != "" {
+					t.Errorf("Seed() incidents mismatch (-want +got):\n%s", diff)
+				}
+				if c.GetDesiredStatus() != tt.wantDesiredStatus {
+					t.Errorf("Seed() desired status %s, want %s", c.GetDesiredStatus().ToString(), tt.wantDesiredStatus.ToString())
+				}
+				return nil
+			})
+			if err != nil {
+				t.Errorf("UseComponent() error %v", err)
+			}
+		})
+	}
+}
+
+func TestState_ComponentNames(t *testing.T) {
+	if diff := cmp.Diff([]string{"bar", "baz", "foo"}, dummyState().ComponentNames()); diff != "" {
+		t.Errorf("ComponentNames() mismatch (-want +got):\n%s", diff)
+	}
+	if got := (&State{}).ComponentNames(); len(got) != 0 {
+		t.Errorf("ComponentNames() of empty state = %v, want none", got)
+	}
+}
+
+func TestState_OnStatusChange(t *testing.T) {
+	appState := &State{}
+	if err := appState.Seed([]statuspagetypes.Component{{Name: "foo", Status: "operational"}}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	var notified []string
+	appState.OnStatusChange(func(transition StatusTransition) {
+		cause := "unknown"
+		if transition.Cause != nil {
+			cause = transition.Cause.Name
+		}
+		notified = append(notified, fmt.Sprintf("%s %s->%s (%d incidents, caused by %s)", transition.ComponentName,
+			transition.OldStatus.ToSnakeCase(), transition.NewStatus.ToSnakeCase(), len(transition.Incidents), cause))
+	})
+	for _, status := range []statuspagetypes.Status{
+		statuspagetypes.MajorOutage, statuspagetypes.MajorOutage, statuspagetypes.PartialOutage,
+	} {
+		_ = appState.UseComponent("foo", func(c *ComponentState) error {
+			c.LogIncident("abc", status)
+			c.SetCause(&events.AlertEvent{Name: "Sam latency"})
+			return nil
+		})
+	}
+	// the cause only applies to the hook that set it
+	_ = appState.UseComponent("foo", func(c *ComponentState) error {
+		c.ResolveIncident("abc")
+		return nil
+	})
+	want := []string{
+		"foo operational->major_outage (1 incidents, caused by Sam latency)",
+		"foo major_outage->partial_outage (1 incidents, caused by Sam latency)",
+		"foo partial_outage->operational (0 incidents, caused by unknown)",
+	}
+	if diff := cmp.Diff(want, notified); diff != "" {
+		t.Errorf("OnStatusChange() notifications mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,103 @@
+package state
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"sync"
+)
+
+// StoredIncidents is what a Store keeps of a single component's open incidents
+type StoredIncidents struct {
+	// Statuses of the open incidents, keyed by incident ID
+	Statuses map[string]statuspagetypes.Status `json:"statuses"`
+	// Services the open incidents are for, keyed by incident ID, where known (see ComponentState.LogServiceIncident)
+	Services map[string]string `json:"services,omitempty"`
+}
+
+// Store persists each component's open incidents so that they survive restarts.
+// Implementations must be safe for concurrent use, since different components may save simultaneously.
+type Store interface {
+	// Load returns the open incidents of every component, keyed by component name
+	Load() (map[string]StoredIncidents, error)
+	// Save replaces the stored open incidents of a single component
+	Save(componentName string, incidents StoredIncidents) error
+}
+
+// NewStore creates the Store described by the configuration's Persistence.Backend.
+func NewStore(config *configuration.Config) (Store, error) {
+	switch config.Persistence.Backend {
+	case "memory", "":
+		return NewMemoryStore(), nil
+	case "file":
+		return NewFileStore(config.Persistence.FilePath), nil
+	}
+	return nil, fmt.Errorf("unknown persistence backend %s", config.Persistence.Backend)
+}
+
+// MemoryStore doesn't persist anything across restarts, but it does fulfill the Store interface
+// so that State doesn't need to special-case having no persistence.
+type MemoryStore struct {
+	componentNameToIncidents map[string]StoredIncidents
+	lock                     *sync.Mutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		componentNameToIncidents: map[string]StoredIncidents{},
+		lock:                     &sync.Mutex{},
+	}
+}
+
+// Load is a part of Store, returning a copy of what has been saved so far
+func (m *MemoryStore) Load() (map[string]StoredIncidents, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return copyComponentIncidents(m.componentNameToIncidents), nil
+}
+
+// Save is a part of Store, recording a copy of the incidents
+func (m *MemoryStore) Save(componentName string, incidents StoredIncidents) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.componentNameToIncidents[componentName] = copyStoredIncidents(incidents)
+	return nil
+}
+
+// copyIncidents makes a shallow copy of a map of incidents, so stores never share maps with ComponentState
+func copyIncidents(incidents map[string]statuspagetypes.Status) map[string]statuspagetypes.Status {
+	incidentsCopy := make(map[string]statuspagetypes.Status, len(incidents))
+	for id, status := range incidents {
+		incidentsCopy[id] = status
+	}
+	return incidentsCopy
+}
+
+// copyServices is like copyIncidents but for the services incidents are for, keeping nil as nil
+func copyServices(services map[string]string) map[string]string {
+	if services == nil {
+		return nil
+	}
+	servicesCopy := make(map[string]string, len(services))
+	for id, service := range services {
+		servicesCopy[id] = service
+	}
+	return servicesCopy
+}
+
+// copyStoredIncidents is like copyIncidents but for everything stored about a component's incidents
+func copyStoredIncidents(incidents StoredIncidents) StoredIncidents {
+	return StoredIncidents{
+		Statuses: copyIncidents(incidents.Statuses),
+		Services: copyServices(incidents.Services),
+	}
+}
+
+// copyComponentIncidents is like copyStoredIncidents but for the incidents of every component
+func copyComponentIncidents(componentNameToIncidents map[string]StoredIncidents) map[string]StoredIncidents {
+	componentsCopy := make(map[string]StoredIncidents, len(componentNameToIncidents))
+	for name, incidents := range componentNameToIncidents {
+		componentsCopy[name] = copyStoredIncidents(incidents)
+	}
+	return componentsCopy
+}
@@ -0,0 +1,34 @@
+package statuspage
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"time"
+)
+
+// scheduleDampedStatus sets a timer to publish the component's pending damped status change, if it has one,
+// once damping allows. The event is the alert that caused the change. It should be called from within a
+// state.State.UseComponent hook.
+func scheduleDampedStatus(config *configuration.Config, appState *state.State, componentName string,
+	c *state.ComponentState, event *events.AlertEvent) {
+	pendingStatus, at, pending := c.GetPendingStatus()
+	if !pending {
+		return
+	}
+	shared.LogLn(config, fmt.Sprintf("damping %s, holding %s until %s",
+		componentName, pendingStatus.ToSnakeCase(), at.Format(time.RFC3339)))
+	c.ScheduleSettle(at, func() {
+		err := appState.UseComponent(componentName, func(c *state.ComponentState) error {
+			componentStatusChanged := c.SettleStatus(time.Now())
+			publishDesiredStatus(config, c, event, componentStatusChanged, false)
+			scheduleDampedStatus(config, appState, componentName, c, event)
+			return nil
+		})
+		if err != nil {
+			shared.LogLn(config, fmt.Sprintf("failed to publish damped status of %s: %v", componentName, err))
+		}
+	})
+}
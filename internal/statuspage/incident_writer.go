@@ -0,0 +1,119 @@
+package statuspage
+
+import (
+	"context"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+	"time"
+)
+
+// IncidentWriter opens, updates, and resolves components' Statuspage incidents as requested with
+// state.ComponentState.RequestIncidentSync, outside of the appState's hooks. Like the StatusWriter, it runs whenever
+// a desired status changes and every StatusWriter.IntervalSeconds regardless, so that requests made without a status
+// change are handled and failed requests are retried. It's an outputs.Output.
+type IncidentWriter struct {
+	config   *configuration.Config
+	appState *state.State
+	client   *resty.Client
+	// templates are parsed once, when the IncidentWriter is created
+	templates *incidentTemplates
+	// wake has room for one pending request to write, so requests made while writing aren't lost
+	wake chan struct{}
+}
+
+func NewIncidentWriter(config *configuration.Config, appState *state.State, client *resty.Client) (*IncidentWriter, error) {
+	templates, err := parseIncidentTemplates(config)
+	if err != nil {
+		return nil, err
+	}
+	return &IncidentWriter{
+		config:    config,
+		appState:  appState,
+		client:    client,
+		templates: templates,
+		wake:      make(chan struct{}, 1),
+	}, nil
+}
+
+// Name identifies the IncidentWriter among Revere's outputs
+func (w *IncidentWriter) Name() string {
+	return "statuspage-incidents"
+}
+
+// Notify asks the IncidentWriter to write soon, without blocking. Every component's pending request is written,
+// not just the one that transitioned.
+func (w *IncidentWriter) Notify(state.StatusTransition) error {
+	w.Wake()
+	return nil
+}
+
+// Wake asks the IncidentWriter to write soon, without blocking
+func (w *IncidentWriter) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run writes whenever woken and every StatusWriter.IntervalSeconds until the context is cancelled. Failures are
+// logged and retried next time.
+func (w *IncidentWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(w.config.StatusWriter.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-ctx.Done():
+			return
+		}
+		if err := w.Write(); err != nil {
+			shared.LogLn(w.config, fmt.Sprintf("failed to write incidents to statuspage, will retry: %v", err))
+		}
+	}
+}
+
+// Write syncs the Statuspage incident of each component with a pending request. Every component is tried even
+// if some fail, and the first failure is returned.
+func (w *IncidentWriter) Write() error {
+	var firstErr error
+	for _, componentName := range w.appState.ComponentNames() {
+		if err := w.writeComponent(componentName); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// writeComponent takes the component's pending request, if any, and carries it out without holding the
+// component, recording the resulting incident afterwards. Failed requests are put back to be retried. Components
+// under maintenance are skipped, since their Statuspage scheduled incident already explains their status.
+func (w *IncidentWriter) writeComponent(componentName string) error {
+	var incidentSync *state.IncidentSync
+	var componentID, statuspageIncidentID string
+	var desiredStatus statuspagetypes.Status
+	err := w.appState.UseComponent(componentName, func(c *state.ComponentState) error {
+		incidentSync = c.TakeIncidentSync()
+		componentID = c.GetID()
+		statuspageIncidentID = c.GetStatuspageIncidentID()
+		desiredStatus = c.GetDesiredStatus()
+		return nil
+	})
+	if err != nil || incidentSync == nil || desiredStatus == statuspagetypes.UnderMaintenance {
+		return err
+	}
+	statuspageIncidentID, syncErr := syncStatuspageIncident(w.config, w.client, w.templates, componentName, componentID,
+		statuspageIncidentID, desiredStatus, incidentSync)
+	return w.appState.UseComponent(componentName, func(c *state.ComponentState) error {
+		if syncErr != nil {
+			c.ReturnIncidentSync(incidentSync)
+			return syncErr
+		}
+		c.SetStatuspageIncidentID(statuspageIncidentID)
+		return nil
+	})
+}
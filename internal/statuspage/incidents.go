@@ -0,0 +1,168 @@
+package statuspage
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+	"text/template"
+)
+
+// IncidentTemplateData is what the configuration's StatuspageIncidents templates are executed with.
+// Alert fields describe the alert that most recently affected the component.
+type IncidentTemplateData struct {
+	ComponentName string
+	// Human-readable status of the component, like "Major Outage"
+	Status        string
+	PolicyName    string
+	Summary       string
+	Documentation string
+	URL           string
+}
+
+// newIncidentTemplateData gathers template data from the component and the alert affecting it
+func newIncidentTemplateData(componentName string, desiredStatus statuspagetypes.Status, event *events.AlertEvent) IncidentTemplateData {
+	return IncidentTemplateData{
+		ComponentName: componentName,
+		Status:        desiredStatus.ToString(),
+		PolicyName:    event.Name,
+		Summary:       event.Summary,
+		Documentation: event.Documentation,
+		URL:           event.URL(),
+	}
+}
+
+// incidentTemplates are the configuration's StatuspageIncidents templates, parsed once up front
+type incidentTemplates struct {
+	title        *template.Template
+	body         *template.Template
+	resolvedBody *template.Template
+}
+
+func parseIncidentTemplates(config *configuration.Config) (*incidentTemplates, error) {
+	var parsed [3]*template.Template
+	for index, named := range [][2]string{
+		{"title", config.StatuspageIncidents.TitleTemplate},
+		{"body", config.StatuspageIncidents.BodyTemplate},
+		{"resolved body", config.StatuspageIncidents.ResolvedBodyTemplate},
+	} {
+		var err error
+		if parsed[index], err = template.New(named[0]).Parse(named[1]); err != nil {
+			return nil, fmt.Errorf("failed to parse %s template: %w", named[0], err)
+		}
+	}
+	return &incidentTemplates{title: parsed[0], body: parsed[1], resolvedBody: parsed[2]}, nil
+}
+
+// executeTemplate renders one of the parsed templates
+func executeTemplate(parsed *template.Template, data IncidentTemplateData) (string, error) {
+	var buffer bytes.Buffer
+	if err := parsed.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", parsed.Name(), err)
+	}
+	return buffer.String(), nil
+}
+
+// syncStatuspageIncident opens, updates, or resolves the component's Statuspage incident based on its desired
+// status, as requested by incidentSync, returning the ID of the incident left open, if any. It makes requests to
+// Statuspage, so it shouldn't be called from within a state.State.UseComponent hook; see IncidentWriter.
+// Incidents list the component as affected with its desired status, the same status the StatusWriter sets, so that
+// Statuspage shows the incident's impact on the component even before the StatusWriter next runs. The incident's own
+// status is only set when opening and resolving it, so that progress people record on Statuspage (like
+// "identified" or "monitoring") isn't overwritten by updates.
+func syncStatuspageIncident(config *configuration.Config, client *resty.Client, templates *incidentTemplates,
+	componentName string, componentID string, statuspageIncidentID string, desiredStatus statuspagetypes.Status,
+	incidentSync *state.IncidentSync) (string, error) {
+	data := newIncidentTemplateData(componentName, desiredStatus, incidentSync.Event)
+	request := statuspagetypes.RequestIncident{
+		ComponentIDs:         []string{componentID},
+		Components:           map[string]string{componentID: desiredStatus.ToSnakeCase()},
+		DeliverNotifications: config.StatuspageIncidents.DeliverNotifications,
+	}
+
+	switch {
+	case desiredStatus != statuspagetypes.Operational && statuspageIncidentID == "":
+		title, err := executeTemplate(templates.title, data)
+		if err != nil {
+			return statuspageIncidentID, err
+		}
+		body, err := executeTemplate(templates.body, data)
+		if err != nil {
+			return statuspageIncidentID, err
+		}
+		request.Name = title
+		request.Body = body
+		request.Status = "investigating"
+		request.Metadata = map[string]map[string]interface{}{
+			statuspagetypes.RevereMetadataKey: {"component_id": componentID},
+		}
+		shared.LogLn(config, fmt.Sprintf("opening statuspage incident for %s", componentName),
+			fmt.Sprintf(" - new: %+v", request))
+		created, err := statuspageapi.PostIncident(client, config.Statuspage.PageID, request)
+		if err != nil {
+			return statuspageIncidentID, err
+		}
+		return created.ID, nil
+
+	case desiredStatus == statuspagetypes.Operational && statuspageIncidentID != "":
+		body, err := executeTemplate(templates.resolvedBody, data)
+		if err != nil {
+			return statuspageIncidentID, err
+		}
+		request.Body = body
+		request.Status = "resolved"
+		shared.LogLn(config, fmt.Sprintf("resolving statuspage incident %s for %s", statuspageIncidentID, componentName),
+			fmt.Sprintf(" - update: %+v", request))
+		if _, err := statuspageapi.PatchIncident(client, config.Statuspage.PageID, statuspageIncidentID, request); err != nil {
+			return statuspageIncidentID, err
+		}
+		return "", nil
+
+	case statuspageIncidentID != "" && incidentSync.Update:
+		body, err := executeTemplate(templates.body, data)
+		if err != nil {
+			return statuspageIncidentID, err
+		}
+		request.Body = body
+		shared.LogLn(config, fmt.Sprintf("updating statuspage incident %s for %s", statuspageIncidentID, componentName),
+			fmt.Sprintf(" - update: %+v", request))
+		if _, err := statuspageapi.PatchIncident(client, config.Statuspage.PageID, statuspageIncidentID, request); err != nil {
+			return statuspageIncidentID, err
+		}
+	}
+	return statuspageIncidentID, nil
+}
+
+// AdoptUnresolvedIncidents finds unresolved Statuspage incidents that Revere opened before it last stopped,
+// recording them against their components so they're updated and resolved like any other. Incidents
+// opened by people are never touched.
+func AdoptUnresolvedIncidents(config *configuration.Config, appState *state.State, client *resty.Client) error {
+	unresolvedIncidents, err := statuspageapi.GetUnresolvedIncidents(client, config.Statuspage.PageID)
+	if err != nil {
+		return err
+	}
+	componentIDToIncidentID := make(map[string]string)
+	for _, incident := range *unresolvedIncidents {
+		if componentID, managed := incident.ManagedComponentID(); managed {
+			componentIDToIncidentID[componentID] = incident.ID
+		}
+	}
+	for _, componentName := range appState.ComponentNames() {
+		err := appState.UseComponent(componentName, func(c *state.ComponentState) error {
+			if incidentID, found := componentIDToIncidentID[c.GetID()]; found && c.GetStatuspageIncidentID() == "" {
+				shared.LogLn(config, fmt.Sprintf("adopting statuspage incident %s for %s", incidentID, componentName))
+				c.SetStatuspageIncidentID(incidentID)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	"testing"
+)
+
+func makeIncidentConfigHelper() *configuration.Config {
+	config := makeConfigHelper([]configuration.Component{{Name: "a component"}}, nil)
+	config.StatuspageIncidents.Enabled = true
+	config.StatuspageIncidents.TitleTemplate = "{{.ComponentName}}: {{.Status}}"
+	config.StatuspageIncidents.BodyTemplate = "{{.Status}} from {{.PolicyName}}{{if .Documentation}}: {{.Documentation}}{{end}}"
+	config.StatuspageIncidents.ResolvedBodyTemplate = "{{.ComponentName}} recovered"
+	return config
+}
+
+func TestStatusUpdater_statuspageIncidents(t *testing.T) {
+	type alert struct {
+		event *events.AlertEvent
+	}
+	tests := []struct {
+		name          string
+		alerts        []alert
+		wantIncidents map[string]statuspagetypes.Incident
+	}{
+		{
+			name: "opens incident when no longer operational",
+			alerts: []alert{
+				{event: &events.AlertEvent{Status: statuspagetypes.MajorOutage,
+					IncidentID: "a", Name: "A policy",
+					Documentation: "Oh no",
+				}},
+			},
+			wantIncidents: map[string]statuspagetypes.Incident{
+				"1": {
+					ID: "1", PageID: "bar", Name: "a component: Major Outage", Status: "investigating",
+					Components: []statuspagetypes.Component{{ID: "a-component-id", Status: "major_outage"}},
+					IncidentUpdates: []statuspagetypes.IncidentUpdate{
+						{Status: "investigating", Body: "Major Outage from A policy: Oh no"},
+					},
+					Metadata: map[string]map[string]interface{}{"revere": {"component_id": "a-component-id"}},
+				},
+			},
+		},
+		{
+			name: "updates incident as alerts arrive and resolves it",
+			alerts: []alert{
+				{event: &events.AlertEvent{Status: statuspagetypes.DegradedPerformance,
+					IncidentID: "a", Name: "A policy",
+				}},
+				// a repeated alert shouldn't post an update
+				{event: &events.AlertEvent{Status: statuspagetypes.DegradedPerformance,
+					IncidentID: "a", Name: "A policy",
+				}},
+				{event: &events.AlertEvent{Status: statuspagetypes.DegradedPerformance,
+					IncidentID: "b", Name: "B policy",
+				}},
+				{event: &events.AlertEvent{Status: statuspagetypes.PartialOutage,
+					IncidentID: "c", Name: "C policy",
+				}},
+				{event: &events.AlertEvent{Status: statuspagetypes.PartialOutage,
+					IncidentID: "c", Ended: true, Name: "C policy",
+				}},
+				// resolving an alert without changing the status shouldn't post an update
+				{event: &events.AlertEvent{Status: statuspagetypes.DegradedPerformance,
+					IncidentID: "a", Ended: true, Name: "A policy",
+				}},
+				{event: &events.AlertEvent{Status: statuspagetypes.DegradedPerformance,
+					IncidentID: "b", Ended: true, Name: "B policy",
+				}},
+			},
+			wantIncidents: map[string]statuspagetypes.Incident{
+				"1": {
+					ID: "1", PageID: "bar", Name: "a component: Degraded Performance", Status: "resolved",
+					Components: []statuspagetypes.Component{{ID: "a-component-id", Status: "operational"}},
+					IncidentUpdates: []statuspagetypes.IncidentUpdate{
+						{Status: "investigating", Body: "Degraded Performance from A policy"},
+						{Status: "investigating", Body: "Degraded Performance from B policy"},
+						{Status: "investigating", Body: "Partial Outage from C policy"},
+						{Status: "investigating", Body: "Degraded Performance from C policy"},
+						{Status: "resolved", Body: "a component recovered"},
+					},
+					Metadata: map[string]map[string]interface{}{"revere": {"component_id": "a-component-id"}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := makeIncidentConfigHelper()
+			appState := &state.State{}
+			if err := appState.Seed([]statuspagetypes.Component{{Name: "a component", ID: "a-component-id", Status: "operational"}}); err != nil {
+				t.Errorf("unexpected Seed error %v", err)
+				return
+			}
+			client := statuspageapi.Client(config)
+			httpmock.ActivateNonDefault(client.GetClient())
+			statuspagemocks.ConfigureComponentMock(config, map[string]statuspagetypes.Component{
+				"a-component-id": {Name: "a component", ID: "a-component-id", Status: "operational"},
+			})
+			incidents := map[string]statuspagetypes.Incident{}
+			statuspagemocks.ConfigureIncidentMock(config, incidents)
+			callback := StatusUpdater(config, appState)
+			incidentWriter, err := NewIncidentWriter(config, appState, client)
+			if err != nil {
+				t.Fatalf("NewIncidentWriter() error %v", err)
+			}
+			for _, a := range tt.alerts {
+				if err := callback("a component", a.event); err != nil {
+					t.Errorf("callback error %v", err)
+				}
+				if err := incidentWriter.Write(); err != nil {
+					t.Errorf("IncidentWriter.Write() error %v", err)
+				}
+			}
+			httpmock.DeactivateAndReset()
+			if diff := cmp.Diff(tt.wantIncidents, incidents); diff != "" {
+				t.Errorf("incidents mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIncidentWriter_Write_retries(t *testing.T) {
+	config := makeIncidentConfigHelper()
+	appState := &state.State{}
+	if err := appState.Seed([]statuspagetypes.Component{{Name: "a component", ID: "a-component-id", Status: "operational"}}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	client := statuspageapi.Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	err := StatusUpdater(config, appState)("a component",
+		&events.AlertEvent{Status: statuspagetypes.MajorOutage, IncidentID: "a", Name: "A policy"})
+	if err != nil {
+		t.Errorf("StatusUpdater() error %v", err)
+	}
+	incidentWriter, err := NewIncidentWriter(config, appState, client)
+	if err != nil {
+		t.Fatalf("NewIncidentWriter() error %v", err)
+	}
+	// Statuspage isn't responding yet
+	if err := incidentWriter.Write(); err == nil {
+		t.Errorf("IncidentWriter.Write() expected error")
+	}
+	incidents := map[string]statuspagetypes.Incident{}
+	statuspagemocks.ConfigureIncidentMock(config, incidents)
+	if err := incidentWriter.Write(); err != nil {
+		t.Errorf("IncidentWriter.Write() error %v", err)
+	}
+	if len(incidents) != 1 {
+		t.Errorf("IncidentWriter.Write() opened %d incidents after retrying, want 1", len(incidents))
+	}
+	_ = appState.UseComponent("a component", func(c *state.ComponentState) error {
+		if got := c.GetStatuspageIncidentID(); got != "1" {
+			t.Errorf("recorded incident %q, want %q", got, "1")
+		}
+		return nil
+	})
+}
+
+func TestIncidentWriter_Write_keepsIncidentProgress(t *testing.T) {
+	config := makeIncidentConfigHelper()
+	appState := &state.State{}
+	if err := appState.Seed([]statuspagetypes.Component{{Name: "a component", ID: "a-component-id", Status: "operational"}}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	client := statuspageapi.Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	incidents := map[string]statuspagetypes.Incident{}
+	statuspagemocks.ConfigureIncidentMock(config, incidents)
+	incidentWriter, err := NewIncidentWriter(config, appState, client)
+	if err != nil {
+		t.Fatalf("NewIncidentWriter() error %v", err)
+	}
+	callback := StatusUpdater(config, appState)
+	for _, event := range []*events.AlertEvent{
+		{Status: statuspagetypes.PartialOutage, IncidentID: "a", Name: "A policy"},
+		{Status: statuspagetypes.MajorOutage, IncidentID: "b", Name: "B policy"},
+		{Status: statuspagetypes.MajorOutage, IncidentID: "b", Name: "B policy", Ended: true},
+		{Status: statuspagetypes.PartialOutage, IncidentID: "a", Name: "A policy", Ended: true},
+	} {
+		if err := callback("a component", event); err != nil {
+			t.Errorf("callback error %v", err)
+		}
+		if err := incidentWriter.Write(); err != nil {
+			t.Errorf("IncidentWriter.Write() error %v", err)
+		}
+		// someone on Statuspage records progress after it's opened
+		if incident, found := incidents["1"]; found && incident.Status == "investigating" {
+			incident.Status = "identified"
+			incidents["1"] = incident
+		}
+	}
+	want := []statuspagetypes.IncidentUpdate{
+		{Status: "investigating", Body: "Partial Outage from A policy"},
+		{Status: "identified", Body: "Major Outage from B policy"},
+		{Status: "identified", Body: "Partial Outage from B policy"},
+		{Status: "resolved", Body: "a component recovered"},
+	}
+	if diff := cmp.Diff(want, incidents["1"].IncidentUpdates); diff != "" {
+		t.Errorf("incident updates mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAdoptUnresolvedIncidents(t *testing.T) {
+	config := makeIncidentConfigHelper()
+	appState := &state.State{}
+	if err := appState.Seed([]statuspagetypes.Component{
+		{Name: "a component", ID: "a-component-id", Status: "major_outage"},
+		{Name: "another component", ID: "another-component-id", Status: "major_outage"},
+	}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	client := statuspageapi.Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	statuspagemocks.ConfigureIncidentMock(config, map[string]statuspagetypes.Incident{
+		"1": {ID: "1", Status: "investigating", Metadata: map[string]map[string]interface{}{"revere": {"component_id": "a-component-id"}}},
+		"2": {ID: "2", Status: "resolved", Metadata: map[string]map[string]interface{}{"revere": {"component_id": "another-component-id"}}},
+		"3": {ID: "3", Status: "investigating", Components: []statuspagetypes.Component{{ID: "another-component-id"}}},
+	})
+	err := AdoptUnresolvedIncidents(config, appState, client)
+	httpmock.DeactivateAndReset()
+	if err != nil {
+		t.Errorf("AdoptUnresolvedIncidents() error %v", err)
+		return
+	}
+	for componentName, wantID := range map[string]string{"a component": "1", "another component": ""} {
+		_ = appState.UseComponent(componentName, func(c *state.ComponentState) error {
+			if got := c.GetStatuspageIncidentID(); got != wantID {
+				t.Errorf("%s adopted incident %q, want %q", componentName, got, wantID)
+			}
+			return nil
+		})
+	}
+}
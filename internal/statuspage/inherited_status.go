@@ -0,0 +1,59 @@
+package statuspage
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+)
+
+// PatchDriftedStatuses patches each remote component whose status differs from what the appState desires.
+// Desired statuses are read within state.State.UseComponent hooks but patched outside of them, so that other
+// users of the components aren't blocked on Statuspage. This is run after seeding so that the appState's view of
+// each component (restored from storage, or per the InheritedStatus policy) is reflected on Statuspage
+// immediately, and then continually by the StatusWriter. Failures to patch one component don't stop the others
+// from being patched; the first is returned.
+func PatchDriftedStatuses(config *configuration.Config, appState *state.State, client *resty.Client, remoteComponents []statuspagetypes.Component) error {
+	var firstErr error
+	for _, remoteComponent := range remoteComponents {
+		if !appState.HasComponent(remoteComponent.Name) {
+			continue
+		}
+		var componentID string
+		var desiredStatus statuspagetypes.Status
+		err := appState.UseComponent(remoteComponent.Name, func(c *state.ComponentState) error {
+			componentID, desiredStatus = c.GetID(), c.GetDesiredStatus()
+			return nil
+		})
+		if err == nil && desiredStatus.ToSnakeCase() != remoteComponent.Status {
+			shared.LogLn(config, fmt.Sprintf("patching %s from %s to %s on statuspage",
+				remoteComponent.Name, remoteComponent.Status, desiredStatus.ToSnakeCase()))
+			_, err = statuspageapi.PatchComponentStatus(client, config.Statuspage.PageID, componentID, desiredStatus)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ClearInheritedIncidents resolves every component's inherited incident. This is how the "wait" InheritedStatus
+// policy stops waiting; components whose status changes as a result are patched by the StatusWriter.
+func ClearInheritedIncidents(config *configuration.Config, appState *state.State) error {
+	for _, componentName := range appState.ComponentNames() {
+		err := appState.UseComponent(componentName, func(c *state.ComponentState) error {
+			if c.ResolveIncident(state.InheritedIncidentID) {
+				shared.LogLn(config, fmt.Sprintf("inherited status of %s expired, now %s",
+					componentName, c.GetDesiredStatus().ToSnakeCase()))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
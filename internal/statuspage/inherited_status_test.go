@@ -0,0 +1,76 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/jarcoal/httpmock"
+	"testing"
+)
+
+func TestInheritedStatusPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		// If ClearInheritedIncidents should be called after PatchDriftedStatuses
+		clear      bool
+		wantStatus string
+	}{
+		{
+			name:       "keep leaves remote status alone",
+			policy:     "keep",
+			wantStatus: "partial_outage",
+		},
+		{
+			name:       "reset patches remote status to operational",
+			policy:     "reset",
+			wantStatus: "operational",
+		},
+		{
+			name:       "wait leaves remote status alone while waiting",
+			policy:     "wait",
+			wantStatus: "partial_outage",
+		},
+		{
+			name:       "wait patches remote status to operational once done waiting",
+			policy:     "wait",
+			clear:      true,
+			wantStatus: "operational",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := makeConfigHelper([]configuration.Component{{Name: "a component"}}, nil)
+			config.InheritedStatus.Policy = tt.policy
+			remoteComponents := []statuspagetypes.Component{
+				{Name: "a component", ID: "a-component-id", Status: "partial_outage"},
+			}
+			mockState := map[string]statuspagetypes.Component{"a-component-id": remoteComponents[0]}
+			appState := state.NewState(config, state.NewMemoryStore())
+			if err := appState.Seed(remoteComponents); err != nil {
+				t.Errorf("unexpected Seed error %v", err)
+				return
+			}
+			client := statuspageapi.Client(config)
+			httpmock.ActivateNonDefault(client.GetClient())
+			statuspagemocks.ConfigureComponentMock(config, mockState)
+			if err := PatchDriftedStatuses(config, appState, client, remoteComponents); err != nil {
+				t.Errorf("PatchDriftedStatuses() error %v", err)
+			}
+			if tt.clear {
+				if err := ClearInheritedIncidents(config, appState); err != nil {
+					t.Errorf("ClearInheritedIncidents() error %v", err)
+				}
+			}
+			if err := NewStatusWriter(config, appState, client).Write(); err != nil {
+				t.Errorf("StatusWriter.Write() error %v", err)
+			}
+			httpmock.DeactivateAndReset()
+			if got := mockState["a-component-id"].Status; got != tt.wantStatus {
+				t.Errorf("remote status %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
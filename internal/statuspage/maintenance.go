@@ -0,0 +1,236 @@
+package statuspage
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+	"time"
+)
+
+// maintenancePolicyName stands in for an alert policy name when a status change was caused by maintenance
+const maintenancePolicyName = "Scheduled maintenance"
+
+// MaintenanceUpdater returns a function to schedule or cancel a maintenance window. Scheduling announces the
+// window with a Statuspage scheduled incident; affected components are put under maintenance by
+// ApplyMaintenanceWindows while it's in progress.
+func MaintenanceUpdater(config *configuration.Config, appState *state.State, client *resty.Client) state.MaintenanceHandler {
+	return func(name string, window *state.MaintenanceWindow) error {
+		if window == nil {
+			if err := cancelMaintenanceWindow(config, appState, client, name); err != nil {
+				return err
+			}
+		} else {
+			window.Name = name
+			if err := scheduleMaintenanceWindow(config, appState, client, *window); err != nil {
+				return err
+			}
+		}
+		return ApplyMaintenanceWindows(config, appState)
+	}
+}
+
+// scheduleMaintenanceWindow creates the window's Statuspage scheduled incident, if it doesn't have one, and
+// records the window in the appState
+func scheduleMaintenanceWindow(config *configuration.Config, appState *state.State, client *resty.Client,
+	window state.MaintenanceWindow) error {
+	if window.StatuspageIncidentID == "" {
+		var componentIDs []string
+		for _, componentName := range window.ComponentNames {
+			err := appState.UseComponent(componentName, func(c *state.ComponentState) error {
+				componentIDs = append(componentIDs, c.GetID())
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		request := statuspagetypes.RequestIncident{
+			Name:                    window.Name,
+			Status:                  "scheduled",
+			Body:                    window.Description,
+			ComponentIDs:            componentIDs,
+			DeliverNotifications:    config.StatuspageIncidents.DeliverNotifications,
+			ScheduledFor:            window.StartsAt.UTC().Format(time.RFC3339),
+			ScheduledUntil:          window.EndsAt.UTC().Format(time.RFC3339),
+			ScheduledAutoInProgress: true,
+			ScheduledAutoCompleted:  true,
+			Metadata: map[string]map[string]interface{}{
+				statuspagetypes.RevereMetadataKey: {"maintenance": window.Name},
+			},
+		}
+		shared.LogLn(config, fmt.Sprintf("scheduling %s maintenance on statuspage", window.Name),
+			fmt.Sprintf(" - new: %+v", request))
+		created, err := statuspageapi.PostIncident(client, config.Statuspage.PageID, request)
+		if err != nil {
+			return err
+		}
+		window.StatuspageIncidentID = created.ID
+	}
+	appState.PutMaintenanceWindow(window)
+	return nil
+}
+
+// cancelMaintenanceWindow completes the window's Statuspage scheduled incident and then removes the window from
+// the appState, so a failed update leaves it scheduled to be cancelled again
+func cancelMaintenanceWindow(config *configuration.Config, appState *state.State, client *resty.Client, name string) error {
+	window, found := appState.GetMaintenanceWindow(name)
+	if !found {
+		return nil
+	}
+	if window.StatuspageIncidentID != "" {
+		request := statuspagetypes.RequestIncident{
+			Status:               "completed",
+			Body:                 "This maintenance has been cancelled.",
+			DeliverNotifications: config.StatuspageIncidents.DeliverNotifications,
+		}
+		if window.ActiveAt(time.Now()) {
+			request.Body = "This maintenance has ended early."
+		}
+		shared.LogLn(config, fmt.Sprintf("completing %s maintenance on statuspage", name),
+			fmt.Sprintf(" - update: %+v", request))
+		_, err := statuspageapi.PatchIncident(client, config.Statuspage.PageID, window.StatuspageIncidentID, request)
+		if err != nil {
+			return err
+		}
+	}
+	appState.RemoveMaintenanceWindow(name)
+	return nil
+}
+
+// ApplyMaintenanceWindows puts components under maintenance while a window affecting them is in progress and
+// takes them out of it otherwise, publishing the resulting status changes. Windows that have ended are
+// forgotten; Statuspage completes their scheduled incidents itself.
+func ApplyMaintenanceWindows(config *configuration.Config, appState *state.State) error {
+	now := time.Now()
+	windows := appState.GetMaintenanceWindows()
+	for _, componentName := range appState.ComponentNames() {
+		underMaintenance := false
+		for _, window := range windows {
+			if window.ActiveAt(now) && window.Affects(componentName) {
+				underMaintenance = true
+			}
+		}
+		err := appState.UseComponent(componentName, func(c *state.ComponentState) error {
+			if c.IsUnderMaintenance() == underMaintenance {
+				return nil
+			}
+			if underMaintenance {
+				shared.LogLn(config, fmt.Sprintf("maintenance of %s started", componentName))
+			} else {
+				shared.LogLn(config, fmt.Sprintf("maintenance of %s ended", componentName))
+			}
+			componentStatusChanged := c.SetUnderMaintenance(underMaintenance)
+			event := &events.AlertEvent{Name: maintenancePolicyName}
+			scheduleDampedStatus(config, appState, componentName, c, event)
+			publishDesiredStatus(config, c, event, componentStatusChanged, false)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, window := range windows {
+		if !now.Before(window.EndsAt) {
+			appState.RemoveMaintenanceWindow(window.Name)
+		}
+	}
+	return nil
+}
+
+// AdoptMaintenanceWindows finds the Statuspage scheduled incidents that Revere created before it last stopped,
+// whether they're upcoming or in progress, recording their maintenance windows in the appState again, and then
+// schedules the configuration's windows that haven't ended and weren't already scheduled. Configured windows that
+// were cancelled before Revere last stopped aren't scheduled again, see maintenanceCompleted.
+func AdoptMaintenanceWindows(config *configuration.Config, appState *state.State, client *resty.Client) error {
+	scheduledIncidents, err := statuspageapi.GetScheduledIncidents(client, config.Statuspage.PageID)
+	if err != nil {
+		return err
+	}
+	activeIncidents, err := statuspageapi.GetActiveMaintenanceIncidents(client, config.Statuspage.PageID)
+	if err != nil {
+		return err
+	}
+	componentIDToName := make(map[string]string)
+	for _, componentName := range appState.ComponentNames() {
+		err := appState.UseComponent(componentName, func(c *state.ComponentState) error {
+			componentIDToName[c.GetID()] = componentName
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, incident := range append(*scheduledIncidents, *activeIncidents...) {
+		name, managed := incident.ManagedMaintenanceName()
+		if !managed {
+			continue
+		}
+		window := state.MaintenanceWindow{Name: name, StatuspageIncidentID: incident.ID}
+		if window.StartsAt, err = time.Parse(time.RFC3339, incident.ScheduledFor); err != nil {
+			return fmt.Errorf("failed to read start of %s maintenance: %w", name, err)
+		}
+		if window.EndsAt, err = time.Parse(time.RFC3339, incident.ScheduledUntil); err != nil {
+			return fmt.Errorf("failed to read end of %s maintenance: %w", name, err)
+		}
+		for _, component := range incident.Components {
+			if componentName, found := componentIDToName[component.ID]; found {
+				window.ComponentNames = append(window.ComponentNames, componentName)
+			}
+		}
+		shared.LogLn(config, fmt.Sprintf("adopting statuspage scheduled incident %s for %s maintenance", incident.ID, name))
+		appState.PutMaintenanceWindow(window)
+	}
+
+	now := time.Now()
+	for _, configWindow := range config.MaintenanceWindows {
+		window := state.MaintenanceWindow{
+			Name:           configWindow.Name,
+			Description:    configWindow.Description,
+			ComponentNames: configWindow.AffectsComponentsNamed,
+		}
+		// Times were validated along with the rest of the configuration
+		window.StartsAt, _ = time.Parse(time.RFC3339, configWindow.Start)
+		window.EndsAt, _ = time.Parse(time.RFC3339, configWindow.End)
+		if _, scheduled := appState.GetMaintenanceWindow(window.Name); scheduled || !now.Before(window.EndsAt) {
+			continue
+		}
+		completed, err := maintenanceCompleted(config, client, window)
+		if err != nil {
+			return err
+		}
+		if completed {
+			shared.LogLn(config, fmt.Sprintf("not scheduling %s maintenance, it was already cancelled", window.Name))
+			continue
+		}
+		if err := scheduleMaintenanceWindow(config, appState, client, window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maintenanceCompleted returns if Statuspage has a completed scheduled incident that Revere created for the window,
+// with the same name and times. Windows are only completed early by cancelling them, so this is how a cancellation
+// is remembered across restarts without Revere storing it.
+func maintenanceCompleted(config *configuration.Config, client *resty.Client, window state.MaintenanceWindow) (bool, error) {
+	incidents, err := statuspageapi.GetIncidents(client, config.Statuspage.PageID, window.Name)
+	if err != nil {
+		return false, err
+	}
+	for _, incident := range *incidents {
+		if name, managed := incident.ManagedMaintenanceName(); !managed || name != window.Name || incident.Status != "completed" {
+			continue
+		}
+		startsAt, startErr := time.Parse(time.RFC3339, incident.ScheduledFor)
+		endsAt, endErr := time.Parse(time.RFC3339, incident.ScheduledUntil)
+		if startErr == nil && endErr == nil && startsAt.Equal(window.StartsAt) && endsAt.Equal(window.EndsAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
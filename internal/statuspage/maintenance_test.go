@@ -0,0 +1,218 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/jarcoal/httpmock"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceUpdater(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	tests := []struct {
+		name string
+		// Status of an alert open against the component before the maintenance is scheduled, if any
+		alertType *statuspagetypes.Status
+		window    state.MaintenanceWindow
+		// If the maintenance should be cancelled afterwards
+		cancel             bool
+		wantStatus         string
+		wantIncidentStatus string
+	}{
+		{
+			name:               "puts components under maintenance while in progress",
+			window:             state.MaintenanceWindow{StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)},
+			wantStatus:         "under_maintenance",
+			wantIncidentStatus: "scheduled",
+		},
+		{
+			name:               "leaves components alone before it starts",
+			window:             state.MaintenanceWindow{StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)},
+			wantStatus:         "operational",
+			wantIncidentStatus: "scheduled",
+		},
+		{
+			name:               "suppresses alerts while in progress",
+			alertType:          statuspageStatusPointer(statuspagetypes.MajorOutage),
+			window:             state.MaintenanceWindow{StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)},
+			wantStatus:         "under_maintenance",
+			wantIncidentStatus: "scheduled",
+		},
+		{
+			name:               "cancelling restores the alert-derived status",
+			alertType:          statuspageStatusPointer(statuspagetypes.PartialOutage),
+			window:             state.MaintenanceWindow{StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)},
+			cancel:             true,
+			wantStatus:         "partial_outage",
+			wantIncidentStatus: "completed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := makeConfigHelper([]configuration.Component{{Name: "a component"}}, nil)
+			mockComponents := map[string]statuspagetypes.Component{
+				"a-component-id": {Name: "a component", ID: "a-component-id", Status: "operational"},
+			}
+			mockIncidents := map[string]statuspagetypes.Incident{}
+			appState := &state.State{}
+			if err := appState.Seed([]statuspagetypes.Component{mockComponents["a-component-id"]}); err != nil {
+				t.Errorf("unexpected Seed error %v", err)
+				return
+			}
+			client := statuspageapi.Client(config)
+			httpmock.ActivateNonDefault(client.GetClient())
+			statuspagemocks.ConfigureComponentMock(config, mockComponents)
+			statuspagemocks.ConfigureIncidentMock(config, mockIncidents)
+			if tt.alertType != nil {
+				err := StatusUpdater(config, appState)("a component",
+					&events.AlertEvent{IncidentID: "foo", Status: *tt.alertType})
+				if err != nil {
+					t.Errorf("StatusUpdater() error %v", err)
+				}
+			}
+			window := tt.window
+			window.ComponentNames = []string{"a component"}
+			if err := MaintenanceUpdater(config, appState, client)("upgrade", &window); err != nil {
+				t.Errorf("MaintenanceUpdater() error %v", err)
+			}
+			if tt.cancel {
+				if err := MaintenanceUpdater(config, appState, client)("upgrade", nil); err != nil {
+					t.Errorf("MaintenanceUpdater() error %v", err)
+				}
+			}
+			if err := NewStatusWriter(config, appState, client).Write(); err != nil {
+				t.Errorf("StatusWriter.Write() error %v", err)
+			}
+			httpmock.DeactivateAndReset()
+			if got := mockComponents["a-component-id"].Status; got != tt.wantStatus {
+				t.Errorf("remote status %s, want %s", got, tt.wantStatus)
+			}
+			incident, found := mockIncidents["1"]
+			if !found {
+				t.Errorf("no scheduled incident created")
+				return
+			}
+			if incident.Status != tt.wantIncidentStatus {
+				t.Errorf("scheduled incident status %s, want %s", incident.Status, tt.wantIncidentStatus)
+			}
+			if name, managed := incident.ManagedMaintenanceName(); !managed || name != "upgrade" {
+				t.Errorf("scheduled incident maintenance name %s, want upgrade", name)
+			}
+			if len(mockIncidents) != 1 {
+				t.Errorf("%d incidents created, want only the scheduled one", len(mockIncidents))
+			}
+		})
+	}
+}
+
+func TestMaintenanceUpdater_failedCancel(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	config := makeConfigHelper([]configuration.Component{{Name: "a component"}}, nil)
+	mockComponents := map[string]statuspagetypes.Component{
+		"a-component-id": {Name: "a component", ID: "a-component-id", Status: "operational"},
+	}
+	appState := &state.State{}
+	if err := appState.Seed([]statuspagetypes.Component{mockComponents["a-component-id"]}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	client := statuspageapi.Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	statuspagemocks.ConfigureComponentMock(config, mockComponents)
+	statuspagemocks.ConfigureIncidentMock(config, map[string]statuspagetypes.Incident{})
+	window := state.MaintenanceWindow{ComponentNames: []string{"a component"},
+		StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}
+	if err := MaintenanceUpdater(config, appState, client)("upgrade", &window); err != nil {
+		t.Errorf("MaintenanceUpdater() error %v", err)
+		return
+	}
+	// the scheduled incident is gone, so completing it fails
+	statuspagemocks.ConfigureIncidentMock(config, map[string]statuspagetypes.Incident{})
+	if err := MaintenanceUpdater(config, appState, client)("upgrade", nil); err == nil {
+		t.Errorf("MaintenanceUpdater() error nil, want the failed update's")
+	}
+	if _, found := appState.GetMaintenanceWindow("upgrade"); !found {
+		t.Errorf("maintenance window removed, want it kept after the failed cancellation")
+	}
+	err := appState.UseComponent("a component", func(c *state.ComponentState) error {
+		if !c.IsUnderMaintenance() {
+			t.Errorf("component taken out of maintenance, want it kept under maintenance")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("unexpected UseComponent error %v", err)
+	}
+}
+
+func TestAdoptMaintenanceWindows(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	config := makeConfigHelper([]configuration.Component{{Name: "a component"}}, nil)
+	config.MaintenanceWindows = []configuration.MaintenanceWindow{
+		{Name: "adopted", AffectsComponentsNamed: []string{"a component"},
+			Start: now.Format(time.RFC3339), End: now.Add(time.Hour).Format(time.RFC3339)},
+		{Name: "configured", AffectsComponentsNamed: []string{"a component"},
+			Start: now.Add(time.Hour).Format(time.RFC3339), End: now.Add(2 * time.Hour).Format(time.RFC3339)},
+		{Name: "ended", AffectsComponentsNamed: []string{"a component"},
+			Start: now.Add(-2 * time.Hour).Format(time.RFC3339), End: now.Add(-time.Hour).Format(time.RFC3339)},
+		{Name: "cancelled", AffectsComponentsNamed: []string{"a component"},
+			Start: now.Add(time.Hour).Format(time.RFC3339), End: now.Add(2 * time.Hour).Format(time.RFC3339)},
+	}
+	mockComponents := map[string]statuspagetypes.Component{
+		"a-component-id": {Name: "a component", ID: "a-component-id", Status: "under_maintenance"},
+	}
+	mockIncidents := map[string]statuspagetypes.Incident{
+		// already in progress, so it's no longer listed among scheduled incidents
+		"1": {ID: "1", Name: "adopted", Status: "in_progress",
+			ScheduledFor: now.Add(-time.Minute).Format(time.RFC3339), ScheduledUntil: now.Add(time.Hour).Format(time.RFC3339),
+			Components: []statuspagetypes.Component{{ID: "a-component-id"}},
+			Metadata:   map[string]map[string]interface{}{statuspagetypes.RevereMetadataKey: {"maintenance": "adopted"}}},
+		"2": {ID: "2", Name: "someone else's", Status: "scheduled",
+			ScheduledFor: now.Format(time.RFC3339), ScheduledUntil: now.Add(time.Hour).Format(time.RFC3339)},
+		// cancelled before a restart, so it shouldn't be scheduled again
+		"3": {ID: "3", Name: "cancelled", Status: "completed",
+			ScheduledFor: now.Add(time.Hour).Format(time.RFC3339), ScheduledUntil: now.Add(2 * time.Hour).Format(time.RFC3339),
+			Metadata: map[string]map[string]interface{}{statuspagetypes.RevereMetadataKey: {"maintenance": "cancelled"}}},
+	}
+	appState := &state.State{}
+	if err := appState.Seed([]statuspagetypes.Component{mockComponents["a-component-id"]}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	client := statuspageapi.Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	statuspagemocks.ConfigureComponentMock(config, mockComponents)
+	statuspagemocks.ConfigureIncidentMock(config, mockIncidents)
+	err := AdoptMaintenanceWindows(config, appState, client)
+	if err == nil {
+		err = ApplyMaintenanceWindows(config, appState)
+	}
+	httpmock.DeactivateAndReset()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+		return
+	}
+	windows := appState.GetMaintenanceWindows()
+	if len(windows) != 2 || windows[0].Name != "adopted" || windows[1].Name != "configured" {
+		t.Errorf("maintenance windows %+v, want adopted and configured", windows)
+		return
+	}
+	if windows[0].StatuspageIncidentID != "1" || windows[0].ComponentNames[0] != "a component" {
+		t.Errorf("adopted maintenance window %+v, want incident 1 affecting a component", windows[0])
+	}
+	if windows[1].StatuspageIncidentID != "4" {
+		t.Errorf("configured maintenance window incident %s, want a new one", windows[1].StatuspageIncidentID)
+	}
+	if len(mockIncidents) != 4 {
+		t.Errorf("%d incidents, want only the configured window's to be created", len(mockIncidents))
+	}
+	if got := mockComponents["a-component-id"].Status; got != "under_maintenance" {
+		t.Errorf("remote status %s, want under_maintenance", got)
+	}
+}
@@ -0,0 +1,94 @@
+package statuspage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"os"
+)
+
+// managedResources records the IDs of components and groups that Revere created on Statuspage, so that the
+// "managed" deletion policy can tell them apart from ones that people created
+type managedResources struct {
+	Components []string `json:"components"`
+	Groups     []string `json:"groups"`
+	// path the resources are saved to; if empty, nothing is recorded
+	path string
+}
+
+// loadManagedResources reads the file at the path, if there is one. An empty path never records anything.
+func loadManagedResources(path string) (*managedResources, error) {
+	resources := &managedResources{path: path}
+	if path == "" {
+		return resources, nil
+	}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return resources, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read managed resources from %s: %w", path, err)
+	}
+	if err := json.Unmarshal(contents, resources); err != nil {
+		return nil, fmt.Errorf("failed to parse managed resources from %s: %w", path, err)
+	}
+	return resources, nil
+}
+
+// save writes the resources to their file, unless they have no path
+func (m *managedResources) save() error {
+	if m.path == "" {
+		return nil
+	}
+	contents, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to render managed resources: %w", err)
+	}
+	if err := os.WriteFile(m.path, contents, 0644); err != nil {
+		return fmt.Errorf("failed to write managed resources to %s: %w", m.path, err)
+	}
+	return nil
+}
+
+// managedResourcesPath returns where to record the components and groups Revere creates. Only the "managed"
+// deletion policy reads the record, so under the others nothing is recorded and the file needn't be writable.
+func managedResourcesPath(config *configuration.Config) string {
+	if config.Statuspage.DeletionPolicy != "managed" {
+		return ""
+	}
+	return config.Statuspage.ManagedResourcesFile
+}
+
+// contains returns if the ID is in the list
+func contains(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns the list lacking the ID
+func without(ids []string, id string) []string {
+	var remaining []string
+	for _, existing := range ids {
+		if existing != id {
+			remaining = append(remaining, existing)
+		}
+	}
+	return remaining
+}
+
+// mayDelete returns if the config's deletion policy allows deleting the remote component or group with the
+// given ID, based on the IDs of those that Revere created
+func mayDelete(config *configuration.Config, managedIDs []string, id string) bool {
+	switch config.Statuspage.DeletionPolicy {
+	case "never":
+		return false
+	case "managed":
+		return contains(managedIDs, id)
+	default:
+		return config.Statuspage.AllowDelete
+	}
+}
@@ -0,0 +1,195 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestReconcileComponents_deletionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		allowDelete bool
+		// Contents of the managed resources file before reconciling, if any
+		managedFile string
+		wantIDs     []string
+		// Component IDs in the managed resources file after reconciling
+		wantManaged []string
+	}{
+		{
+			name:        "never keeps everything",
+			policy:      "never",
+			allowDelete: true,
+			wantIDs:     []string{"1", "2"},
+		},
+		{
+			name:    "flag keeps everything without the flag",
+			policy:  "flag",
+			wantIDs: []string{"1", "2"},
+		},
+		{
+			name:        "flag deletes unconfigured components with the flag",
+			policy:      "flag",
+			allowDelete: true,
+			wantIDs:     []string{"1"},
+		},
+		{
+			name:        "managed keeps components Revere didn't create",
+			policy:      "managed",
+			managedFile: `{"components": ["1"]}`,
+			wantIDs:     []string{"1", "2"},
+			wantManaged: []string{"1"},
+		},
+		{
+			name:        "managed deletes components Revere created",
+			policy:      "managed",
+			managedFile: `{"components": ["1", "2"]}`,
+			wantIDs:     []string{"1"},
+			wantManaged: []string{"1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := makeDeletionConfigHelper([]configuration.Component{{Name: "Configured"}})
+			config.Statuspage.DeletionPolicy = tt.policy
+			config.Statuspage.AllowDelete = tt.allowDelete
+			config.Statuspage.ManagedResourcesFile = filepath.Join(t.TempDir(), "managed.json")
+			if tt.managedFile != "" {
+				if err := os.WriteFile(config.Statuspage.ManagedResourcesFile, []byte(tt.managedFile), 0644); err != nil {
+					t.Errorf("failed to write managed resources: %v", err)
+					return
+				}
+			}
+			components := map[string]statuspagetypes.Component{
+				"1": {ID: "1", Name: "Configured", Showcase: true, Status: "operational", PageID: "foo"},
+				"2": {ID: "2", Name: "Unconfigured", Showcase: true, Status: "operational", PageID: "foo"},
+			}
+			client := statuspageapi.Client(config)
+			httpmock.ActivateNonDefault(client.GetClient())
+			statuspagemocks.ConfigureComponentMock(config, components)
+			err := ReconcileComponents(config, client)
+			httpmock.DeactivateAndReset()
+			if err != nil {
+				t.Errorf("ReconcileComponents() error = %v", err)
+				return
+			}
+			var gotIDs []string
+			for id := range components {
+				gotIDs = append(gotIDs, id)
+			}
+			sort.Strings(gotIDs)
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("ReconcileComponents() remote mismatch (-want +got):\n%s", diff)
+			}
+			managed, err := loadManagedResources(config.Statuspage.ManagedResourcesFile)
+			if err != nil {
+				t.Errorf("loadManagedResources() error = %v", err)
+				return
+			}
+			if diff := cmp.Diff(tt.wantManaged, managed.Components); diff != "" {
+				t.Errorf("managed components mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileComponents_recordsCreated(t *testing.T) {
+	config := makeDeletionConfigHelper([]configuration.Component{{Name: "New"}})
+	config.Statuspage.DeletionPolicy = "managed"
+	config.Statuspage.ManagedResourcesFile = filepath.Join(t.TempDir(), "managed.json")
+	client := statuspageapi.Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	components := map[string]statuspagetypes.Component{}
+	statuspagemocks.ConfigureComponentMock(config, components)
+	err := ReconcileComponents(config, client)
+	httpmock.DeactivateAndReset()
+	if err != nil {
+		t.Errorf("ReconcileComponents() error = %v", err)
+		return
+	}
+	managed, err := loadManagedResources(config.Statuspage.ManagedResourcesFile)
+	if err != nil {
+		t.Errorf("loadManagedResources() error = %v", err)
+		return
+	}
+	var createdIDs []string
+	for id := range components {
+		createdIDs = append(createdIDs, id)
+	}
+	if diff := cmp.Diff(createdIDs, managed.Components); diff != "" {
+		t.Errorf("managed components mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReconcileComponents_recordsOnlyUnderManagedPolicy(t *testing.T) {
+	config := makeDeletionConfigHelper([]configuration.Component{{Name: "New"}})
+	config.Statuspage.DeletionPolicy = "flag"
+	// a directory that doesn't exist, so writing the file would fail
+	config.Statuspage.ManagedResourcesFile = filepath.Join(t.TempDir(), "missing", "managed.json")
+	client := statuspageapi.Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	components := map[string]statuspagetypes.Component{}
+	statuspagemocks.ConfigureComponentMock(config, components)
+	err := ReconcileComponents(config, client)
+	httpmock.DeactivateAndReset()
+	if err != nil {
+		t.Errorf("ReconcileComponents() error = %v", err)
+		return
+	}
+	if len(components) != 1 {
+		t.Errorf("%d components created, want 1", len(components))
+	}
+	if _, err := os.Stat(config.Statuspage.ManagedResourcesFile); !os.IsNotExist(err) {
+		t.Errorf("managed resources file written under the flag policy, want nothing recorded")
+	}
+}
+
+func makeDeletionConfigHelper(components []configuration.Component) *configuration.Config {
+	return &configuration.Config{
+		Statuspage: struct {
+			ApiKey               string `validate:"required"`
+			PageID               string `validate:"required"`
+			ApiRoot              string
+			Components           []configuration.Component      `validate:"unique=Name,dive"`
+			Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+			DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+			AllowDelete          bool
+			ManagedResourcesFile string
+		}{ApiKey: "key", PageID: "foo", ApiRoot: "https://localhost", Components: components},
+	}
+}
+
+func Test_mayDelete(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		allowDelete bool
+		managedIDs  []string
+		id          string
+		want        bool
+	}{
+		{name: "never with flag", policy: "never", allowDelete: true, managedIDs: []string{"1"}, id: "1", want: false},
+		{name: "managed and recorded", policy: "managed", managedIDs: []string{"1"}, id: "1", want: true},
+		{name: "managed but not recorded", policy: "managed", allowDelete: true, managedIDs: []string{"2"}, id: "1", want: false},
+		{name: "flag without flag", policy: "flag", managedIDs: []string{"1"}, id: "1", want: false},
+		{name: "flag with flag", policy: "flag", allowDelete: true, id: "1", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := makeDeletionConfigHelper(nil)
+			config.Statuspage.DeletionPolicy = tt.policy
+			config.Statuspage.AllowDelete = tt.allowDelete
+			if got := mayDelete(config, tt.managedIDs, tt.id); got != tt.want {
+				t.Errorf("mayDelete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
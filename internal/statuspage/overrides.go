@@ -0,0 +1,56 @@
+package statuspage
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"time"
+)
+
+// overridePolicyName stands in for an alert policy name when a status change was caused by an override
+const overridePolicyName = "Manual override"
+
+// OverrideUpdater returns a function to set or clear a component's manual override. Resulting status changes
+// are published to Statuspage exactly as StatusUpdater publishes those caused by alerts.
+func OverrideUpdater(config *configuration.Config, appState *state.State) state.OverrideHandler {
+	return func(componentName string, override *state.Override) error {
+		return appState.UseComponent(componentName, func(c *state.ComponentState) error {
+			event := &events.AlertEvent{Name: overridePolicyName}
+			var componentStatusChanged bool
+			if override == nil {
+				shared.LogLn(config, fmt.Sprintf("clearing override for %s", componentName))
+				componentStatusChanged = c.ClearOverride()
+			} else {
+				shared.LogLn(config, fmt.Sprintf("overriding %s to %s", componentName, override.Status.ToSnakeCase()))
+				event.Summary = override.Reason
+				componentStatusChanged = c.SetOverride(*override)
+			}
+			scheduleDampedStatus(config, appState, componentName, c, event)
+			publishDesiredStatus(config, c, event, componentStatusChanged, override != nil)
+			return nil
+		})
+	}
+}
+
+// ExpireOverrides clears every override that has expired, publishing the resulting status changes.
+func ExpireOverrides(config *configuration.Config, appState *state.State) error {
+	now := time.Now()
+	for _, componentName := range appState.ComponentNames() {
+		err := appState.UseComponent(componentName, func(c *state.ComponentState) error {
+			if c.ExpireOverride(now) {
+				shared.LogLn(config, fmt.Sprintf("override for %s expired, patching to %s on statuspage",
+					componentName, c.GetDesiredStatus().ToSnakeCase()))
+				event := &events.AlertEvent{Name: overridePolicyName}
+				scheduleDampedStatus(config, appState, componentName, c, event)
+				publishDesiredStatus(config, c, event, true, false)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/jarcoal/httpmock"
+	"testing"
+	"time"
+)
+
+func TestOverrideUpdater(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name string
+		// Status of an alert open against the component before the override is set, if any
+		alertType *statuspagetypes.Status
+		override  *state.Override
+		// If ExpireOverrides should be called afterwards
+		expire     bool
+		wantStatus string
+	}{
+		{
+			name:       "forces an outage",
+			override:   &state.Override{Status: statuspagetypes.MajorOutage},
+			wantStatus: "major_outage",
+		},
+		{
+			name:       "pins a component to operational despite alerts",
+			alertType:  statuspageStatusPointer(statuspagetypes.PartialOutage),
+			override:   &state.Override{Status: statuspagetypes.Operational},
+			wantStatus: "operational",
+		},
+		{
+			name:       "clearing restores the alert-derived status",
+			alertType:  statuspageStatusPointer(statuspagetypes.PartialOutage),
+			override:   nil,
+			wantStatus: "partial_outage",
+		},
+		{
+			name:       "expiry restores the alert-derived status",
+			alertType:  statuspageStatusPointer(statuspagetypes.PartialOutage),
+			override:   &state.Override{Status: statuspagetypes.Operational, ExpiresAt: &past},
+			expire:     true,
+			wantStatus: "partial_outage",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := makeConfigHelper([]configuration.Component{{Name: "a component"}}, nil)
+			mockState := map[string]statuspagetypes.Component{
+				"a-component-id": {Name: "a component", ID: "a-component-id", Status: "operational"},
+			}
+			appState := &state.State{}
+			if err := appState.Seed([]statuspagetypes.Component{mockState["a-component-id"]}); err != nil {
+				t.Errorf("unexpected Seed error %v", err)
+				return
+			}
+			client := statuspageapi.Client(config)
+			httpmock.ActivateNonDefault(client.GetClient())
+			statuspagemocks.ConfigureComponentMock(config, mockState)
+			if tt.alertType != nil {
+				err := StatusUpdater(config, appState)("a component",
+					&events.AlertEvent{IncidentID: "foo", Status: *tt.alertType})
+				if err != nil {
+					t.Errorf("StatusUpdater() error %v", err)
+				}
+			}
+			if err := OverrideUpdater(config, appState)("a component", tt.override); err != nil {
+				t.Errorf("OverrideUpdater() error %v", err)
+			}
+			if tt.expire {
+				if err := ExpireOverrides(config, appState); err != nil {
+					t.Errorf("ExpireOverrides() error %v", err)
+				}
+			}
+			if err := NewStatusWriter(config, appState, client).Write(); err != nil {
+				t.Errorf("StatusWriter.Write() error %v", err)
+			}
+			httpmock.DeactivateAndReset()
+			if got := mockState["a-component-id"].Status; got != tt.wantStatus {
+				t.Errorf("remote status %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func statuspageStatusPointer(status statuspagetypes.Status) *statuspagetypes.Status {
+	return &status
+}
@@ -0,0 +1,193 @@
+package statuspage
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldChange is a single field that would differ on the remote
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// PlannedChange is a single component or group that would be deleted, created, or modified
+type PlannedChange struct {
+	// Action is "delete", "create", or "modify" ("keep" for unmanaged resources)
+	Action string `json:"action"`
+	// Kind is "component" or "group"
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// ID of the remote component or group, empty when creating
+	ID      string        `json:"id,omitempty"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// Plan describes what ReconcileComponents and ReconcileGroups would do, in the order they'd do it: components
+// before groups, and for each, deletions (ordered by ID) and then creations and modifications (from first position
+// to last, then by name)
+type Plan struct {
+	Changes []PlannedChange `json:"changes"`
+	// Unmanaged components and groups aren't in the config, but the deletion policy prevents deleting them
+	Unmanaged []PlannedChange `json:"unmanaged,omitempty"`
+}
+
+// HasDrift returns if applying the plan would change the remote. Unmanaged resources don't count, since the
+// deletion policy keeps them on purpose.
+func (p *Plan) HasDrift() bool {
+	return len(p.Changes) > 0
+}
+
+// ToText renders the plan for humans to read
+func (p *Plan) ToText() string {
+	if !p.HasDrift() && len(p.Unmanaged) == 0 {
+		return "No changes: Statuspage matches the configuration.\n"
+	}
+	symbols := map[string]string{"delete": "-", "create": "+", "modify": "~"}
+	var builder strings.Builder
+	for _, unmanaged := range p.Unmanaged {
+		builder.WriteString(fmt.Sprintf("! keep %s %q (%s): not configured, but the deletion policy prevents deleting it\n",
+			unmanaged.Kind, unmanaged.Name, unmanaged.ID))
+	}
+	for _, change := range p.Changes {
+		builder.WriteString(fmt.Sprintf("%s %s %s %q", symbols[change.Action], change.Action, change.Kind, change.Name))
+		if change.ID != "" {
+			builder.WriteString(fmt.Sprintf(" (%s)", change.ID))
+		}
+		builder.WriteString("\n")
+		for _, field := range change.Changes {
+			if change.Action == "create" {
+				builder.WriteString(fmt.Sprintf("    %s: %s\n", field.Field, formatValue(field.To)))
+			} else {
+				builder.WriteString(fmt.Sprintf("    %s: %s -> %s\n", field.Field, formatValue(field.From), formatValue(field.To)))
+			}
+		}
+	}
+	counts := map[string]int{}
+	for _, change := range p.Changes {
+		counts[change.Action]++
+	}
+	builder.WriteString(fmt.Sprintf("Plan: %d to delete, %d to create, %d to modify.\n",
+		counts["delete"], counts["create"], counts["modify"]))
+	return builder.String()
+}
+
+// formatValue renders a field's value as JSON, so strings are quoted and lists are bracketed
+func formatValue(value interface{}) string {
+	rendered, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(rendered)
+}
+
+// ToJSON renders the plan for machines to read
+func (p *Plan) ToJSON() (string, error) {
+	rendered, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to render plan as JSON: %w", err)
+	}
+	return string(rendered) + "\n", nil
+}
+
+// DryRun computes what ReconcileComponents and then ReconcileGroups would change, without changing anything.
+// Groups that would include components not yet created refer to them by placeholder IDs.
+func DryRun(config *configuration.Config, client *resty.Client) (*Plan, error) {
+	components, err := planComponents(config, client)
+	if err != nil {
+		return nil, err
+	}
+	componentNameToID, err := makeComponentMapping(client, config.Statuspage.PageID)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range components.toCreate {
+		componentNameToID[component.Name] = fmt.Sprintf("(new %s)", component.Name)
+	}
+	// Groups refer to components by their configured names, which renamed components don't have yet
+	for _, component := range components.toModify {
+		if remoteName := components.remoteComponentMap[component.Name].Name; remoteName != component.Name {
+			delete(componentNameToID, remoteName)
+			componentNameToID[component.Name] = component.ID
+		}
+	}
+	groups, err := planGroups(config, client, componentNameToID)
+	if err != nil {
+		return nil, err
+	}
+	componentIDToName := make(map[string]string)
+	for name, id := range componentNameToID {
+		componentIDToName[id] = name
+	}
+
+	var plan Plan
+	for _, component := range components.unmanaged {
+		plan.Unmanaged = append(plan.Unmanaged, PlannedChange{Action: "keep", Kind: "component", Name: component.Name, ID: component.ID})
+	}
+	for _, group := range groups.unmanaged {
+		plan.Unmanaged = append(plan.Unmanaged, PlannedChange{Action: "keep", Kind: "group", Name: group.Name, ID: group.ID})
+	}
+	for _, component := range components.toDelete {
+		plan.Changes = append(plan.Changes, PlannedChange{Action: "delete", Kind: "component", Name: component.Name, ID: component.ID})
+	}
+	for _, component := range components.toCreate {
+		plan.Changes = append(plan.Changes, PlannedChange{Action: "create", Kind: "component", Name: component.Name,
+			Changes: diffFields(statuspagetypes.Component{}, component)})
+	}
+	for _, component := range components.toModify {
+		plan.Changes = append(plan.Changes, PlannedChange{Action: "modify", Kind: "component", Name: component.Name, ID: component.ID,
+			Changes: diffFields(components.remoteComponentMap[component.Name], component)})
+	}
+	for _, group := range groups.toDelete {
+		plan.Changes = append(plan.Changes, PlannedChange{Action: "delete", Kind: "group", Name: group.Name, ID: group.ID})
+	}
+	for _, group := range groups.toCreate {
+		plan.Changes = append(plan.Changes, PlannedChange{Action: "create", Kind: "group", Name: group.Name,
+			Changes: diffFields(statuspagetypes.Group{}, withComponentNames(group, componentIDToName))})
+	}
+	for _, group := range groups.toModify {
+		plan.Changes = append(plan.Changes, PlannedChange{Action: "modify", Kind: "group", Name: group.Name, ID: group.ID,
+			Changes: diffFields(withComponentNames(groups.remoteGroupNameToGroup[group.Name], componentIDToName),
+				withComponentNames(group, componentIDToName))})
+	}
+	return &plan, nil
+}
+
+// diffFields lists the fields of two structs of the same type that differ, named by their JSON tags
+func diffFields(from interface{}, to interface{}) []FieldChange {
+	var changes []FieldChange
+	fromValue, toValue := reflect.ValueOf(from), reflect.ValueOf(to)
+	for i := 0; i < fromValue.NumField(); i++ {
+		fromField, toField := fromValue.Field(i).Interface(), toValue.Field(i).Interface()
+		if !reflect.DeepEqual(fromField, toField) {
+			name := strings.Split(fromValue.Type().Field(i).Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = fromValue.Type().Field(i).Name
+			}
+			changes = append(changes, FieldChange{Field: name, From: fromField, To: toField})
+		}
+	}
+	return changes
+}
+
+// withComponentNames copies the group, replacing component IDs with names where known so plans are readable
+func withComponentNames(group statuspagetypes.Group, componentIDToName map[string]string) statuspagetypes.Group {
+	names := make([]string, 0, len(group.Components))
+	for _, id := range group.Components {
+		if name, found := componentIDToName[id]; found {
+			names = append(names, name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	sort.Strings(names)
+	group.Components = names
+	return group
+}
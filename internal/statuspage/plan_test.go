@@ -0,0 +1,169 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	config := emptyTestConfig
+	config.Statuspage.AllowDelete = true
+	config.Statuspage.Components = []configuration.Component{
+		{Name: "Same", Description: "Same description"},
+		{Name: "Modified", Description: "New description"},
+		{Name: "New", Description: "A new component"},
+	}
+	config.Statuspage.Groups = []configuration.ComponentGroup{
+		{Name: "Modified group", ComponentNames: []string{"Same", "New"}},
+		{Name: "Created group", ComponentNames: []string{"Modified"}},
+	}
+	components := map[string]statuspagetypes.Component{
+		"1": {Name: "Modified", Description: "Old description", Showcase: true, Status: "operational", ID: "1", Position: 1},
+		"2": {Name: "Same", Description: "Same description", Showcase: true, Status: "operational", ID: "2", Position: 2},
+		"3": {Name: "Deleted", Description: "To be deleted", Showcase: true, Status: "operational", ID: "3", Position: 3},
+	}
+	groups := map[string]statuspagetypes.Group{
+		"4": {ID: "4", Name: "Modified group", Components: []string{"2"}, Position: 1},
+		"5": {ID: "5", Name: "Deleted group", Components: []string{"3"}, Position: 2},
+	}
+	want := &Plan{Changes: []PlannedChange{
+		{Action: "delete", Kind: "component", Name: "Deleted", ID: "3"},
+		{Action: "create", Kind: "component", Name: "New", Changes: []FieldChange{
+			{Field: "description", From: "", To: "A new component"},
+			{Field: "name", From: "", To: "New"},
+			{Field: "position", From: 0, To: 2},
+			{Field: "showcase", From: false, To: true},
+			{Field: "status", From: "", To: "operational"},
+		}},
+		{Action: "modify", Kind: "component", Name: "Modified", ID: "1", Changes: []FieldChange{
+			{Field: "description", From: "Old description", To: "New description"},
+		}},
+		{Action: "modify", Kind: "component", Name: "Same", ID: "2", Changes: []FieldChange{
+			{Field: "position", From: 2, To: 1},
+		}},
+		{Action: "delete", Kind: "group", Name: "Deleted group", ID: "5"},
+		{Action: "create", Kind: "group", Name: "Created group", Changes: []FieldChange{
+			{Field: "components", From: []string(nil), To: []string{"Modified"}},
+			{Field: "name", From: "", To: "Created group"},
+			{Field: "position", From: 0, To: 2},
+		}},
+		{Action: "modify", Kind: "group", Name: "Modified group", ID: "4", Changes: []FieldChange{
+			{Field: "components", From: []string{"Same"}, To: []string{"New", "Same"}},
+		}},
+	}}
+
+	client := statuspageapi.Client(&config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	// The group mock serves only component names and IDs, so the component mock must take precedence by
+	// being registered first
+	componentIDToName := make(map[string]string)
+	for id, component := range components {
+		componentIDToName[id] = component.Name
+	}
+	statuspagemocks.ConfigureComponentMock(&config, components)
+	statuspagemocks.ConfigureGroupMock(&config, componentIDToName, groups)
+	got, err := DryRun(&config, client)
+	calls := httpmock.GetCallCountInfo()
+	httpmock.DeactivateAndReset()
+	if err != nil {
+		t.Errorf("DryRun() error = %v", err)
+		return
+	}
+	for call, count := range calls {
+		if count > 0 && !strings.HasPrefix(call, "GET") {
+			t.Errorf("DryRun() made a mutating request %s", call)
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DryRun() mismatch (-want +got):\n%s", diff)
+	}
+	if !got.HasDrift() {
+		t.Errorf("HasDrift() = false, want true")
+	}
+}
+
+func TestPlan_HasDrift(t *testing.T) {
+	tests := []struct {
+		name string
+		plan Plan
+		want bool
+	}{
+		{
+			name: "No changes",
+			plan: Plan{},
+			want: false,
+		},
+		{
+			name: "Changes",
+			plan: Plan{Changes: []PlannedChange{{Action: "delete", Kind: "component", Name: "Deleted", ID: "3"}}},
+			want: true,
+		},
+		{
+			name: "Only unmanaged resources",
+			plan: Plan{Unmanaged: []PlannedChange{{Action: "keep", Kind: "component", Name: "Manual", ID: "5"}}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.plan.HasDrift(); got != tt.want {
+				t.Errorf("HasDrift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlan_ToText(t *testing.T) {
+	tests := []struct {
+		name string
+		plan Plan
+		want string
+	}{
+		{
+			name: "No changes",
+			plan: Plan{},
+			want: "No changes: Statuspage matches the configuration.\n",
+		},
+		{
+			name: "Changes",
+			plan: Plan{Changes: []PlannedChange{
+				{Action: "delete", Kind: "component", Name: "Deleted", ID: "3"},
+				{Action: "create", Kind: "group", Name: "New", Changes: []FieldChange{
+					{Field: "components", From: []string(nil), To: []string{"A", "B"}},
+				}},
+				{Action: "modify", Kind: "component", Name: "Modified", ID: "2", Changes: []FieldChange{
+					{Field: "showcase", From: false, To: true},
+				}},
+			}},
+			want: `- delete component "Deleted" (3)
++ create group "New"
+    components: ["A","B"]
+~ modify component "Modified" (2)
+    showcase: false -> true
+Plan: 1 to delete, 1 to create, 1 to modify.
+`,
+		},
+		{
+			name: "Unmanaged resources",
+			plan: Plan{Unmanaged: []PlannedChange{
+				{Action: "keep", Kind: "component", Name: "Manual", ID: "5"},
+			}},
+			want: `! keep component "Manual" (5): not configured, but the deletion policy prevents deleting it
+Plan: 0 to delete, 0 to create, 0 to modify.
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.plan.ToText()); diff != "" {
+				t.Errorf("ToText() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
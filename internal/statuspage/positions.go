@@ -0,0 +1,48 @@
+package statuspage
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/shared"
+)
+
+// pagePositions computes where each configured component and group goes on the page. Statuspage orders groups
+// and ungrouped components together at the top level, and grouped components within their group. Ungrouped
+// components come first, in the order of Statuspage.Components, followed by groups in the order of
+// Statuspage.Groups; each group's components are ordered within it as they're listed in Statuspage.Components.
+func pagePositions(config *configuration.Config) (componentPositions map[string]int, groupPositions map[string]int) {
+	componentToGroup := make(map[string]string)
+	for _, group := range config.Statuspage.Groups {
+		for _, componentName := range group.ComponentNames {
+			componentToGroup[componentName] = group.Name
+		}
+	}
+	componentPositions = make(map[string]int)
+	groupPositions = make(map[string]int)
+	topLevel := 0
+	withinGroup := make(map[string]int)
+	for _, component := range config.Statuspage.Components {
+		if groupName, grouped := componentToGroup[component.Name]; grouped {
+			withinGroup[groupName]++
+			componentPositions[component.Name] = withinGroup[groupName]
+		} else {
+			topLevel++
+			componentPositions[component.Name] = topLevel
+		}
+	}
+	for _, group := range config.Statuspage.Groups {
+		topLevel++
+		groupPositions[group.Name] = topLevel
+	}
+	return componentPositions, groupPositions
+}
+
+// warnIfNotPositioned logs if Statuspage's response shows that it didn't apply the position it was sent. Statuspage's
+// API reference doesn't list position among the fields it accepts, so this is how it would show that positions are
+// ignored rather than that the page is out of order.
+func warnIfNotPositioned(config *configuration.Config, kind string, name string, sent int, got int) {
+	if sent != 0 && sent != got {
+		shared.LogLn(config, fmt.Sprintf("statuspage didn't apply position %d to %s %s, it's at %d instead",
+			sent, kind, name, got))
+	}
+}
@@ -0,0 +1,44 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func Test_pagePositions(t *testing.T) {
+	config := emptyTestConfig
+	config.Statuspage.Components = []configuration.Component{
+		{Name: "Ungrouped"},
+		{Name: "Notebooks"},
+		{Name: "Also ungrouped"},
+		{Name: "Workspaces"},
+		{Name: "UI"},
+	}
+	config.Statuspage.Groups = []configuration.ComponentGroup{
+		{Name: "Empty", ComponentNames: []string{"Missing"}},
+		{Name: "Terra", ComponentNames: []string{"UI", "Notebooks"}},
+		{Name: "Data", ComponentNames: []string{"Workspaces"}},
+	}
+	// Ungrouped components come first, then groups in their own order even though that differs from the order
+	// of their components
+	wantComponents := map[string]int{
+		"Ungrouped":      1,
+		"Notebooks":      1,
+		"Also ungrouped": 2,
+		"Workspaces":     1,
+		"UI":             2,
+	}
+	wantGroups := map[string]int{
+		"Empty": 3,
+		"Terra": 4,
+		"Data":  5,
+	}
+	gotComponents, gotGroups := pagePositions(&config)
+	if diff := cmp.Diff(wantComponents, gotComponents); diff != "" {
+		t.Errorf("pagePositions() components mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantGroups, gotGroups); diff != "" {
+		t.Errorf("pagePositions() groups mismatch (-want +got):\n%s", diff)
+	}
+}
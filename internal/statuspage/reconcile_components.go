@@ -9,6 +9,7 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/mitchellh/mapstructure"
 	"reflect"
+	"sort"
 )
 
 // listComponentsToDelete provides a slice of remote components that don't correlate to an entry in the configuration
@@ -25,10 +26,12 @@ func listComponentsToDelete(
 	return componentsToDelete
 }
 
-// listComponentsToCreate provides a slice of components that aren't present on the remote
+// listComponentsToCreate provides a slice of components that aren't present on the remote.
+// Components are given their position from the name-position map, if present.
 func listComponentsToCreate(
 	configComponentMap map[string]configuration.Component,
 	remoteComponentMap map[string]statuspagetypes.Component,
+	positions map[string]int,
 ) []statuspagetypes.Component {
 	var componentsToCreate []statuspagetypes.Component
 	for name, configComponent := range configComponentMap {
@@ -37,16 +40,21 @@ func listComponentsToCreate(
 			statuspagetypes.MergeConfigComponentToApi(configComponent, &newComponent)
 			// We specifically don't want status to be influenced by configuration file; components start out operational
 			newComponent.Status = "operational"
+			if position, present := positions[name]; present {
+				newComponent.Position = position
+			}
 			componentsToCreate = append(componentsToCreate, newComponent)
 		}
 	}
 	return componentsToCreate
 }
 
-// listComponentsToModify provides a slice of components that should be modified on the remote
+// listComponentsToModify provides a slice of components that should be modified on the remote.
+// Components are also modified if their position differs from that in the name-position map, if present.
 func listComponentsToModify(
 	configComponentMap map[string]configuration.Component,
 	remoteComponentMap map[string]statuspagetypes.Component,
+	positions map[string]int,
 ) ([]statuspagetypes.Component, error) {
 	var componentsToModify []statuspagetypes.Component
 	for name, configComponent := range configComponentMap {
@@ -57,6 +65,9 @@ func listComponentsToModify(
 				return nil, fmt.Errorf("error decoding statuspage.Component to statuspage.Component: %w", err)
 			}
 			statuspagetypes.MergeConfigComponentToApi(configComponent, &modifiedComponent)
+			if position, present := positions[name]; present {
+				modifiedComponent.Position = position
+			}
 			// if remote component is different from remote+configuration component, it must be modified
 			if !reflect.DeepEqual(remoteComponent, modifiedComponent) {
 				componentsToModify = append(componentsToModify, modifiedComponent)
@@ -66,55 +77,130 @@ func listComponentsToModify(
 	return componentsToModify, nil
 }
 
-// ReconcileComponents modifies the set of components on Statuspage.io to match what is given in the config file.
-// It creates statuspage.Component slices for deletion, creation, and modification, and then hands that data
-// to the correct functions in statuspage/component_api.go
-func ReconcileComponents(config *configuration.Config, client *resty.Client) error {
+// componentChanges holds what ReconcileComponents would change on the remote, along with what it was based on
+type componentChanges struct {
+	toDelete []statuspagetypes.Component
+	// unmanaged components aren't in the config but may not be deleted per the deletion policy
+	unmanaged          []statuspagetypes.Component
+	toCreate           []statuspagetypes.Component
+	toModify           []statuspagetypes.Component
+	configComponentMap map[string]configuration.Component
+	remoteComponentMap map[string]statuspagetypes.Component
+	managed            *managedResources
+}
+
+// planComponents computes the changes necessary for the remote components to match the config file, without
+// making them
+func planComponents(config *configuration.Config, client *resty.Client) (*componentChanges, error) {
 	statuspageComponents, err := statuspageapi.GetComponents(client, config.Statuspage.PageID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	statuspageComponentMap := make(map[string]statuspagetypes.Component)
 	for _, statuspageComponent := range *statuspageComponents {
 		statuspageComponentMap[statuspageComponent.Name] = statuspageComponent
 	}
+	// Renamed components need to be found under their new name, so they're modified rather than replaced
+	statuspageComponentMap = rekeyRemoteComponents(config.Statuspage.Components, statuspageComponentMap)
 	configComponentMap := make(map[string]configuration.Component)
 	for _, configComponent := range config.Statuspage.Components {
 		configComponentMap[configComponent.Name] = configComponent
 	}
+	positions, _ := pagePositions(config)
+
+	toModify, err := listComponentsToModify(configComponentMap, statuspageComponentMap, positions)
+	if err != nil {
+		return nil, err
+	}
+	managed, err := loadManagedResources(managedResourcesPath(config))
+	if err != nil {
+		return nil, err
+	}
+	changes := &componentChanges{
+		toCreate:           listComponentsToCreate(configComponentMap, statuspageComponentMap, positions),
+		toModify:           toModify,
+		configComponentMap: configComponentMap,
+		remoteComponentMap: statuspageComponentMap,
+		managed:            managed,
+	}
+	for _, component := range listComponentsToDelete(configComponentMap, statuspageComponentMap) {
+		if mayDelete(config, managed.Components, component.ID) {
+			changes.toDelete = append(changes.toDelete, component)
+		} else {
+			changes.unmanaged = append(changes.unmanaged, component)
+		}
+	}
+	// Deletions are made in a consistent order, so that plans are repeatable
+	sort.Sort(statuspagetypes.ComponentSort(changes.toDelete))
+	sort.Sort(statuspagetypes.ComponentSort(changes.unmanaged))
+	// Statuspage shifts other components to make room for a position, so set them from first to last; positions
+	// within different groups may be equal, so those are set by name
+	sort.Slice(changes.toCreate, func(i, j int) bool {
+		if changes.toCreate[i].Position != changes.toCreate[j].Position {
+			return changes.toCreate[i].Position < changes.toCreate[j].Position
+		}
+		return changes.toCreate[i].Name < changes.toCreate[j].Name
+	})
+	sort.Slice(changes.toModify, func(i, j int) bool {
+		if changes.toModify[i].Position != changes.toModify[j].Position {
+			return changes.toModify[i].Position < changes.toModify[j].Position
+		}
+		return changes.toModify[i].Name < changes.toModify[j].Name
+	})
+	return changes, nil
+}
 
-	toDelete := listComponentsToDelete(configComponentMap, statuspageComponentMap)
-	toCreate := listComponentsToCreate(configComponentMap, statuspageComponentMap)
-	toModify, err := listComponentsToModify(configComponentMap, statuspageComponentMap)
+// ReconcileComponents modifies the set of components on Statuspage.io to match what is given in the config file.
+// It creates statuspage.Component slices for deletion, creation, and modification, and then hands that data
+// to the correct functions in statuspage/component_api.go
+func ReconcileComponents(config *configuration.Config, client *resty.Client) error {
+	changes, err := planComponents(config, client)
 	if err != nil {
 		return err
 	}
 
-	for _, component := range toDelete {
+	for _, component := range changes.unmanaged {
+		shared.LogLn(config, fmt.Sprintf("not deleting %s component (%s) from statuspage even though it isn't configured, "+
+			"per the %s deletion policy", component.Name, component.ID, config.Statuspage.DeletionPolicy))
+	}
+	for _, component := range changes.toDelete {
 		shared.LogLn(config, fmt.Sprintf("deleting %s component from statuspage", component.Name),
 			fmt.Sprintf(" - deleting: %+v", component))
 		err := statuspageapi.DeleteComponent(client, config.Statuspage.PageID, component.ID)
 		if err != nil {
 			return err
 		}
+		changes.managed.Components = without(changes.managed.Components, component.ID)
+		if err := changes.managed.save(); err != nil {
+			return err
+		}
 	}
-	for _, component := range toCreate {
+	for _, component := range changes.toCreate {
 		shared.LogLn(config, fmt.Sprintf("creating %s component on statuspage", component.Name),
 			fmt.Sprintf(" - new: %+v", component))
-		_, err := statuspageapi.PostComponent(client, config.Statuspage.PageID, component)
+		created, err := statuspageapi.PostComponent(client, config.Statuspage.PageID, component)
 		if err != nil {
 			return err
 		}
+		warnIfNotPositioned(config, "component", component.Name, component.Position, created.Position)
+		changes.managed.Components = append(changes.managed.Components, created.ID)
+		if err := changes.managed.save(); err != nil {
+			return err
+		}
 	}
-	for _, component := range toModify {
+	for _, component := range changes.toModify {
+		if remoteName := changes.remoteComponentMap[component.Name].Name; remoteName != component.Name {
+			shared.LogLn(config, fmt.Sprintf("renaming %s component on statuspage to %s", remoteName, component.Name))
+		}
 		shared.LogLn(config, fmt.Sprintf("modifying %s component on statuspage", component.Name),
-			fmt.Sprintf(" - config: %+v", configComponentMap[component.Name]),
-			fmt.Sprintf(" - remote: %+v", statuspageComponentMap[component.Name]),
+			fmt.Sprintf(" - config: %+v", changes.configComponentMap[component.Name]),
+			fmt.Sprintf(" - remote: %+v", changes.remoteComponentMap[component.Name]),
 			fmt.Sprintf(" - modified: %+v", component))
-		_, err := statuspageapi.PatchComponent(client, config.Statuspage.PageID, component.ID, component)
+		patched, err := statuspageapi.PatchComponent(client, config.Statuspage.PageID, component.ID, component)
 		if err != nil {
 			return err
 		}
+		warnIfNotPositioned(config, "component", component.Name, component.Position, patched.Position)
 	}
 
 	return nil
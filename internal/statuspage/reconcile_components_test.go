@@ -20,16 +20,21 @@ func TestReconcileComponents(t *testing.T) {
 	config := configuration.Config{
 		Verbose: false,
 		Client: struct {
-			Redirects int
-			Retries   int
+			Redirects           int
+			Retries             int
+			MaxRetryWaitSeconds int
+			RequestsPerSecond   float64
 		}{Redirects: 0, Retries: 0},
 		Statuspage: struct {
-			ApiKey     string `validate:"required"`
-			PageID     string `validate:"required"`
-			ApiRoot    string
-			Components []configuration.Component      `validate:"unique=Name,dive"`
-			Groups     []configuration.ComponentGroup `validate:"unique=Name,dive"`
-		}{ApiKey: "key", PageID: "foo", ApiRoot: "https://localhost",
+			ApiKey               string `validate:"required"`
+			PageID               string `validate:"required"`
+			ApiRoot              string
+			Components           []configuration.Component      `validate:"unique=Name,dive"`
+			Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+			DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+			AllowDelete          bool
+			ManagedResourcesFile string
+		}{ApiKey: "key", PageID: "foo", ApiRoot: "https://localhost", DeletionPolicy: "flag", AllowDelete: true,
 			Components: []configuration.Component{
 				{Name: "Same", Description: "Same description"},
 				{Name: "Modified", Description: "New description"},
@@ -53,14 +58,14 @@ func TestReconcileComponents(t *testing.T) {
 				client: client,
 			},
 			start: map[string]statuspagetypes.Component{
-				"1": {Name: "Same", Description: "Same description", Showcase: true, Status: "operational", ID: "1", PageID: "foo"},
-				"2": {Name: "Modified", Description: "Old description", Showcase: true, Status: "operational", ID: "2", PageID: "foo"},
+				"1": {Name: "Same", Description: "Same description", Showcase: true, Status: "operational", ID: "1", PageID: "foo", Position: 1},
+				"2": {Name: "Modified", Description: "Old description", Showcase: true, Status: "operational", ID: "2", PageID: "foo", Position: 2},
 				"3": {Name: "Deleted", Description: "To be deleted", Showcase: true, Status: "operational", ID: "3", PageID: "foo"},
 			},
 			end: map[string]statuspagetypes.Component{
-				"1": {Name: "Same", Description: "Same description", Showcase: true, Status: "operational", ID: "1", PageID: "foo"},
-				"2": {Name: "Modified", Description: "New description", Showcase: true, Status: "operational", ID: "2", PageID: "foo"},
-				"4": {Name: "New", Description: "A new component", Showcase: true, Status: "operational", ID: "4", PageID: "foo"},
+				"1": {Name: "Same", Description: "Same description", Showcase: true, Status: "operational", ID: "1", PageID: "foo", Position: 1},
+				"2": {Name: "Modified", Description: "New description", Showcase: true, Status: "operational", ID: "2", PageID: "foo", Position: 2},
+				"4": {Name: "New", Description: "A new component", Showcase: true, Status: "operational", ID: "4", PageID: "foo", Position: 3},
 			},
 		},
 	}
@@ -119,7 +124,7 @@ func Test_listComponentsToCreate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := listComponentsToCreate(tt.args.configComponentMap, tt.args.remoteComponentMap)
+			got := listComponentsToCreate(tt.args.configComponentMap, tt.args.remoteComponentMap, nil)
 			sort.Sort(statuspagetypes.ComponentSort(got))
 			sort.Sort(statuspagetypes.ComponentSort(tt.want))
 			if diff := cmp.Diff(tt.want, got); diff != "" {
@@ -229,7 +234,7 @@ func Test_listComponentsToModify(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := listComponentsToModify(tt.args.configComponentMap, tt.args.remoteComponentMap)
+			got, err := listComponentsToModify(tt.args.configComponentMap, tt.args.remoteComponentMap, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listComponentsToModify() error = %v, wantErr %v", err, tt.wantErr)
 				return
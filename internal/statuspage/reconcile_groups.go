@@ -65,6 +65,7 @@ func listGroupsToCreate(
 	configGroupMap map[string]configuration.ComponentGroup,
 	remoteGroupMap map[string]statuspagetypes.Group,
 	componentNameToID map[string]string,
+	positions map[string]int,
 ) ([]statuspagetypes.Group, error) {
 	var groupsToCreate []statuspagetypes.Group
 	for name, configGroup := range configGroupMap {
@@ -74,6 +75,9 @@ func listGroupsToCreate(
 			if err != nil {
 				return nil, err
 			}
+			if position, present := positions[name]; present {
+				newGroup.Position = position
+			}
 			groupsToCreate = append(groupsToCreate, newGroup)
 		}
 	}
@@ -84,6 +88,7 @@ func listGroupsToModify(
 	configGroupMap map[string]configuration.ComponentGroup,
 	remoteGroupMap map[string]statuspagetypes.Group,
 	componentNameToID map[string]string,
+	positions map[string]int,
 ) ([]statuspagetypes.Group, error) {
 	var groupsToModify []statuspagetypes.Group
 	for name, configGroup := range configGroupMap {
@@ -98,6 +103,9 @@ func listGroupsToModify(
 			if err != nil {
 				return nil, err
 			}
+			if position, present := positions[name]; present {
+				modifiedGroup.Position = position
+			}
 			// if remote group is different from remote+configuration group, it must be modified
 			if !reflect.DeepEqual(remoteGroup, modifiedGroup) {
 				groupsToModify = append(groupsToModify, modifiedGroup)
@@ -107,57 +115,133 @@ func listGroupsToModify(
 	return groupsToModify, nil
 }
 
-func ReconcileGroups(config *configuration.Config, client *resty.Client) error {
-	componentNameToID, err := makeComponentMapping(client, config.Statuspage.PageID)
-	if err != nil {
-		return err
-	}
+// groupChanges holds what ReconcileGroups would change on the remote, along with what it was based on
+type groupChanges struct {
+	toDelete []statuspagetypes.Group
+	// unmanaged groups aren't in the config but may not be deleted per the deletion policy
+	unmanaged              []statuspagetypes.Group
+	toCreate               []statuspagetypes.Group
+	toModify               []statuspagetypes.Group
+	configGroupNameToGroup map[string]configuration.ComponentGroup
+	remoteGroupNameToGroup map[string]statuspagetypes.Group
+	managed                *managedResources
+}
 
+// planGroups computes the changes necessary for the remote groups to match the config file, without making
+// them. Component IDs for groups are looked up in componentNameToID.
+func planGroups(config *configuration.Config, client *resty.Client, componentNameToID map[string]string) (*groupChanges, error) {
 	statuspageGroupNameToGroup, err := makeStatuspageGroupMapping(client, config.Statuspage.PageID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	// Renamed groups need to be found under their new name, so they're modified rather than replaced
+	statuspageGroupNameToGroup = rekeyRemoteGroups(config.Statuspage.Groups, statuspageGroupNameToGroup)
 	configGroupNameToGroup := make(map[string]configuration.ComponentGroup)
 	for _, configGroup := range config.Statuspage.Groups {
 		configGroupNameToGroup[configGroup.Name] = configGroup
 	}
+	_, positions := pagePositions(config)
 
-	toDelete := listGroupsToDelete(configGroupNameToGroup, statuspageGroupNameToGroup)
-	toCreate, err := listGroupsToCreate(configGroupNameToGroup, statuspageGroupNameToGroup, componentNameToID)
+	toCreate, err := listGroupsToCreate(configGroupNameToGroup, statuspageGroupNameToGroup, componentNameToID, positions)
+	if err != nil {
+		return nil, err
+	}
+	toModify, err := listGroupsToModify(configGroupNameToGroup, statuspageGroupNameToGroup, componentNameToID, positions)
+	if err != nil {
+		return nil, err
+	}
+	managed, err := loadManagedResources(managedResourcesPath(config))
+	if err != nil {
+		return nil, err
+	}
+	changes := &groupChanges{
+		toCreate:               toCreate,
+		toModify:               toModify,
+		configGroupNameToGroup: configGroupNameToGroup,
+		remoteGroupNameToGroup: statuspageGroupNameToGroup,
+		managed:                managed,
+	}
+	for _, group := range listGroupsToDelete(configGroupNameToGroup, statuspageGroupNameToGroup) {
+		if mayDelete(config, managed.Groups, group.ID) {
+			changes.toDelete = append(changes.toDelete, group)
+		} else {
+			changes.unmanaged = append(changes.unmanaged, group)
+		}
+	}
+	// Deletions are made in a consistent order, so that plans are repeatable
+	sort.Sort(statuspagetypes.GroupSort(changes.toDelete))
+	sort.Sort(statuspagetypes.GroupSort(changes.unmanaged))
+	// Statuspage shifts other groups to make room for a position, so set them from first to last; positions
+	// within different groups may be equal, so those are set by name
+	sort.Slice(changes.toCreate, func(i, j int) bool {
+		if changes.toCreate[i].Position != changes.toCreate[j].Position {
+			return changes.toCreate[i].Position < changes.toCreate[j].Position
+		}
+		return changes.toCreate[i].Name < changes.toCreate[j].Name
+	})
+	sort.Slice(changes.toModify, func(i, j int) bool {
+		if changes.toModify[i].Position != changes.toModify[j].Position {
+			return changes.toModify[i].Position < changes.toModify[j].Position
+		}
+		return changes.toModify[i].Name < changes.toModify[j].Name
+	})
+	return changes, nil
+}
+
+func ReconcileGroups(config *configuration.Config, client *resty.Client) error {
+	componentNameToID, err := makeComponentMapping(client, config.Statuspage.PageID)
 	if err != nil {
 		return err
 	}
-	toModify, err := listGroupsToModify(configGroupNameToGroup, statuspageGroupNameToGroup, componentNameToID)
+
+	changes, err := planGroups(config, client, componentNameToID)
 	if err != nil {
 		return err
 	}
 
-	for _, group := range toDelete {
+	for _, group := range changes.unmanaged {
+		shared.LogLn(config, fmt.Sprintf("not deleting %s group (%s) from statuspage even though it isn't configured, "+
+			"per the %s deletion policy", group.Name, group.ID, config.Statuspage.DeletionPolicy))
+	}
+	for _, group := range changes.toDelete {
 		shared.LogLn(config, fmt.Sprintf("deleting %s group from statuspage", group.Name),
 			fmt.Sprintf(" - deleting: %+v", group))
 		err := statuspageapi.DeleteGroup(client, config.Statuspage.PageID, group.ID)
 		if err != nil {
 			return err
 		}
+		changes.managed.Groups = without(changes.managed.Groups, group.ID)
+		if err := changes.managed.save(); err != nil {
+			return err
+		}
 	}
-	for _, group := range toCreate {
+	for _, group := range changes.toCreate {
 		shared.LogLn(config, fmt.Sprintf("creating %s group on statuspage", group.Name),
 			fmt.Sprintf(" - new: %+v", group))
-		_, err := statuspageapi.PostGroup(client, config.Statuspage.PageID, group)
+		created, err := statuspageapi.PostGroup(client, config.Statuspage.PageID, group)
 		if err != nil {
 			return err
 		}
+		warnIfNotPositioned(config, "group", group.Name, group.Position, created.Position)
+		changes.managed.Groups = append(changes.managed.Groups, created.ID)
+		if err := changes.managed.save(); err != nil {
+			return err
+		}
 	}
-	for _, group := range toModify {
+	for _, group := range changes.toModify {
+		if remoteName := changes.remoteGroupNameToGroup[group.Name].Name; remoteName != group.Name {
+			shared.LogLn(config, fmt.Sprintf("renaming %s group on statuspage to %s", remoteName, group.Name))
+		}
 		shared.LogLn(config, fmt.Sprintf("modifying %s group on statuspage", group.Name),
-			fmt.Sprintf(" - config: %+v", configGroupNameToGroup[group.Name]),
-			fmt.Sprintf(" - remote: %+v", statuspageGroupNameToGroup[group.Name]),
+			fmt.Sprintf(" - config: %+v", changes.configGroupNameToGroup[group.Name]),
+			fmt.Sprintf(" - remote: %+v", changes.remoteGroupNameToGroup[group.Name]),
 			fmt.Sprintf(" - modified: %+v", group))
-		_, err := statuspageapi.PatchGroup(client, config.Statuspage.PageID, group.ID, group)
+		patched, err := statuspageapi.PatchGroup(client, config.Statuspage.PageID, group.ID, group)
 		if err != nil {
 			return err
 		}
+		warnIfNotPositioned(config, "group", group.Name, group.Position, patched.Position)
 	}
 
 	return nil
@@ -14,15 +14,20 @@ import (
 
 var emptyTestConfig = configuration.Config{
 	Client: struct {
-		Redirects int
-		Retries   int
+		Redirects           int
+		Retries             int
+		MaxRetryWaitSeconds int
+		RequestsPerSecond   float64
 	}{Redirects: 0, Retries: 0},
 	Statuspage: struct {
-		ApiKey     string `validate:"required"`
-		PageID     string `validate:"required"`
-		ApiRoot    string
-		Components []configuration.Component      `validate:"unique=Name,dive"`
-		Groups     []configuration.ComponentGroup `validate:"unique=Name,dive"`
+		ApiKey               string `validate:"required"`
+		PageID               string `validate:"required"`
+		ApiRoot              string
+		Components           []configuration.Component      `validate:"unique=Name,dive"`
+		Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+		DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+		AllowDelete          bool
+		ManagedResourcesFile string
 	}{ApiKey: "foo", PageID: "bar", ApiRoot: "https://localhost"},
 }
 
@@ -34,19 +39,26 @@ func TestReconcileGroups(t *testing.T) {
 	config := configuration.Config{
 		Verbose: false,
 		Client: struct {
-			Redirects int
-			Retries   int
+			Redirects           int
+			Retries             int
+			MaxRetryWaitSeconds int
+			RequestsPerSecond   float64
 		}{Redirects: 0, Retries: 0},
 		Statuspage: struct {
-			ApiKey     string `validate:"required"`
-			PageID     string `validate:"required"`
-			ApiRoot    string
-			Components []configuration.Component      `validate:"unique=Name,dive"`
-			Groups     []configuration.ComponentGroup `validate:"unique=Name,dive"`
+			ApiKey               string `validate:"required"`
+			PageID               string `validate:"required"`
+			ApiRoot              string
+			Components           []configuration.Component      `validate:"unique=Name,dive"`
+			Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+			DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+			AllowDelete          bool
+			ManagedResourcesFile string
 		}{
-			ApiKey:  "key",
-			PageID:  "foo",
-			ApiRoot: "https://localhost",
+			ApiKey:         "key",
+			PageID:         "foo",
+			ApiRoot:        "https://localhost",
+			DeletionPolicy: "flag",
+			AllowDelete:    true,
 			Components: []configuration.Component{
 				{Name: "A component"},
 				{Name: "B component"},
@@ -86,12 +98,12 @@ func TestReconcileGroups(t *testing.T) {
 			start: map[string]statuspagetypes.Group{
 				"1": {ID: "1", PageID: "foo", Name: "Modified group", Components: []string{"456"}},
 				"2": {ID: "2", PageID: "foo", Name: "Deleted group", Components: []string{"111"}},
-				"3": {ID: "3", PageID: "foo", Name: "Same group", Components: []string{"111"}},
+				"3": {ID: "3", PageID: "foo", Name: "Same group", Components: []string{"111"}, Position: 3},
 			},
 			end: map[string]statuspagetypes.Group{
-				"1": {ID: "1", PageID: "foo", Name: "Modified group", Components: []string{"123", "456"}},
-				"3": {ID: "3", PageID: "foo", Name: "Same group", Components: []string{"111"}},
-				"4": {ID: "4", PageID: "foo", Name: "Created group", Components: []string{"789"}},
+				"1": {ID: "1", PageID: "foo", Name: "Modified group", Components: []string{"123", "456"}, Position: 1},
+				"3": {ID: "3", PageID: "foo", Name: "Same group", Components: []string{"111"}, Position: 3},
+				"4": {ID: "4", PageID: "foo", Name: "Created group", Components: []string{"789"}, Position: 2},
 			},
 		},
 	}
@@ -163,7 +175,7 @@ func Test_listGroupsToCreate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := listGroupsToCreate(tt.args.configGroupMap, tt.args.remoteGroupMap, tt.args.componentNameToID)
+			got, err := listGroupsToCreate(tt.args.configGroupMap, tt.args.remoteGroupMap, tt.args.componentNameToID, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listGroupsToCreate() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -272,7 +284,7 @@ func Test_listGroupsToModify(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := listGroupsToModify(tt.args.configGroupMap, tt.args.remoteGroupMap, tt.args.componentNameToID)
+			got, err := listGroupsToModify(tt.args.configGroupMap, tt.args.remoteGroupMap, tt.args.componentNameToID, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listGroupsToModify() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,110 @@
+package statuspage
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+)
+
+// configIdentity holds what a configured component or group may be matched to the remote by
+type configIdentity struct {
+	name          string
+	previousNames []string
+	id            string
+}
+
+// matchRemoteKeys decides which remote entry each configured identity refers to, given the remote entries'
+// IDs by their current key (usually their name). It returns the key each remote entry should have so that
+// it lines up with its configured counterpart; unmatched remote entries keep their key unless a match took it.
+// Matching prefers a pinned ID, then the current name, then any previous name, so that a rename patches the
+// existing entry instead of replacing it.
+func matchRemoteKeys(identities []configIdentity, remoteKeyToID map[string]string) map[string]string {
+	remoteIDToKey := make(map[string]string)
+	for key, id := range remoteKeyToID {
+		remoteIDToKey[id] = key
+	}
+	// remote key -> config name, for each remote entry that's been matched
+	matched := make(map[string]string)
+	// config name -> whether it's been matched
+	satisfied := make(map[string]bool)
+	claim := func(remoteKey string, name string) {
+		matched[remoteKey] = name
+		satisfied[name] = true
+	}
+	for _, identity := range identities {
+		if key, found := remoteIDToKey[identity.id]; identity.id != "" && found {
+			claim(key, identity.name)
+		}
+	}
+	for _, identity := range identities {
+		if _, found := remoteKeyToID[identity.name]; found && !satisfied[identity.name] {
+			if _, taken := matched[identity.name]; !taken {
+				claim(identity.name, identity.name)
+			}
+		}
+	}
+	for _, identity := range identities {
+		for _, previousName := range identity.previousNames {
+			if _, found := remoteKeyToID[previousName]; found && !satisfied[identity.name] {
+				if _, taken := matched[previousName]; !taken {
+					claim(previousName, identity.name)
+				}
+			}
+		}
+	}
+
+	newKeys := make(map[string]string)
+	for key := range remoteKeyToID {
+		if name, found := matched[key]; found {
+			newKeys[key] = name
+		} else if satisfied[key] {
+			// Another remote entry was matched to the config entry with this name, so this one can't keep it
+			newKeys[key] = fmt.Sprintf("%s (%s)", key, remoteKeyToID[key])
+		} else {
+			newKeys[key] = key
+		}
+	}
+	return newKeys
+}
+
+// rekeyRemoteComponents re-keys the name-component mapping of the remote so components line up with their
+// configured counterparts, even if they've been renamed
+func rekeyRemoteComponents(
+	configComponents []configuration.Component,
+	remoteComponentMap map[string]statuspagetypes.Component,
+) map[string]statuspagetypes.Component {
+	var identities []configIdentity
+	for _, component := range configComponents {
+		identities = append(identities, configIdentity{component.Name, component.PreviousNames, component.ID})
+	}
+	remoteKeyToID := make(map[string]string)
+	for key, component := range remoteComponentMap {
+		remoteKeyToID[key] = component.ID
+	}
+	rekeyed := make(map[string]statuspagetypes.Component)
+	for oldKey, newKey := range matchRemoteKeys(identities, remoteKeyToID) {
+		rekeyed[newKey] = remoteComponentMap[oldKey]
+	}
+	return rekeyed
+}
+
+// rekeyRemoteGroups re-keys the name-group mapping of the remote so groups line up with their configured
+// counterparts, even if they've been renamed
+func rekeyRemoteGroups(
+	configGroups []configuration.ComponentGroup,
+	remoteGroupMap map[string]statuspagetypes.Group,
+) map[string]statuspagetypes.Group {
+	var identities []configIdentity
+	for _, group := range configGroups {
+		identities = append(identities, configIdentity{group.Name, group.PreviousNames, group.ID})
+	}
+	remoteKeyToID := make(map[string]string)
+	for key, group := range remoteGroupMap {
+		remoteKeyToID[key] = group.ID
+	}
+	rekeyed := make(map[string]statuspagetypes.Group)
+	for oldKey, newKey := range matchRemoteKeys(identities, remoteKeyToID) {
+		rekeyed[newKey] = remoteGroupMap[oldKey]
+	}
+	return rekeyed
+}
@@ -0,0 +1,130 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	"testing"
+)
+
+func Test_matchRemoteKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		identities    []configIdentity
+		remoteKeyToID map[string]string
+		want          map[string]string
+	}{
+		{
+			name:          "Matches by name",
+			identities:    []configIdentity{{name: "A"}},
+			remoteKeyToID: map[string]string{"A": "1", "B": "2"},
+			want:          map[string]string{"A": "A", "B": "B"},
+		},
+		{
+			name:          "Matches by previous name",
+			identities:    []configIdentity{{name: "New", previousNames: []string{"Older", "Old"}}},
+			remoteKeyToID: map[string]string{"Old": "1"},
+			want:          map[string]string{"Old": "New"},
+		},
+		{
+			name:          "Prefers current name to previous name",
+			identities:    []configIdentity{{name: "New", previousNames: []string{"Old"}}},
+			remoteKeyToID: map[string]string{"Old": "1", "New": "2"},
+			want:          map[string]string{"Old": "Old", "New": "New"},
+		},
+		{
+			name:          "Matches by pinned ID",
+			identities:    []configIdentity{{name: "New", id: "1"}},
+			remoteKeyToID: map[string]string{"Anything": "1"},
+			want:          map[string]string{"Anything": "New"},
+		},
+		{
+			name:          "Prefers pinned ID to current name",
+			identities:    []configIdentity{{name: "New", id: "1"}},
+			remoteKeyToID: map[string]string{"Anything": "1", "New": "2"},
+			want:          map[string]string{"Anything": "New", "New": "New (2)"},
+		},
+		{
+			name:          "Ignores missing pinned ID",
+			identities:    []configIdentity{{name: "New", id: "3"}},
+			remoteKeyToID: map[string]string{"New": "2"},
+			want:          map[string]string{"New": "New"},
+		},
+		{
+			name: "Doesn't match one remote twice",
+			identities: []configIdentity{
+				{name: "A", id: "1"},
+				{name: "B", previousNames: []string{"Old"}},
+			},
+			remoteKeyToID: map[string]string{"Old": "1"},
+			want:          map[string]string{"Old": "A"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchRemoteKeys(tt.identities, tt.remoteKeyToID)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("matchRemoteKeys() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileComponents_renames(t *testing.T) {
+	config := emptyTestConfig
+	config.Statuspage.AllowDelete = true
+	config.Statuspage.Components = []configuration.Component{
+		{Name: "Renamed", Description: "Same description", PreviousNames: []string{"Original"}},
+		{Name: "Pinned", Description: "Same description", ID: "2"},
+	}
+	client := statuspageapi.Client(&config)
+	components := map[string]statuspagetypes.Component{
+		"1": {ID: "1", PageID: "bar", Name: "Original", Description: "Same description", Position: 1, Showcase: true, Status: "major_outage"},
+		"2": {ID: "2", PageID: "bar", Name: "Whatever", Description: "Same description", Position: 2, Showcase: true, Status: "operational"},
+	}
+	httpmock.ActivateNonDefault(client.GetClient())
+	statuspagemocks.ConfigureComponentMock(&config, components)
+	err := ReconcileComponents(&config, client)
+	httpmock.DeactivateAndReset()
+	if err != nil {
+		t.Errorf("ReconcileComponents() error = %v", err)
+		return
+	}
+	want := map[string]statuspagetypes.Component{
+		"1": {ID: "1", PageID: "bar", Name: "Renamed", Description: "Same description", Position: 1, Showcase: true, Status: "major_outage"},
+		"2": {ID: "2", PageID: "bar", Name: "Pinned", Description: "Same description", Position: 2, Showcase: true, Status: "operational"},
+	}
+	if diff := cmp.Diff(want, components); diff != "" {
+		t.Errorf("ReconcileComponents() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReconcileGroups_renames(t *testing.T) {
+	config := emptyTestConfig
+	config.Statuspage.AllowDelete = true
+	config.Statuspage.Components = []configuration.Component{{Name: "A component"}}
+	config.Statuspage.Groups = []configuration.ComponentGroup{
+		{Name: "Renamed group", PreviousNames: []string{"Original group"}, ComponentNames: []string{"A component"}},
+	}
+	client := statuspageapi.Client(&config)
+	groups := map[string]statuspagetypes.Group{
+		"1": {ID: "1", PageID: "bar", Name: "Original group", Components: []string{"123"}, Position: 1},
+	}
+	httpmock.ActivateNonDefault(client.GetClient())
+	statuspagemocks.ConfigureGroupMock(&config, map[string]string{"123": "A component"}, groups)
+	err := ReconcileGroups(&config, client)
+	httpmock.DeactivateAndReset()
+	if err != nil {
+		t.Errorf("ReconcileGroups() error = %v", err)
+		return
+	}
+	want := map[string]statuspagetypes.Group{
+		"1": {ID: "1", PageID: "bar", Name: "Renamed group", Components: []string{"123"}, Position: 1},
+	}
+	if diff := cmp.Diff(want, groups); diff != "" {
+		t.Errorf("ReconcileGroups() mismatch (-want +got):\n%s", diff)
+	}
+}
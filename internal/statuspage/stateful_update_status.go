@@ -1,22 +1,22 @@
 package statuspage
 
 import (
-	"github.com/broadinstitute/revere/internal/cloudmonitoring"
 	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
 	"github.com/broadinstitute/revere/internal/pubsub/pubsubtypes"
 	"github.com/broadinstitute/revere/internal/state"
-	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
-	"github.com/go-resty/resty/v2"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"time"
 )
 
 // StatusUpdater returns a function to handle a possible update against a single component.
 // The returned function is correctly typed to be called by pubsub.ReceiveMessages as a callback.
-func StatusUpdater(config *configuration.Config, appState *state.State, client *resty.Client) pubsubtypes.PerComponentHandler {
+func StatusUpdater(config *configuration.Config, appState *state.State) pubsubtypes.PerComponentHandler {
 
 	// StatusUpdater returns a function with arguments only for what changes per-component. Even though the function
-	// takes advantage of config/appState/client, it has a narrow signature in line with what the pubsub package
+	// takes advantage of config/appState, it has a narrow signature in line with what the pubsub package
 	// parses from an incoming message.
-	return func(componentName string, labels *cloudmonitoring.AlertLabels, incident *cloudmonitoring.MonitoringIncident) error {
+	return func(componentName string, event *events.AlertEvent) error {
 
 		// Within the StatusUpdater's returned function, we wrap all work inside the appState.UseComponent hook.
 		// 		This is a bit like a React useEffect hook! If that makes no sense, read on:
@@ -27,20 +27,45 @@ func StatusUpdater(config *configuration.Config, appState *state.State, client *
 		// component.
 		// 3. Because the entire body of this function is within the hook, this function does not need to worry about
 		// concurrency control.
-		// 4. **This eliminates a class of race conditions arising out of delay around status changes (both in-memory
-		// __and__ in communicating with Statuspage.io)**
+		// 4. **This eliminates a class of race conditions arising out of delay around status changes**
+		// 5. Nothing here waits on Statuspage.io, so the hook is quick: the StatusWriter and IncidentWriter
+		// communicate the result afterwards, each from its own routine
 		return appState.UseComponent(componentName, func(c *state.ComponentState) error {
 			var componentStatusChanged bool
-			if incident.HasEnded() {
-				componentStatusChanged = c.ResolveIncident(incident.IncidentID)
+			newAlert := !event.Ended && !c.HasOpenIncident(event.IncidentID)
+			if event.Ended {
+				componentStatusChanged = c.ResolveIncident(event.IncidentID)
 			} else {
-				componentStatusChanged = c.LogIncident(incident.IncidentID, labels.AlertType)
-			}
-			if componentStatusChanged {
-				_, err := statuspageapi.PatchComponentStatus(client, config.Statuspage.PageID, c.GetID(), c.GetDesiredStatus())
-				return err
+				componentStatusChanged = c.LogServiceIncident(event.IncidentID, event.ServiceName, event.Status)
+				c.DescribeIncident(event.IncidentID, event.Source, openedAt(event))
+				c.SummarizeIncident(event.IncidentID, event.Name, event.Summary, event.URL())
 			}
+			// If the component's status change was damped, it's published later instead
+			scheduleDampedStatus(config, appState, componentName, c, event)
+			publishDesiredStatus(config, c, event, componentStatusChanged, newAlert)
 			return nil
 		})
 	}
 }
+
+// openedAt returns when the alert started, falling back to the current time if the alert doesn't say
+func openedAt(event *events.AlertEvent) time.Time {
+	if !event.StartedAt.IsZero() {
+		return event.StartedAt
+	}
+	return time.Now().UTC()
+}
+
+// publishDesiredStatus records the alert as the cause of any change to the component's status and requests that
+// the component's Statuspage incident be synced, if enabled. It should be called from within a
+// state.State.UseComponent hook after the component's state was changed by the alert. Nothing is sent to
+// Statuspage within the hook: the component's status is patched by the StatusWriter and its incident by the
+// IncidentWriter. Components under maintenance don't have their incidents synced, since their Statuspage
+// scheduled incident already explains their status.
+func publishDesiredStatus(config *configuration.Config, c *state.ComponentState, event *events.AlertEvent,
+	componentStatusChanged bool, newAlert bool) {
+	c.SetCause(event)
+	if config.StatuspageIncidents.Enabled && c.GetDesiredStatus() != statuspagetypes.UnderMaintenance {
+		c.RequestIncidentSync(event, componentStatusChanged || newAlert)
+	}
+}
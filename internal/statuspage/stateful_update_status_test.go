@@ -1,8 +1,8 @@
 package statuspage
 
 import (
-	"github.com/broadinstitute/revere/internal/cloudmonitoring"
 	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/events"
 	"github.com/broadinstitute/revere/internal/state"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
@@ -15,15 +15,20 @@ func makeConfigHelper(components []configuration.Component, serviceToComponentMa
 	return &configuration.Config{
 		Verbose: false,
 		Client: struct {
-			Redirects int
-			Retries   int
+			Redirects           int
+			Retries             int
+			MaxRetryWaitSeconds int
+			RequestsPerSecond   float64
 		}{Redirects: 3, Retries: 3},
 		Statuspage: struct {
-			ApiKey     string `validate:"required"`
-			PageID     string `validate:"required"`
-			ApiRoot    string
-			Components []configuration.Component      `validate:"unique=Name,dive"`
-			Groups     []configuration.ComponentGroup `validate:"unique=Name,dive"`
+			ApiKey               string `validate:"required"`
+			PageID               string `validate:"required"`
+			ApiRoot              string
+			Components           []configuration.Component      `validate:"unique=Name,dive"`
+			Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+			DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+			AllowDelete          bool
+			ManagedResourcesFile string
 		}{
 			ApiKey: "foo", PageID: "bar", ApiRoot: "https://localhost",
 			Components: components,
@@ -40,8 +45,7 @@ func TestStatusUpdater(t *testing.T) {
 	}
 	type resultArgs struct {
 		componentName string
-		labels        *cloudmonitoring.AlertLabels
-		incident      *cloudmonitoring.MonitoringIncident
+		event         *events.AlertEvent
 	}
 	tests := []struct {
 		name string
@@ -77,10 +81,10 @@ func TestStatusUpdater(t *testing.T) {
 			},
 			resultArgs: resultArgs{
 				componentName: "a component",
-				labels:        &cloudmonitoring.AlertLabels{AlertType: statuspagetypes.MajorOutage},
-				incident: &cloudmonitoring.MonitoringIncident{
+				event: &events.AlertEvent{
 					IncidentID: "an-incident-id",
-					State:      "open",
+					Status:     statuspagetypes.MajorOutage,
+					Ended:      false,
 				},
 			},
 			wantStatus: statuspagetypes.MajorOutage,
@@ -113,10 +117,10 @@ func TestStatusUpdater(t *testing.T) {
 			},
 			resultArgs: resultArgs{
 				componentName: "a component",
-				labels:        &cloudmonitoring.AlertLabels{AlertType: statuspagetypes.MajorOutage},
-				incident: &cloudmonitoring.MonitoringIncident{
+				event: &events.AlertEvent{
 					IncidentID: "an-incident-id",
-					State:      "closed",
+					Status:     statuspagetypes.MajorOutage,
+					Ended:      true,
 				},
 			},
 			wantStatus: statuspagetypes.Operational,
@@ -150,10 +154,47 @@ func TestStatusUpdater(t *testing.T) {
 			},
 			resultArgs: resultArgs{
 				componentName: "a component",
-				labels:        &cloudmonitoring.AlertLabels{AlertType: statuspagetypes.MajorOutage},
-				incident: &cloudmonitoring.MonitoringIncident{
+				event: &events.AlertEvent{
 					IncidentID: "an-incident-id",
-					State:      "open",
+					Status:     statuspagetypes.MajorOutage,
+					Ended:      false,
+				},
+			},
+			wantStatus: statuspagetypes.MajorOutage,
+		},
+		{
+			name: "corrects remote status that drifted",
+			args: args{
+				config: makeConfigHelper(
+					[]configuration.Component{
+						{Name: "a component"},
+					},
+					[]configuration.ServiceToComponentMapping{
+						{
+							ServiceName: "a service", ServiceEnvironment: "an environment",
+							AffectsComponentsNamed: []string{"a component"},
+						},
+					}),
+				appStateSeed: map[string]string{
+					"a component": "a-component-id",
+				},
+				mockState: map[string]statuspagetypes.Component{
+					"a-component-id": {Name: "a component", ID: "a-component-id", Status: "operational"},
+				},
+			},
+			stateModifications: func(appState *state.State) {
+				_ = appState.UseComponent("a component", func(c *state.ComponentState) error {
+					// Pretend an earlier patch failed or someone edited the status on Statuspage
+					c.LogIncident("an-incident-id", statuspagetypes.MajorOutage)
+					return nil
+				})
+			},
+			resultArgs: resultArgs{
+				componentName: "a component",
+				event: &events.AlertEvent{
+					IncidentID: "an-incident-id",
+					Status:     statuspagetypes.MajorOutage,
+					Ended:      false,
 				},
 			},
 			wantStatus: statuspagetypes.MajorOutage,
@@ -180,10 +221,10 @@ func TestStatusUpdater(t *testing.T) {
 			},
 			resultArgs: resultArgs{
 				componentName: "a component",
-				labels:        &cloudmonitoring.AlertLabels{AlertType: statuspagetypes.MajorOutage},
-				incident: &cloudmonitoring.MonitoringIncident{
+				event: &events.AlertEvent{
 					IncidentID: "an-incident-id",
-					State:      "closed",
+					Status:     statuspagetypes.MajorOutage,
+					Ended:      true,
 				},
 			},
 			wantStatus: statuspagetypes.Operational,
@@ -217,10 +258,10 @@ func TestStatusUpdater(t *testing.T) {
 			},
 			resultArgs: resultArgs{
 				componentName: "a component",
-				labels:        &cloudmonitoring.AlertLabels{AlertType: statuspagetypes.MajorOutage},
-				incident: &cloudmonitoring.MonitoringIncident{
+				event: &events.AlertEvent{
 					IncidentID: "an-incident-id",
-					State:      "open",
+					Status:     statuspagetypes.MajorOutage,
+					Ended:      false,
 				},
 			},
 			wantStatus: statuspagetypes.MajorOutage,
@@ -254,10 +295,10 @@ func TestStatusUpdater(t *testing.T) {
 			},
 			resultArgs: resultArgs{
 				componentName: "a component",
-				labels:        &cloudmonitoring.AlertLabels{AlertType: statuspagetypes.DegradedPerformance},
-				incident: &cloudmonitoring.MonitoringIncident{
+				event: &events.AlertEvent{
 					IncidentID: "an-incident-id",
-					State:      "open",
+					Status:     statuspagetypes.DegradedPerformance,
+					Ended:      false,
 				},
 			},
 			wantStatus: statuspagetypes.PartialOutage,
@@ -292,10 +333,10 @@ func TestStatusUpdater(t *testing.T) {
 			},
 			resultArgs: resultArgs{
 				componentName: "a component",
-				labels:        &cloudmonitoring.AlertLabels{AlertType: statuspagetypes.PartialOutage},
-				incident: &cloudmonitoring.MonitoringIncident{
+				event: &events.AlertEvent{
 					IncidentID: "another-incident-id",
-					State:      "closed",
+					Status:     statuspagetypes.PartialOutage,
+					Ended:      true,
 				},
 			},
 			wantStatus: statuspagetypes.DegradedPerformance,
@@ -304,18 +345,28 @@ func TestStatusUpdater(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			appState := &state.State{}
-			appState.Seed(tt.args.appStateSeed)
+			var seed []statuspagetypes.Component
+			for name, id := range tt.args.appStateSeed {
+				seed = append(seed, statuspagetypes.Component{Name: name, ID: id, Status: "operational"})
+			}
+			if err := appState.Seed(seed); err != nil {
+				t.Errorf("unexpected Seed error %v", err)
+				return
+			}
 			if tt.stateModifications != nil {
 				tt.stateModifications(appState)
 			}
 			statuspageClient := statuspageapi.Client(tt.args.config)
 			httpmock.ActivateNonDefault(statuspageClient.GetClient())
 			statuspagemocks.ConfigureComponentMock(tt.args.config, tt.args.mockState)
-			callback := StatusUpdater(tt.args.config, appState, statuspageClient)
-			if err := callback(tt.resultArgs.componentName, tt.resultArgs.labels, tt.resultArgs.incident); (err != nil) != tt.wantErr {
+			callback := StatusUpdater(tt.args.config, appState)
+			if err := callback(tt.resultArgs.componentName, tt.resultArgs.event); (err != nil) != tt.wantErr {
 				t.Errorf("callback error %v", err)
 				return
 			}
+			if err := NewStatusWriter(tt.args.config, appState, statuspageClient).Write(); err != nil {
+				t.Errorf("StatusWriter.Write() error %v", err)
+			}
 			err := appState.UseComponent(tt.resultArgs.componentName, func(c *state.ComponentState) error {
 				// Check that the status got updated in the in-memory state
 				if c.GetDesiredStatus() != tt.wantStatus {
@@ -0,0 +1,80 @@
+package statuspage
+
+import (
+	"context"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/go-resty/resty/v2"
+	"time"
+)
+
+// StatusWriter converges the status of each component on Statuspage with what the appState desires. It runs
+// whenever a desired status changes and periodically regardless, so that failed patches are retried and manual
+// edits on Statuspage are corrected. It's Statuspage's outputs.Output.
+type StatusWriter struct {
+	config   *configuration.Config
+	appState *state.State
+	client   *resty.Client
+	// wake has room for one pending request to write, so requests made while writing aren't lost
+	wake chan struct{}
+}
+
+func NewStatusWriter(config *configuration.Config, appState *state.State, client *resty.Client) *StatusWriter {
+	return &StatusWriter{
+		config:   config,
+		appState: appState,
+		client:   client,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Name identifies the StatusWriter among Revere's outputs
+func (w *StatusWriter) Name() string {
+	return "statuspage"
+}
+
+// Notify asks the StatusWriter to write soon, without blocking. Every component's status is written,
+// not just the one that transitioned.
+func (w *StatusWriter) Notify(state.StatusTransition) error {
+	w.Wake()
+	return nil
+}
+
+// Wake asks the StatusWriter to write soon, without blocking
+func (w *StatusWriter) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run writes whenever woken and every StatusWriter.IntervalSeconds until the context is cancelled. Failures are
+// logged and retried next time.
+func (w *StatusWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(w.config.StatusWriter.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-ctx.Done():
+			return
+		}
+		if err := w.Write(); err != nil {
+			shared.LogLn(w.config, fmt.Sprintf("failed to write statuses to statuspage, will retry: %v", err))
+		}
+	}
+}
+
+// Write reads each component's status from Statuspage and patches those that differ from what the appState
+// desires.
+func (w *StatusWriter) Write() error {
+	remoteComponents, err := statuspageapi.GetComponents(w.client, w.config.Statuspage.PageID)
+	if err != nil {
+		return err
+	}
+	return PatchDriftedStatuses(w.config, w.appState, w.client, *remoteComponents)
+}
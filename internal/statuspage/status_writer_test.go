@@ -0,0 +1,52 @@
+package statuspage
+
+import (
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/state"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspageapi"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/jarcoal/httpmock"
+	"testing"
+)
+
+func TestStatusWriter_Write(t *testing.T) {
+	config := makeConfigHelper([]configuration.Component{{Name: "a component"}, {Name: "another component"}}, nil)
+	appState := &state.State{}
+	if err := appState.Seed([]statuspagetypes.Component{
+		{Name: "a component", ID: "a-component-id", Status: "operational"},
+		{Name: "another component", ID: "another-component-id", Status: "operational"},
+	}); err != nil {
+		t.Errorf("unexpected Seed error %v", err)
+		return
+	}
+	_ = appState.UseComponent("a component", func(c *state.ComponentState) error {
+		c.LogIncident("an-incident-id", statuspagetypes.MajorOutage)
+		return nil
+	})
+	mockState := map[string]statuspagetypes.Component{
+		// Patched since it's behind the appState
+		"a-component-id": {Name: "a component", ID: "a-component-id", Status: "operational"},
+		// Patched since it was edited on Statuspage
+		"another-component-id": {Name: "another component", ID: "another-component-id", Status: "partial_outage"},
+		// Left alone since the appState doesn't know about it
+		"new-component-id": {Name: "new component", ID: "new-component-id", Status: "degraded_performance"},
+	}
+	client := statuspageapi.Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	statuspagemocks.ConfigureComponentMock(config, mockState)
+	if err := NewStatusWriter(config, appState, client).Write(); err != nil {
+		t.Errorf("StatusWriter.Write() error %v", err)
+	}
+	httpmock.DeactivateAndReset()
+	want := map[string]string{
+		"a-component-id":       "major_outage",
+		"another-component-id": "operational",
+		"new-component-id":     "degraded_performance",
+	}
+	for id, wantStatus := range want {
+		if got := mockState[id].Status; got != wantStatus {
+			t.Errorf("remote status of %s %s, want %s", id, got, wantStatus)
+		}
+	}
+}
@@ -4,14 +4,41 @@ import (
 	"github.com/broadinstitute/revere/internal/configuration"
 	"github.com/broadinstitute/revere/internal/shared"
 	"github.com/go-resty/resty/v2"
+	"time"
 )
 
 // Client within the statuspage package contains Resty config specific to interacting
-// with statuspage.io
+// with statuspage.io.
+// Responses saying too many requests were made are retried, waiting as long as their Retry-After header says
+// (within Client.MaxRetryWaitSeconds); while waiting, no other requests are made by the client either. If
+// Client.RequestsPerSecond is set, requests are spaced out to stay within it.
 func Client(config *configuration.Config) *resty.Client {
-	return shared.BaseClient(config).
+	client := shared.BaseClient(config).
 		SetHostURL(config.Statuspage.ApiRoot).
 		SetAuthScheme("OAuth").
 		SetAuthToken(config.Statuspage.ApiKey).
-		SetHeader("Accept", "application/json")
+		SetHeader("Accept", "application/json").
+		AddRetryCondition(func(response *resty.Response, _ error) bool {
+			return isRateLimited(response)
+		})
+	var limiter *rateLimiter
+	if config.Client.RequestsPerSecond > 0 {
+		limiter = newRateLimiter(config.Client.RequestsPerSecond)
+		client.OnBeforeRequest(limiter.wait)
+	}
+	client.SetRetryAfter(func(client *resty.Client, response *resty.Response) (time.Duration, error) {
+		if !isRateLimited(response) {
+			return 0, nil
+		}
+		// Resty won't wait longer than its maximum, so other requests shouldn't be held up for longer either
+		wait := retryAfter(response, time.Now())
+		if wait > client.RetryMaxWaitTime {
+			wait = client.RetryMaxWaitTime
+		}
+		if limiter != nil && wait > 0 {
+			limiter.pauseUntil(time.Now().Add(wait))
+		}
+		return wait, nil
+	})
+	return client
 }
@@ -22,11 +22,14 @@ func TestClient(t *testing.T) {
 			args: args{
 				config: &configuration.Config{
 					Statuspage: struct {
-						ApiKey     string `validate:"required"`
-						PageID     string `validate:"required"`
-						ApiRoot    string
-						Components []configuration.Component      `validate:"unique=Name,dive"`
-						Groups     []configuration.ComponentGroup `validate:"unique=Name,dive"`
+						ApiKey               string `validate:"required"`
+						PageID               string `validate:"required"`
+						ApiRoot              string
+						Components           []configuration.Component      `validate:"unique=Name,dive"`
+						Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+						DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+						AllowDelete          bool
+						ManagedResourcesFile string
 					}{},
 				},
 			},
@@ -40,11 +43,14 @@ func TestClient(t *testing.T) {
 			args: args{
 				config: &configuration.Config{
 					Statuspage: struct {
-						ApiKey     string `validate:"required"`
-						PageID     string `validate:"required"`
-						ApiRoot    string
-						Components []configuration.Component      `validate:"unique=Name,dive"`
-						Groups     []configuration.ComponentGroup `validate:"unique=Name,dive"`
+						ApiKey               string `validate:"required"`
+						PageID               string `validate:"required"`
+						ApiRoot              string
+						Components           []configuration.Component      `validate:"unique=Name,dive"`
+						Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+						DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+						AllowDelete          bool
+						ManagedResourcesFile string
 					}{ApiKey: "foo"},
 				},
 			},
@@ -58,11 +64,14 @@ func TestClient(t *testing.T) {
 			args: args{
 				config: &configuration.Config{
 					Statuspage: struct {
-						ApiKey     string `validate:"required"`
-						PageID     string `validate:"required"`
-						ApiRoot    string
-						Components []configuration.Component      `validate:"unique=Name,dive"`
-						Groups     []configuration.ComponentGroup `validate:"unique=Name,dive"`
+						ApiKey               string `validate:"required"`
+						PageID               string `validate:"required"`
+						ApiRoot              string
+						Components           []configuration.Component      `validate:"unique=Name,dive"`
+						Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+						DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+						AllowDelete          bool
+						ManagedResourcesFile string
 					}{ApiRoot: "https://example.com"},
 				},
 			},
@@ -7,17 +7,25 @@ import (
 	"github.com/go-resty/resty/v2"
 )
 
-// GetComponents provides a slice of all components (NOT groups) on the remote page
+// GetComponents provides a slice of all components (NOT groups) on the remote page, across every page of results
 func GetComponents(client *resty.Client, pageID string) (*[]statuspagetypes.Component, error) {
-	resp, err := client.R().
-		SetResult([]statuspagetypes.Component{}).
-		Get(fmt.Sprintf("/pages/%s/components", pageID))
-	if err = shared.CheckResponse(resp, err); err != nil {
+	var componentsWithGroups []statuspagetypes.Component
+	err := getAllPages(client, func(request *resty.Request) (int, error) {
+		resp, err := request.
+			SetResult([]statuspagetypes.Component{}).
+			Get(fmt.Sprintf("/pages/%s/components", pageID))
+		if err = shared.CheckResponse(resp, err); err != nil {
+			return 0, err
+		}
+		page := *resp.Result().(*[]statuspagetypes.Component)
+		componentsWithGroups = append(componentsWithGroups, page...)
+		return len(page), nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	componentsWithGroups := resp.Result().(*[]statuspagetypes.Component)
-	componentsWithoutGroups := make([]statuspagetypes.Component, 0, len(*componentsWithGroups))
-	for _, component := range *componentsWithGroups {
+	componentsWithoutGroups := make([]statuspagetypes.Component, 0, len(componentsWithGroups))
+	for _, component := range componentsWithGroups {
 		if !component.Group {
 			componentsWithoutGroups = append(componentsWithoutGroups, component)
 		}
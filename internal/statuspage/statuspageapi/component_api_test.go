@@ -1,12 +1,15 @@
 package statuspageapi
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/broadinstitute/revere/internal/configuration"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/go-cmp/cmp"
 	"github.com/jarcoal/httpmock"
+	"net/http"
 	"testing"
 )
 
@@ -14,15 +17,20 @@ func testConfig() *configuration.Config {
 	return &configuration.Config{
 		Verbose: false,
 		Client: struct {
-			Redirects int
-			Retries   int
+			Redirects           int
+			Retries             int
+			MaxRetryWaitSeconds int
+			RequestsPerSecond   float64
 		}{Redirects: 3, Retries: 3},
 		Statuspage: struct {
-			ApiKey     string `validate:"required"`
-			PageID     string `validate:"required"`
-			ApiRoot    string
-			Components []configuration.Component      `validate:"unique=Name,dive"`
-			Groups     []configuration.ComponentGroup `validate:"unique=Name,dive"`
+			ApiKey               string `validate:"required"`
+			PageID               string `validate:"required"`
+			ApiRoot              string
+			Components           []configuration.Component      `validate:"unique=Name,dive"`
+			Groups               []configuration.ComponentGroup `validate:"unique=Name,dive"`
+			DeletionPolicy       string                         `validate:"oneof=never managed flag"`
+			AllowDelete          bool
+			ManagedResourcesFile string
 		}{ApiKey: "foo", PageID: "baz", ApiRoot: "https://localhost"},
 	}
 }
@@ -127,6 +135,35 @@ func TestGetComponents(t *testing.T) {
 	}
 }
 
+func TestGetComponents_paginated(t *testing.T) {
+	config := testConfig()
+	client := Client(config)
+	components := make(map[string]statuspagetypes.Component)
+	for i := 0; i < 2*perPage+50; i++ {
+		component := statuspagemocks.ComponentFactory(fmt.Sprintf("component %d", i))
+		component.PageID = config.Statuspage.PageID
+		components[component.ID] = *component
+	}
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	statuspagemocks.ConfigureComponentMock(config, components)
+	got, err := GetComponents(client, config.Statuspage.PageID)
+	if err != nil {
+		t.Fatalf("GetComponents() error = %v", err)
+	}
+	if len(*got) != len(components) {
+		t.Errorf("GetComponents() returned %d components, want %d", len(*got), len(components))
+	}
+	for _, component := range *got {
+		if diff := cmp.Diff(components[component.ID], component); diff != "" {
+			t.Errorf("GetComponents() mismatch (-want +got):\n%s", diff)
+		}
+	}
+	if calls := httpmock.GetTotalCallCount(); calls != 3 {
+		t.Errorf("GetComponents() made %d requests, want 3", calls)
+	}
+}
+
 func TestPatchComponent(t *testing.T) {
 	type args struct {
 		client      *resty.Client
@@ -324,3 +361,77 @@ func TestPatchComponentStatus(t *testing.T) {
 		})
 	}
 }
+
+// Component bodies are what Statuspage is sent, including the position prepare orders the page with, so they're
+// checked exactly rather than via the mock, which would accept anything
+func TestComponentRequestBodies(t *testing.T) {
+	config := testConfig()
+	component := statuspagetypes.Component{ID: "abc", Name: "Rawls", Description: "Workspaces", GroupID: "def",
+		Position: 3, Showcase: true, Status: "operational", CreatedAt: "ignored"}
+	wantComponent := map[string]interface{}{
+		"description":           "Workspaces",
+		"group_id":              "def",
+		"name":                  "Rawls",
+		"only_show_if_degraded": false,
+		"position":              float64(3),
+		"showcase":              true,
+		"status":                "operational",
+	}
+	tests := []struct {
+		name     string
+		method   string
+		call     func(client *resty.Client) error
+		wantBody map[string]interface{}
+	}{
+		{
+			name:   "PostComponent",
+			method: "POST",
+			call: func(client *resty.Client) error {
+				_, err := PostComponent(client, config.Statuspage.PageID, component)
+				return err
+			},
+			wantBody: map[string]interface{}{"component": wantComponent},
+		},
+		{
+			name:   "PatchComponent",
+			method: "PATCH",
+			call: func(client *resty.Client) error {
+				_, err := PatchComponent(client, config.Statuspage.PageID, component.ID, component)
+				return err
+			},
+			wantBody: map[string]interface{}{"component": wantComponent},
+		},
+		{
+			name:   "PatchComponentStatus",
+			method: "PATCH",
+			call: func(client *resty.Client) error {
+				_, err := PatchComponentStatus(client, config.Statuspage.PageID, component.ID, statuspagetypes.MajorOutage)
+				return err
+			},
+			wantBody: map[string]interface{}{"component": map[string]interface{}{"status": "major_outage"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := Client(config)
+			httpmock.ActivateNonDefault(client.GetClient())
+			var gotBody map[string]interface{}
+			httpmock.RegisterResponder(tt.method, fmt.Sprintf(`=~^%s/pages/baz/components`, config.Statuspage.ApiRoot),
+				func(request *http.Request) (*http.Response, error) {
+					if err := json.NewDecoder(request.Body).Decode(&gotBody); err != nil {
+						return httpmock.NewStringResponse(400, err.Error()), nil
+					}
+					return httpmock.NewJsonResponse(200, component)
+				})
+			err := tt.call(client)
+			httpmock.DeactivateAndReset()
+			if err != nil {
+				t.Errorf("%s() error = %v", tt.name, err)
+				return
+			}
+			if diff := cmp.Diff(tt.wantBody, gotBody); diff != "" {
+				t.Errorf("%s() request body mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
@@ -7,15 +7,24 @@ import (
 	"github.com/go-resty/resty/v2"
 )
 
-// GetGroups provides a slice of all groups on the remote page
+// GetGroups provides a slice of all groups on the remote page, across every page of results
 func GetGroups(client *resty.Client, pageID string) (*[]statuspagetypes.Group, error) {
-	resp, err := client.R().
-		SetResult([]statuspagetypes.Group{}).
-		Get(fmt.Sprintf("/pages/%s/component-groups", pageID))
-	if err = shared.CheckResponse(resp, err); err != nil {
+	groups := make([]statuspagetypes.Group, 0)
+	err := getAllPages(client, func(request *resty.Request) (int, error) {
+		resp, err := request.
+			SetResult([]statuspagetypes.Group{}).
+			Get(fmt.Sprintf("/pages/%s/component-groups", pageID))
+		if err = shared.CheckResponse(resp, err); err != nil {
+			return 0, err
+		}
+		page := *resp.Result().(*[]statuspagetypes.Group)
+		groups = append(groups, page...)
+		return len(page), nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return resp.Result().(*[]statuspagetypes.Group), nil
+	return &groups, nil
 }
 
 // PostGroup creates a new group on the remote page
@@ -1,11 +1,14 @@
 package statuspageapi
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
 	"github.com/go-resty/resty/v2"
 	"github.com/google/go-cmp/cmp"
 	"github.com/jarcoal/httpmock"
+	"net/http"
 	"testing"
 )
 
@@ -107,6 +110,36 @@ func TestGetGroups(t *testing.T) {
 	}
 }
 
+func TestGetGroups_paginated(t *testing.T) {
+	config := testConfig()
+	client := Client(config)
+	groups := make(map[string]statuspagetypes.Group)
+	for i := 0; i < perPage; i++ {
+		group := statuspagemocks.GroupFactory(fmt.Sprintf("group %d", i))
+		group.PageID = config.Statuspage.PageID
+		groups[group.ID] = *group
+	}
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	statuspagemocks.ConfigureGroupMock(config, map[string]string{}, groups)
+	got, err := GetGroups(client, config.Statuspage.PageID)
+	if err != nil {
+		t.Fatalf("GetGroups() error = %v", err)
+	}
+	if len(*got) != len(groups) {
+		t.Errorf("GetGroups() returned %d groups, want %d", len(*got), len(groups))
+	}
+	for _, group := range *got {
+		if diff := cmp.Diff(groups[group.ID], group); diff != "" {
+			t.Errorf("GetGroups() mismatch (-want +got):\n%s", diff)
+		}
+	}
+	// a full page means there might be more, so the empty second page is requested too
+	if calls := httpmock.GetTotalCallCount(); calls != 2 {
+		t.Errorf("GetGroups() made %d requests, want 2", calls)
+	}
+}
+
 func TestPatchGroup(t *testing.T) {
 	type args struct {
 		client  *resty.Client
@@ -238,3 +271,64 @@ func TestPostGroup(t *testing.T) {
 		})
 	}
 }
+
+// Group bodies are checked exactly like component bodies in TestComponentRequestBodies
+func TestGroupRequestBodies(t *testing.T) {
+	config := testConfig()
+	group := statuspagetypes.Group{ID: "abc", Name: "Terra", Description: "Everything", Components: []string{"1", "2"},
+		Position: 2, CreatedAt: "ignored"}
+	wantBody := map[string]interface{}{
+		"component_group": map[string]interface{}{
+			"components":  []interface{}{"1", "2"},
+			"name":        "Terra",
+			"description": "Everything",
+			"position":    float64(2),
+		},
+		"description": "Everything",
+	}
+	tests := []struct {
+		name   string
+		method string
+		call   func(client *resty.Client) error
+	}{
+		{
+			name:   "PostGroup",
+			method: "POST",
+			call: func(client *resty.Client) error {
+				_, err := PostGroup(client, config.Statuspage.PageID, group)
+				return err
+			},
+		},
+		{
+			name:   "PatchGroup",
+			method: "PATCH",
+			call: func(client *resty.Client) error {
+				_, err := PatchGroup(client, config.Statuspage.PageID, group.ID, group)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := Client(config)
+			httpmock.ActivateNonDefault(client.GetClient())
+			var gotBody map[string]interface{}
+			httpmock.RegisterResponder(tt.method, fmt.Sprintf(`=~^%s/pages/baz/component-groups`, config.Statuspage.ApiRoot),
+				func(request *http.Request) (*http.Response, error) {
+					if err := json.NewDecoder(request.Body).Decode(&gotBody); err != nil {
+						return httpmock.NewStringResponse(400, err.Error()), nil
+					}
+					return httpmock.NewJsonResponse(200, group)
+				})
+			err := tt.call(client)
+			httpmock.DeactivateAndReset()
+			if err != nil {
+				t.Errorf("%s() error = %v", tt.name, err)
+				return
+			}
+			if diff := cmp.Diff(wantBody, gotBody); diff != "" {
+				t.Errorf("%s() request body mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
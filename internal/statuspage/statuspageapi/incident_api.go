@@ -0,0 +1,91 @@
+package statuspageapi
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+)
+
+// GetIncidents provides a slice of the incidents on the remote page matching the search query, across every page
+// of results. Statuspage searches incident names and bodies, so callers should check the incidents they get.
+func GetIncidents(client *resty.Client, pageID string, query string) (*[]statuspagetypes.Incident, error) {
+	incidents := make([]statuspagetypes.Incident, 0)
+	err := getAllPages(client, func(request *resty.Request) (int, error) {
+		resp, err := request.
+			SetQueryParam("q", query).
+			SetResult([]statuspagetypes.Incident{}).
+			Get(fmt.Sprintf("/pages/%s/incidents", pageID))
+		if err = shared.CheckResponse(resp, err); err != nil {
+			return 0, err
+		}
+		page := *resp.Result().(*[]statuspagetypes.Incident)
+		incidents = append(incidents, page...)
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &incidents, nil
+}
+
+// GetUnresolvedIncidents provides a slice of all incidents on the remote page that aren't resolved
+func GetUnresolvedIncidents(client *resty.Client, pageID string) (*[]statuspagetypes.Incident, error) {
+	resp, err := client.R().
+		SetResult([]statuspagetypes.Incident{}).
+		Get(fmt.Sprintf("/pages/%s/incidents/unresolved", pageID))
+	if err = shared.CheckResponse(resp, err); err != nil {
+		return nil, err
+	}
+	return resp.Result().(*[]statuspagetypes.Incident), nil
+}
+
+// GetScheduledIncidents provides a slice of all scheduled maintenance incidents on the remote page that
+// haven't started yet
+func GetScheduledIncidents(client *resty.Client, pageID string) (*[]statuspagetypes.Incident, error) {
+	resp, err := client.R().
+		SetResult([]statuspagetypes.Incident{}).
+		Get(fmt.Sprintf("/pages/%s/incidents/scheduled", pageID))
+	if err = shared.CheckResponse(resp, err); err != nil {
+		return nil, err
+	}
+	return resp.Result().(*[]statuspagetypes.Incident), nil
+}
+
+// GetActiveMaintenanceIncidents provides a slice of all scheduled maintenance incidents on the remote page that
+// are in progress
+func GetActiveMaintenanceIncidents(client *resty.Client, pageID string) (*[]statuspagetypes.Incident, error) {
+	resp, err := client.R().
+		SetResult([]statuspagetypes.Incident{}).
+		Get(fmt.Sprintf("/pages/%s/incidents/active_maintenance", pageID))
+	if err = shared.CheckResponse(resp, err); err != nil {
+		return nil, err
+	}
+	return resp.Result().(*[]statuspagetypes.Incident), nil
+}
+
+// PostIncident creates a new incident on the remote page. Giving the "scheduled" status and the scheduled
+// fields creates a scheduled maintenance instead.
+func PostIncident(client *resty.Client, pageID string, incident statuspagetypes.RequestIncident) (*statuspagetypes.Incident, error) {
+	resp, err := client.R().
+		SetResult(statuspagetypes.Incident{}).
+		SetBody(map[string]interface{}{"incident": incident}).
+		Post(fmt.Sprintf("/pages/%s/incidents", pageID))
+	if err = shared.CheckResponse(resp, err); err != nil {
+		return nil, err
+	}
+	return resp.Result().(*statuspagetypes.Incident), nil
+}
+
+// PatchIncident updates an existing incident on the remote page by the incident's ID. Any body
+// given is posted as a new incident update, and setting the status to "resolved" resolves it.
+func PatchIncident(client *resty.Client, pageID string, incidentID string, incident statuspagetypes.RequestIncident) (*statuspagetypes.Incident, error) {
+	resp, err := client.R().
+		SetResult(statuspagetypes.Incident{}).
+		SetBody(map[string]interface{}{"incident": incident}).
+		Patch(fmt.Sprintf("/pages/%s/incidents/%s", pageID, incidentID))
+	if err = shared.CheckResponse(resp, err); err != nil {
+		return nil, err
+	}
+	return resp.Result().(*statuspagetypes.Incident), nil
+}
@@ -0,0 +1,235 @@
+package statuspageapi
+
+import (
+	"fmt"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagemocks"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	"testing"
+)
+
+func TestGetUnresolvedIncidents(t *testing.T) {
+	type args struct {
+		client *resty.Client
+		pageID string
+	}
+	config := testConfig()
+	unresolved := statuspagetypes.Incident{ID: "1", Name: "unresolved", Status: "investigating", PageID: config.Statuspage.PageID}
+	resolved := statuspagetypes.Incident{ID: "2", Name: "resolved", Status: "resolved", PageID: config.Statuspage.PageID}
+	tests := []struct {
+		name    string
+		args    args
+		want    *[]statuspagetypes.Incident
+		wantErr bool
+	}{
+		{
+			name: "Returns parsed incident list",
+			args: args{
+				client: Client(config),
+				pageID: config.Statuspage.PageID,
+			},
+			want: &[]statuspagetypes.Incident{unresolved},
+		},
+		{
+			name: "Fails on 404",
+			args: args{
+				client: Client(config),
+				pageID: "nonexistentID",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.ActivateNonDefault(tt.args.client.GetClient())
+			statuspagemocks.ConfigureIncidentMock(config, map[string]statuspagetypes.Incident{
+				unresolved.ID: unresolved,
+				resolved.ID:   resolved,
+			})
+			got, err := GetUnresolvedIncidents(tt.args.client, tt.args.pageID)
+			httpmock.DeactivateAndReset()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetUnresolvedIncidents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GetUnresolvedIncidents() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPostIncident(t *testing.T) {
+	type args struct {
+		client   *resty.Client
+		pageID   string
+		incident statuspagetypes.RequestIncident
+	}
+	config := testConfig()
+	request := statuspagetypes.RequestIncident{
+		Name:         "Notebooks: Major Outage",
+		Status:       "investigating",
+		Body:         "Something is wrong",
+		ComponentIDs: []string{"abc"},
+		Components:   map[string]string{"abc": "major_outage"},
+		Metadata:     map[string]map[string]interface{}{"revere": {"component_id": "abc"}},
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *statuspagetypes.Incident
+		wantErr bool
+	}{
+		{
+			name: "Creates the incident and returns it",
+			args: args{
+				client:   Client(config),
+				pageID:   config.Statuspage.PageID,
+				incident: request,
+			},
+			want: &statuspagetypes.Incident{
+				ID:              "1",
+				Name:            "Notebooks: Major Outage",
+				Status:          "investigating",
+				PageID:          config.Statuspage.PageID,
+				Components:      []statuspagetypes.Component{{ID: "abc", Status: "major_outage"}},
+				IncidentUpdates: []statuspagetypes.IncidentUpdate{{Status: "investigating", Body: "Something is wrong"}},
+				Metadata:        map[string]map[string]interface{}{"revere": {"component_id": "abc"}},
+			},
+		},
+		{
+			name: "Errors on 404",
+			args: args{
+				client:   Client(config),
+				pageID:   "nonexistentID",
+				incident: request,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.ActivateNonDefault(tt.args.client.GetClient())
+			statuspagemocks.ConfigureIncidentMock(config, map[string]statuspagetypes.Incident{})
+			got, err := PostIncident(tt.args.client, tt.args.pageID, tt.args.incident)
+			httpmock.DeactivateAndReset()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PostIncident() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("PostIncident() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPatchIncident(t *testing.T) {
+	type args struct {
+		client     *resty.Client
+		pageID     string
+		incidentID string
+		incident   statuspagetypes.RequestIncident
+	}
+	config := testConfig()
+	existing := statuspagetypes.Incident{
+		ID:              "1",
+		Name:            "Notebooks: Major Outage",
+		Status:          "investigating",
+		IncidentUpdates: []statuspagetypes.IncidentUpdate{{Status: "investigating", Body: "Something is wrong"}},
+	}
+	request := statuspagetypes.RequestIncident{
+		Status: "resolved",
+		Body:   "All better",
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *statuspagetypes.Incident
+		wantErr bool
+	}{
+		{
+			name: "Updates the incident if found",
+			args: args{
+				client:     Client(config),
+				pageID:     config.Statuspage.PageID,
+				incidentID: "1",
+				incident:   request,
+			},
+			want: &statuspagetypes.Incident{
+				ID:     "1",
+				Name:   "Notebooks: Major Outage",
+				Status: "resolved",
+				PageID: config.Statuspage.PageID,
+				IncidentUpdates: []statuspagetypes.IncidentUpdate{
+					{Status: "investigating", Body: "Something is wrong"},
+					{Status: "resolved", Body: "All better"},
+				},
+			},
+		},
+		{
+			name: "Fails on page 404",
+			args: args{
+				client:     Client(config),
+				pageID:     "nonexistentID",
+				incidentID: "1",
+				incident:   request,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fails on incident 404",
+			args: args{
+				client:     Client(config),
+				pageID:     config.Statuspage.PageID,
+				incidentID: "nonexistentID",
+				incident:   request,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.ActivateNonDefault(tt.args.client.GetClient())
+			statuspagemocks.ConfigureIncidentMock(config, map[string]statuspagetypes.Incident{existing.ID: existing})
+			got, err := PatchIncident(tt.args.client, tt.args.pageID, tt.args.incidentID, tt.args.incident)
+			httpmock.DeactivateAndReset()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PatchIncident() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("PatchIncident() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetIncidents(t *testing.T) {
+	config := testConfig()
+	mockIncidents := map[string]statuspagetypes.Incident{}
+	for i := 1; i <= perPage+1; i++ {
+		id := fmt.Sprintf("%03d", i)
+		mockIncidents[id] = statuspagetypes.Incident{ID: id, Name: "upgrade", Status: "completed", PageID: config.Statuspage.PageID}
+	}
+	mockIncidents["other"] = statuspagetypes.Incident{ID: "other", Name: "outage", Status: "resolved", PageID: config.Statuspage.PageID}
+	client := Client(config)
+	httpmock.ActivateNonDefault(client.GetClient())
+	statuspagemocks.ConfigureIncidentMock(config, mockIncidents)
+	got, err := GetIncidents(client, config.Statuspage.PageID, "upgrade")
+	httpmock.DeactivateAndReset()
+	if err != nil {
+		t.Errorf("GetIncidents() error = %v", err)
+		return
+	}
+	if len(*got) != perPage+1 {
+		t.Errorf("GetIncidents() got %d incidents, want every matching one across pages (%d)", len(*got), perPage+1)
+	}
+	for _, incident := range *got {
+		if incident.Name != "upgrade" {
+			t.Errorf("GetIncidents() got %s, which doesn't match the query", incident.Name)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package statuspageapi
+
+import (
+	"github.com/go-resty/resty/v2"
+	"strconv"
+)
+
+// perPage is how many results are requested from each page of Statuspage's list endpoints, the most it allows
+const perPage = 100
+
+// getAllPages walks a paginated list endpoint from the first page, calling getPage with a request for each page.
+// getPage should make the request and return how many results it got; walking stops at the first page
+// returning fewer than perPage results, since Statuspage doesn't otherwise say which page is the last.
+func getAllPages(client *resty.Client, getPage func(request *resty.Request) (int, error)) error {
+	for page := 1; ; page++ {
+		count, err := getPage(client.R().SetQueryParams(map[string]string{
+			"page":     strconv.Itoa(page),
+			"per_page": strconv.Itoa(perPage),
+		}))
+		if err != nil {
+			return err
+		}
+		if count < perPage {
+			return nil
+		}
+	}
+}
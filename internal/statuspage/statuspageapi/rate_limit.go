@@ -0,0 +1,80 @@
+package statuspageapi
+
+import (
+	"github.com/go-resty/resty/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces requests out evenly so that no more than one is made per interval. Requests that would
+// be made too soon wait their turn, and the server may push every request back by asking to retry later.
+// It's safe for concurrent use, since a single client is shared across goroutines.
+type rateLimiter struct {
+	interval time.Duration
+	// next is the earliest a request may be made
+	next time.Time
+	lock *sync.Mutex
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / requestsPerSecond),
+		lock:     &sync.Mutex{},
+	}
+}
+
+// reserve claims the next slot for a request, returning how long to wait for it
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	return wait
+}
+
+// pauseUntil keeps any request from being made before the given time
+func (l *rateLimiter) pauseUntil(until time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.next.Before(until) {
+		l.next = until
+	}
+}
+
+// wait is a resty.RequestMiddleware blocking until the request may be made, or its context is done
+func (l *rateLimiter) wait(_ *resty.Client, request *resty.Request) error {
+	timer := time.NewTimer(l.reserve(time.Now()))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-request.Context().Done():
+		return request.Context().Err()
+	}
+}
+
+// isRateLimited returns if the response says too many requests were made: 429, or Statuspage's 420
+func isRateLimited(response *resty.Response) bool {
+	return response != nil && (response.StatusCode() == http.StatusTooManyRequests || response.StatusCode() == 420)
+}
+
+// retryAfter reads how long the response's Retry-After header says to wait, either as a number of seconds or as
+// an HTTP date, returning zero if it doesn't say
+func retryAfter(response *resty.Response, now time.Time) time.Duration {
+	header := response.Header().Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,225 @@
+package statuspageapi
+
+import (
+	"context"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/shared"
+	"github.com/go-resty/resty/v2"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_rateLimiter_reserve(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		// pausedUntil, if set, is given to pauseUntil before reserving
+		pausedUntil time.Time
+		reserveAt   []time.Time
+		want        []time.Duration
+	}{
+		{
+			name:      "First request doesn't wait",
+			reserveAt: []time.Time{now},
+			want:      []time.Duration{0},
+		},
+		{
+			name:      "Simultaneous requests are spaced out",
+			reserveAt: []time.Time{now, now, now},
+			want:      []time.Duration{0, 500 * time.Millisecond, time.Second},
+		},
+		{
+			name:      "Requests spaced out already don't wait",
+			reserveAt: []time.Time{now, now.Add(time.Second), now.Add(2 * time.Second)},
+			want:      []time.Duration{0, 0, 0},
+		},
+		{
+			name:        "Requests wait out a pause",
+			pausedUntil: now.Add(10 * time.Second),
+			reserveAt:   []time.Time{now, now},
+			want:        []time.Duration{10 * time.Second, 10*time.Second + 500*time.Millisecond},
+		},
+		{
+			name:        "Pauses in the past are ignored",
+			pausedUntil: now.Add(-10 * time.Second),
+			reserveAt:   []time.Time{now},
+			want:        []time.Duration{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newRateLimiter(2)
+			if !tt.pausedUntil.IsZero() {
+				limiter.pauseUntil(tt.pausedUntil)
+			}
+			for i, at := range tt.reserveAt {
+				if got := limiter.reserve(at); got != tt.want[i] {
+					t.Errorf("reserve() #%d = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_rateLimiter_wait(t *testing.T) {
+	limiter := newRateLimiter(0.001)
+	limiter.reserve(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.wait(nil, resty.New().R().SetContext(ctx)); err == nil {
+		t.Errorf("wait() error = nil, want context's error when cancelled")
+	}
+}
+
+func Test_isRateLimited(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		want       bool
+	}{
+		{name: "429", statusCode: http.StatusTooManyRequests, want: true},
+		{name: "420", statusCode: 420, want: true},
+		{name: "200", statusCode: http.StatusOK, want: false},
+		{name: "500", statusCode: http.StatusInternalServerError, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &resty.Response{RawResponse: &http.Response{StatusCode: tt.statusCode}}
+			if got := isRateLimited(response); got != tt.want {
+				t.Errorf("isRateLimited() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	t.Run("No response", func(t *testing.T) {
+		if isRateLimited(nil) {
+			t.Errorf("isRateLimited() = true, want false")
+		}
+	})
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{
+			name:   "Seconds",
+			header: "30",
+			want:   30 * time.Second,
+		},
+		{
+			name:   "HTTP date",
+			header: now.Add(2 * time.Minute).Format(http.TimeFormat),
+			want:   2 * time.Minute,
+		},
+		{
+			name:   "Date in the past",
+			header: now.Add(-2 * time.Minute).Format(http.TimeFormat),
+			want:   0,
+		},
+		{
+			name:   "Missing",
+			header: "",
+			want:   0,
+		},
+		{
+			name:   "Unparsable",
+			header: "soon",
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("Retry-After", tt.header)
+			}
+			response := &resty.Response{RawResponse: &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}}
+			if got := retryAfter(response, now); got != tt.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_retriesRateLimited(t *testing.T) {
+	client := Client(&configuration.Config{
+		Client: struct {
+			Redirects           int
+			Retries             int
+			MaxRetryWaitSeconds int
+			RequestsPerSecond   float64
+		}{Retries: 2, MaxRetryWaitSeconds: 1, RequestsPerSecond: 100},
+	})
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://example.com/",
+		func(request *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				response := httpmock.NewStringResponse(420, "slow down")
+				response.Header.Set("Retry-After", "1")
+				return response, nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK, "ok"), nil
+		})
+	start := time.Now()
+	response, err := client.R().Get("https://example.com/")
+	if err := shared.CheckResponse(response, err); err != nil {
+		t.Fatalf("Client() request error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Client() made %d requests, want 2", calls)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Client() retried after %v, want at least the Retry-After of 1s", elapsed)
+	}
+}
+
+func TestClient_clampsRetryAfter(t *testing.T) {
+	client := Client(&configuration.Config{
+		Client: struct {
+			Redirects           int
+			Retries             int
+			MaxRetryWaitSeconds int
+			RequestsPerSecond   float64
+		}{Retries: 2, MaxRetryWaitSeconds: 1, RequestsPerSecond: 100},
+	})
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://example.com/",
+		func(request *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				response := httpmock.NewStringResponse(http.StatusTooManyRequests, "slow down")
+				response.Header.Set("Retry-After", "3600")
+				return response, nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK, "ok"), nil
+		})
+	// Without clamping, the retry would be held up for the full hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	response, err := client.R().SetContext(ctx).Get("https://example.com/")
+	if err := shared.CheckResponse(response, err); err != nil {
+		t.Fatalf("Client() request error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Client() made %d requests, want 2", calls)
+	}
+	// Later requests mustn't be held up for the full hour either
+	start := time.Now()
+	response, err = client.R().SetContext(ctx).Get("https://example.com/")
+	if err := shared.CheckResponse(response, err); err != nil {
+		t.Fatalf("Client() request error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Client() held up a later request for %v, want no longer than MaxRetryWaitSeconds", elapsed)
+	}
+}
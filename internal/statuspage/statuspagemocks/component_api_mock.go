@@ -7,10 +7,12 @@ import (
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
 	"github.com/jarcoal/httpmock"
 	"net/http"
+	"sort"
 	"strconv"
 )
 
 // ConfigureComponentMock mimics the behavior of Statuspage's component API via the given backing map.
+// Components are listed in order of ID, paginated like Statuspage does.
 // Any components given in the initial map or created via the mock will have their page ID properly set.
 // Created component IDs are incremented based on component map size and the number of deleted components.
 // The caller is responsible for activating/deactivating/resetting httpmock.
@@ -31,7 +33,9 @@ func ConfigureComponentMock(config *configuration.Config, components map[string]
 			for _, component := range components {
 				componentSlice = append(componentSlice, component)
 			}
-			resp, err := httpmock.NewJsonResponse(200, componentSlice)
+			sort.Slice(componentSlice, func(i, j int) bool { return componentSlice[i].ID < componentSlice[j].ID })
+			start, end := paginate(request, len(componentSlice))
+			resp, err := httpmock.NewJsonResponse(200, componentSlice[start:end])
 			if err != nil {
 				return httpmock.NewStringResponse(500, err.Error()), nil
 			}
@@ -77,6 +81,9 @@ func ConfigureComponentMock(config *configuration.Config, components map[string]
 			existingComponent.OnlyShowIfDegraded = incomingBody.Component.OnlyShowIfDegraded
 			existingComponent.Showcase = incomingBody.Component.Showcase
 			existingComponent.StartDate = incomingBody.Component.StartDate
+			if incomingBody.Component.Position != 0 {
+				existingComponent.Position = incomingBody.Component.Position
+			}
 			if incomingBody.Component.Status != "" {
 				existingComponent.Status = incomingBody.Component.Status
 			}
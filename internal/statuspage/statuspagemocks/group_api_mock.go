@@ -7,10 +7,12 @@ import (
 	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
 	"github.com/jarcoal/httpmock"
 	"net/http"
+	"sort"
 	"strconv"
 )
 
 // ConfigureGroupMock mimics the behavior of Statuspage's group API via the given backing map.
+// Components and groups are listed in order of ID, paginated like Statuspage does.
 // Any groups given in the initial map or created via the mock will have their page ID properly set.
 // Created component IDs are incremented based on component map size and the number of deleted components.
 // The caller is responsible for activating/deactivating/resetting httpmock.
@@ -37,7 +39,9 @@ func ConfigureGroupMock(config *configuration.Config, componentIDtoName map[stri
 					Name: name,
 				})
 			}
-			resp, err := httpmock.NewJsonResponse(200, componentSlice)
+			sort.Slice(componentSlice, func(i, j int) bool { return componentSlice[i].ID < componentSlice[j].ID })
+			start, end := paginate(request, len(componentSlice))
+			resp, err := httpmock.NewJsonResponse(200, componentSlice[start:end])
 			if err != nil {
 				return httpmock.NewStringResponse(500, err.Error()), nil
 			}
@@ -53,7 +57,9 @@ func ConfigureGroupMock(config *configuration.Config, componentIDtoName map[stri
 			for _, group := range groupIDtoGroup {
 				groupSlice = append(groupSlice, group)
 			}
-			resp, err := httpmock.NewJsonResponse(200, groupSlice)
+			sort.Slice(groupSlice, func(i, j int) bool { return groupSlice[i].ID < groupSlice[j].ID })
+			start, end := paginate(request, len(groupSlice))
+			resp, err := httpmock.NewJsonResponse(200, groupSlice[start:end])
 			if err != nil {
 				return httpmock.NewStringResponse(500, err.Error()), nil
 			}
@@ -102,6 +108,9 @@ func ConfigureGroupMock(config *configuration.Config, componentIDtoName map[stri
 			existingGroup.Name = incomingBody.ComponentGroup.Name
 			existingGroup.Description = incomingBody.ComponentGroup.Description
 			existingGroup.Components = incomingBody.ComponentGroup.Components
+			if incomingBody.ComponentGroup.Position != 0 {
+				existingGroup.Position = incomingBody.ComponentGroup.Position
+			}
 			groupIDtoGroup[httpmock.MustGetSubmatch(request, 2)] = existingGroup
 			resp, err := httpmock.NewJsonResponse(200, &existingGroup)
 			if err != nil {
@@ -0,0 +1,176 @@
+package statuspagemocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/broadinstitute/revere/internal/configuration"
+	"github.com/broadinstitute/revere/internal/statuspage/statuspagetypes"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigureIncidentMock mimics the behavior of Statuspage's incident API via the given backing map.
+// Any incidents given in the initial map or created via the mock will have their page ID properly set.
+// Created incident IDs are incremented based on incident map size.
+// Incident bodies are recorded as incident updates, and components are recorded by ID only.
+// Searching incidents matches the query against their names, listing them in order of ID and paginated like
+// Statuspage does.
+// The caller is responsible for activating/deactivating/resetting httpmock.
+func ConfigureIncidentMock(config *configuration.Config, incidents map[string]statuspagetypes.Incident) {
+	pageID := config.Statuspage.PageID
+	apiRoot := config.Statuspage.ApiRoot
+	for id, incident := range incidents {
+		incident.PageID = pageID
+		incidents[id] = incident
+	}
+	applyRequest := func(incident *statuspagetypes.Incident, request statuspagetypes.RequestIncident) {
+		if request.Name != "" {
+			incident.Name = request.Name
+		}
+		if request.Status != "" {
+			incident.Status = request.Status
+		}
+		if request.Body != "" {
+			incident.IncidentUpdates = append(incident.IncidentUpdates, statuspagetypes.IncidentUpdate{
+				Status: incident.Status,
+				Body:   request.Body,
+			})
+		}
+		if request.ComponentIDs != nil {
+			incident.Components = nil
+			for _, id := range request.ComponentIDs {
+				incident.Components = append(incident.Components, statuspagetypes.Component{ID: id, Status: request.Components[id]})
+			}
+		}
+		if request.Metadata != nil {
+			incident.Metadata = request.Metadata
+		}
+		if request.ScheduledFor != "" {
+			incident.ScheduledFor = request.ScheduledFor
+		}
+		if request.ScheduledUntil != "" {
+			incident.ScheduledUntil = request.ScheduledUntil
+		}
+	}
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf(`=~^%s/pages/([^/]+)/incidents\z`, apiRoot),
+		func(request *http.Request) (*http.Response, error) {
+			if pageNotFound := validatePageID(pageID, request); pageNotFound != nil {
+				return pageNotFound, nil
+			}
+			query := strings.ToLower(request.URL.Query().Get("q"))
+			incidentSlice := make([]statuspagetypes.Incident, 0, len(incidents))
+			for _, incident := range incidents {
+				if strings.Contains(strings.ToLower(incident.Name), query) {
+					incidentSlice = append(incidentSlice, incident)
+				}
+			}
+			sort.Slice(incidentSlice, func(i, j int) bool { return incidentSlice[i].ID < incidentSlice[j].ID })
+			start, end := paginate(request, len(incidentSlice))
+			resp, err := httpmock.NewJsonResponse(200, incidentSlice[start:end])
+			if err != nil {
+				return httpmock.NewStringResponse(500, err.Error()), nil
+			}
+			return resp, nil
+		})
+	httpmock.RegisterResponder("GET", fmt.Sprintf(`=~^%s/pages/([^/]+)/incidents/unresolved`, apiRoot),
+		func(request *http.Request) (*http.Response, error) {
+			if pageNotFound := validatePageID(pageID, request); pageNotFound != nil {
+				return pageNotFound, nil
+			}
+			incidentSlice := make([]statuspagetypes.Incident, 0, len(incidents))
+			for _, incident := range incidents {
+				if incident.Status != "resolved" && incident.Status != "postmortem" {
+					incidentSlice = append(incidentSlice, incident)
+				}
+			}
+			resp, err := httpmock.NewJsonResponse(200, incidentSlice)
+			if err != nil {
+				return httpmock.NewStringResponse(500, err.Error()), nil
+			}
+			return resp, nil
+		})
+	httpmock.RegisterResponder("GET", fmt.Sprintf(`=~^%s/pages/([^/]+)/incidents/scheduled`, apiRoot),
+		func(request *http.Request) (*http.Response, error) {
+			if pageNotFound := validatePageID(pageID, request); pageNotFound != nil {
+				return pageNotFound, nil
+			}
+			incidentSlice := make([]statuspagetypes.Incident, 0, len(incidents))
+			for _, incident := range incidents {
+				if incident.Status == "scheduled" {
+					incidentSlice = append(incidentSlice, incident)
+				}
+			}
+			resp, err := httpmock.NewJsonResponse(200, incidentSlice)
+			if err != nil {
+				return httpmock.NewStringResponse(500, err.Error()), nil
+			}
+			return resp, nil
+		})
+	httpmock.RegisterResponder("GET", fmt.Sprintf(`=~^%s/pages/([^/]+)/incidents/active_maintenance`, apiRoot),
+		func(request *http.Request) (*http.Response, error) {
+			if pageNotFound := validatePageID(pageID, request); pageNotFound != nil {
+				return pageNotFound, nil
+			}
+			incidentSlice := make([]statuspagetypes.Incident, 0, len(incidents))
+			for _, incident := range incidents {
+				if incident.Status == "in_progress" || incident.Status == "verifying" {
+					incidentSlice = append(incidentSlice, incident)
+				}
+			}
+			resp, err := httpmock.NewJsonResponse(200, incidentSlice)
+			if err != nil {
+				return httpmock.NewStringResponse(500, err.Error()), nil
+			}
+			return resp, nil
+		})
+	httpmock.RegisterResponder("POST", fmt.Sprintf(`=~^%s/pages/([^/]+)/incidents\z`, apiRoot),
+		func(request *http.Request) (*http.Response, error) {
+			if pageNotFound := validatePageID(pageID, request); pageNotFound != nil {
+				return pageNotFound, nil
+			}
+			var incomingBody struct {
+				Incident statuspagetypes.RequestIncident `json:"incident"`
+			}
+			if err := json.NewDecoder(request.Body).Decode(&incomingBody); err != nil {
+				return httpmock.NewStringResponse(400, err.Error()), nil
+			}
+			incident := statuspagetypes.Incident{
+				ID:     strconv.Itoa(len(incidents) + 1),
+				PageID: pageID,
+			}
+			applyRequest(&incident, incomingBody.Incident)
+			incidents[incident.ID] = incident
+			resp, err := httpmock.NewJsonResponse(201, incident)
+			if err != nil {
+				return httpmock.NewStringResponse(500, err.Error()), nil
+			}
+			return resp, nil
+		})
+	httpmock.RegisterResponder("PATCH", fmt.Sprintf(`=~^%s/pages/([^/]+)/incidents/([^/]+)`, apiRoot),
+		func(request *http.Request) (*http.Response, error) {
+			if pageNotFound := validatePageID(pageID, request); pageNotFound != nil {
+				return pageNotFound, nil
+			}
+			if incidentNotFound := validateIncidentID(incidents, request); incidentNotFound != nil {
+				return incidentNotFound, nil
+			}
+			var incomingBody struct {
+				Incident statuspagetypes.RequestIncident `json:"incident"`
+			}
+			if err := json.NewDecoder(request.Body).Decode(&incomingBody); err != nil {
+				return httpmock.NewStringResponse(400, err.Error()), nil
+			}
+			existingIncident := incidents[httpmock.MustGetSubmatch(request, 2)]
+			applyRequest(&existingIncident, incomingBody.Incident)
+			incidents[existingIncident.ID] = existingIncident
+			resp, err := httpmock.NewJsonResponse(200, &existingIncident)
+			if err != nil {
+				return httpmock.NewStringResponse(500, err.Error()), nil
+			}
+			return resp, nil
+		})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/jarcoal/httpmock"
 	"math/rand"
 	"net/http"
+	"strconv"
 )
 
 //goland:noinspection SpellCheckingInspection
@@ -45,6 +46,29 @@ func GroupFactory(name string) *statuspagetypes.Group {
 	}
 }
 
+// paginate returns the bounds of the page of results the request asks for out of the given number of results,
+// mimicking Statuspage's list endpoints: the page and per_page query parameters default to 1 and 100, and pages
+// past the end are empty
+func paginate(request *http.Request, length int) (start int, end int) {
+	page, err := strconv.Atoi(request.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(request.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = 100
+	}
+	start = (page - 1) * perPage
+	if start > length {
+		start = length
+	}
+	end = start + perPage
+	if end > length {
+		end = length
+	}
+	return start, end
+}
+
 // validatePageID returns a 404 response if the pageID wasn't present as the first regex of the request URL,
 // and nil otherwise
 func validatePageID(pageID string, request *http.Request) *http.Response {
@@ -84,3 +108,15 @@ func validateGroupID(groups map[string]statuspagetypes.Group, request *http.Requ
 		404,
 		fmt.Sprintf("Group ID %s was not found in groups map", reqGroupID))
 }
+
+// validateIncidentID returns a 404 response if an incident ID wasn't present as the second regex of the request
+// and nil otherwise
+func validateIncidentID(incidents map[string]statuspagetypes.Incident, request *http.Request) *http.Response {
+	reqIncidentID := httpmock.MustGetSubmatch(request, 2)
+	if _, found := incidents[reqIncidentID]; found {
+		return nil
+	}
+	return httpmock.NewStringResponse(
+		404,
+		fmt.Sprintf("Incident ID %s was not found in incidents map", reqIncidentID))
+}
@@ -35,11 +35,14 @@ func MergeConfigComponentToApi(configComponent configuration.Component, apiCompo
 // RequestComponent represents what Statuspage accepts as input for components.
 // This is necessary because Statuspage errors if unexpected keys are present
 // in request JSON (???) so we must reduce Component down to this type.
+// Position isn't in Statuspage's API reference for requests; prepare sends it to order the page, and warns if the
+// response shows it wasn't applied.
 type RequestComponent struct {
 	Description        string `json:"description"`
 	GroupID            string `json:"group_id,omitempty"`
 	Name               string `json:"name,omitempty"`
 	OnlyShowIfDegraded bool   `json:"only_show_if_degraded"`
+	Position           int    `json:"position,omitempty"`
 	Showcase           bool   `json:"showcase"`
 	StartDate          string `json:"start_date,omitempty"`
 	Status             string `json:"status,omitempty"`
@@ -54,6 +57,7 @@ func (c *Component) ToRequest() RequestComponent {
 		Name:               c.Name,
 		OnlyShowIfDegraded: c.OnlyShowIfDegraded,
 		GroupID:            c.GroupID,
+		Position:           c.Position,
 		Showcase:           c.Showcase,
 		StartDate:          c.StartDate,
 	}
@@ -105,6 +105,7 @@ func TestComponent_toRequest(t *testing.T) {
 				GroupID:            "d",
 				Name:               "f",
 				OnlyShowIfDegraded: true,
+				Position:           1,
 				Showcase:           true,
 				StartDate:          "h",
 				Status:             "i",
@@ -51,11 +51,13 @@ func MergeConfigGroupToApi(configGroup configuration.ComponentGroup, apiGroup *G
 // I'm leaving the field in both places, to hopefully be forwards/backwards
 // compatible with whatever Atlassian does to fix this inconsistency. If they
 // make one field start to error, we'd find out about it on app startup, not runtime.
+// Position is likewise undocumented for requests, and is handled like RequestComponent's.
 type RequestGroup struct {
 	ComponentGroup struct {
 		Components  []string `json:"components"`
 		Name        string   `json:"name"`
 		Description string   `json:"description"`
+		Position    int      `json:"position,omitempty"`
 	} `json:"component_group"`
 	Description string `json:"description"`
 }
@@ -67,10 +69,12 @@ func (g *Group) ToRequest() RequestGroup {
 			Components  []string `json:"components"`
 			Name        string   `json:"name"`
 			Description string   `json:"description"`
+			Position    int      `json:"position,omitempty"`
 		}{
 			Components:  g.Components,
 			Name:        g.Name,
 			Description: g.Description,
+			Position:    g.Position,
 		},
 		Description: g.Description,
 	}
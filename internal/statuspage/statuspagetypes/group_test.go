@@ -35,12 +35,13 @@ func TestGroup_ToRequest(t *testing.T) {
 					Components  []string `json:"components"`
 					Name        string   `json:"name"`
 					Description string   `json:"description"`
+					Position    int      `json:"position,omitempty"`
 				}{Components: []string{"foo"}, Name: "baz", Description: "bar"},
 				Description: "bar",
 			},
 		},
 		{
-			name: "Strips other fields",
+			name: "Strips other fields but position",
 			fields: fields{
 				Components:  []string{"1", "2"},
 				CreatedAt:   "3",
@@ -56,7 +57,8 @@ func TestGroup_ToRequest(t *testing.T) {
 					Components  []string `json:"components"`
 					Name        string   `json:"name"`
 					Description string   `json:"description"`
-				}{Components: []string{"1", "2"}, Name: "6", Description: "4"},
+					Position    int      `json:"position,omitempty"`
+				}{Components: []string{"1", "2"}, Name: "6", Description: "4", Position: 8},
 				Description: "4",
 			},
 		},
@@ -0,0 +1,71 @@
+package statuspagetypes
+
+// Incident represents how Statuspage returns incidents in its API.
+// Only the fields Revere reads are included.
+type Incident struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Impact     string `json:"impact"`
+	Shortlink  string `json:"shortlink"`
+	PageID     string `json:"page_id"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	ResolvedAt string `json:"resolved_at"`
+	// ScheduledFor and ScheduledUntil bound scheduled maintenance incidents
+	ScheduledFor    string           `json:"scheduled_for"`
+	ScheduledUntil  string           `json:"scheduled_until"`
+	Components      []Component      `json:"components"`
+	IncidentUpdates []IncidentUpdate `json:"incident_updates"`
+	// Metadata is arbitrary two-level key-value data that Statuspage stores alongside the incident
+	Metadata map[string]map[string]interface{} `json:"metadata"`
+}
+
+// IncidentUpdate represents each message posted to an incident
+type IncidentUpdate struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
+// RevereMetadataKey is the top-level key of Incident.Metadata that Revere uses to mark incidents it manages
+const RevereMetadataKey = "revere"
+
+// ManagedComponentID returns the ID of the component Revere opened the incident for, if Revere did so.
+func (i *Incident) ManagedComponentID() (string, bool) {
+	revereMetadata, present := i.Metadata[RevereMetadataKey]
+	if !present {
+		return "", false
+	}
+	componentID, ok := revereMetadata["component_id"].(string)
+	return componentID, ok && componentID != ""
+}
+
+// ManagedMaintenanceName returns the name of the maintenance window Revere scheduled the incident for, if
+// Revere did so.
+func (i *Incident) ManagedMaintenanceName() (string, bool) {
+	revereMetadata, present := i.Metadata[RevereMetadataKey]
+	if !present {
+		return "", false
+	}
+	maintenanceName, ok := revereMetadata["maintenance"].(string)
+	return maintenanceName, ok && maintenanceName != ""
+}
+
+// RequestIncident represents what Statuspage accepts as input for creating and updating incidents.
+// Components maps component IDs to their new status in snake case.
+type RequestIncident struct {
+	Name                 string                            `json:"name,omitempty"`
+	Status               string                            `json:"status,omitempty"`
+	Body                 string                            `json:"body,omitempty"`
+	ComponentIDs         []string                          `json:"component_ids,omitempty"`
+	Components           map[string]string                 `json:"components,omitempty"`
+	DeliverNotifications bool                              `json:"deliver_notifications"`
+	Metadata             map[string]map[string]interface{} `json:"metadata,omitempty"`
+	// Scheduled fields only apply to scheduled maintenance incidents, see statuspageapi.PostIncident
+	ScheduledFor            string `json:"scheduled_for,omitempty"`
+	ScheduledUntil          string `json:"scheduled_until,omitempty"`
+	ScheduledAutoInProgress bool   `json:"scheduled_auto_in_progress,omitempty"`
+	ScheduledAutoCompleted  bool   `json:"scheduled_auto_completed,omitempty"`
+}
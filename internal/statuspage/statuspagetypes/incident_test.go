@@ -0,0 +1,40 @@
+package statuspagetypes
+
+import "testing"
+
+func TestIncident_ManagedComponentID(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    map[string]map[string]interface{}
+		want        string
+		wantManaged bool
+	}{
+		{
+			name:        "Managed incident",
+			metadata:    map[string]map[string]interface{}{"revere": {"component_id": "abc"}},
+			want:        "abc",
+			wantManaged: true,
+		},
+		{
+			name:     "No metadata",
+			metadata: nil,
+		},
+		{
+			name:     "Other metadata",
+			metadata: map[string]map[string]interface{}{"jira": {"issue_id": "123"}},
+		},
+		{
+			name:     "Malformed metadata",
+			metadata: map[string]map[string]interface{}{"revere": {"component_id": 123}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &Incident{Metadata: tt.metadata}
+			got, managed := i.ManagedComponentID()
+			if got != tt.want || managed != tt.wantManaged {
+				t.Errorf("ManagedComponentID() = %v, %v, want %v, %v", got, managed, tt.want, tt.wantManaged)
+			}
+		})
+	}
+}
@@ -48,6 +48,38 @@ func (s Status) ToSnakeCase() string {
 	return fmt.Sprintf("invalid_status_%d", s)
 }
 
+func (s Status) ToKebabCase() string {
+	switch s {
+	case Operational:
+		return "operational"
+	case DegradedPerformance:
+		return "degraded-performance"
+	case PartialOutage:
+		return "partial-outage"
+	case MajorOutage:
+		return "major-outage"
+	case UnderMaintenance:
+		return "under-maintenance"
+	}
+	return fmt.Sprintf("invalid-status-%d", s)
+}
+
+func StatusFromSnakeCase(snakeCaseString string) (Status, error) {
+	switch snakeCaseString {
+	case "operational":
+		return Operational, nil
+	case "degraded_performance":
+		return DegradedPerformance, nil
+	case "partial_outage":
+		return PartialOutage, nil
+	case "major_outage":
+		return MajorOutage, nil
+	case "under_maintenance":
+		return UnderMaintenance, nil
+	}
+	return -1, fmt.Errorf("%s cannot be parsed to a Status", snakeCaseString)
+}
+
 func StatusFromKebabCase(kebabCaseString string) (Status, error) {
 	switch kebabCaseString {
 	case "operational":
@@ -73,3 +105,22 @@ func (s Status) WorstWith(other Status) Status {
 		return s
 	}
 }
+
+// MarshalText is a part of encoding.TextMarshaler, so that statuses are human-readable when
+// serialized (as JSON or otherwise)
+func (s Status) MarshalText() ([]byte, error) {
+	if _, err := StatusFromKebabCase(s.ToKebabCase()); err != nil {
+		return nil, err
+	}
+	return []byte(s.ToKebabCase()), nil
+}
+
+// UnmarshalText is a part of encoding.TextUnmarshaler, the inverse of MarshalText
+func (s *Status) UnmarshalText(text []byte) error {
+	status, err := StatusFromKebabCase(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
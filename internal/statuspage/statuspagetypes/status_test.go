@@ -1,6 +1,9 @@
 package statuspagetypes
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 func TestStatus_ToString(t *testing.T) {
 	tests := []struct {
@@ -94,6 +97,62 @@ func TestStatus_ToSnakeCase(t *testing.T) {
 	}
 }
 
+func TestStatusFromSnakeCase(t *testing.T) {
+	type args struct {
+		snakeCaseString string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Status
+		wantErr bool
+	}{
+		{
+			name: "operational parse",
+			args: args{snakeCaseString: "operational"},
+			want: Operational,
+		},
+		{
+			name: "degraded_performance parse",
+			args: args{snakeCaseString: "degraded_performance"},
+			want: DegradedPerformance,
+		},
+		{
+			name: "partial_outage parse",
+			args: args{snakeCaseString: "partial_outage"},
+			want: PartialOutage,
+		},
+		{
+			name: "major_outage parse",
+			args: args{snakeCaseString: "major_outage"},
+			want: MajorOutage,
+		},
+		{
+			name: "under_maintenance parse",
+			args: args{snakeCaseString: "under_maintenance"},
+			want: UnderMaintenance,
+		},
+		{
+			name:    "invalid parse",
+			args:    args{snakeCaseString: "major-outage"},
+			want:    -1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StatusFromSnakeCase(tt.args.snakeCaseString)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("StatusFromSnakeCase() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("StatusFromSnakeCase() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestStatusFromKebabCase(t *testing.T) {
 	type args struct {
 		kebabCaseString string
@@ -193,3 +252,92 @@ func TestStatus_WorstWith(t *testing.T) {
 		})
 	}
 }
+
+func TestStatus_ToKebabCase(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Status
+		want string
+	}{
+		{
+			name: "Operational output",
+			s:    Operational,
+			want: "operational",
+		},
+		{
+			name: "DegradedPerformance output",
+			s:    DegradedPerformance,
+			want: "degraded-performance",
+		},
+		{
+			name: "PartialOutage output",
+			s:    PartialOutage,
+			want: "partial-outage",
+		},
+		{
+			name: "MajorOutage output",
+			s:    MajorOutage,
+			want: "major-outage",
+		},
+		{
+			name: "UnderMaintenance output",
+			s:    UnderMaintenance,
+			want: "under-maintenance",
+		},
+		{
+			name: "Invalid status output",
+			s:    -1,
+			want: "invalid-status--1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.ToKebabCase(); got != tt.want {
+				t.Errorf("ToKebabCase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus_TextRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Status
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Valid status",
+			s:    PartialOutage,
+			want: `{"status":"partial-outage"}`,
+		},
+		{
+			name:    "Invalid status",
+			s:       -1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marshalled, err := json.Marshal(map[string]Status{"status": tt.s})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MarshalText() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(marshalled) != tt.want {
+				t.Errorf("MarshalText() = %s, want %s", marshalled, tt.want)
+			}
+			var unmarshalled map[string]Status
+			if err := json.Unmarshal(marshalled, &unmarshalled); err != nil {
+				t.Errorf("UnmarshalText() error = %v", err)
+				return
+			}
+			if unmarshalled["status"] != tt.s {
+				t.Errorf("UnmarshalText() = %v, want %v", unmarshalled["status"], tt.s)
+			}
+		})
+	}
+}